@@ -1,6 +1,8 @@
 package aws
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
@@ -14,24 +16,38 @@ import (
 
 type Instance interface {
 	Create(svc iamiface.IAMAPI) error
+	CreateWithContext(ctx context.Context, svc iamiface.IAMAPI) error
 	Update(svc iamiface.IAMAPI) error
+	UpdateWithContext(ctx context.Context, svc iamiface.IAMAPI) error
 	Delete(svc iamiface.IAMAPI) error
+	DeleteWithContext(ctx context.Context, svc iamiface.IAMAPI) error
 	ARN() awsarn.ARN
 	IsCreated(svc iamiface.IAMAPI) bool
 }
 
-func IsAlreadyExistsError(err error) bool {
-	if err != nil {
-		return err.(awserr.Error).Code() == iam.ErrCodeEntityAlreadyExistsException
+// IsErrorCode tells whether err is, or wraps, an awserr.Error with one of the given codes. Errors that didn't come
+// from AWS never match.
+func IsErrorCode(err error, codes ...string) bool {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return false
+	}
+	for _, code := range codes {
+		if aerr.Code() == code {
+			return true
+		}
 	}
 	return false
 }
 
+// IsAlreadyExistsError tells whether IAM told us, that the entity in question already exists
+func IsAlreadyExistsError(err error) bool {
+	return IsErrorCode(err, iam.ErrCodeEntityAlreadyExistsException)
+}
+
+// IsNotExistsError tells whether IAM told us, that the entity in question doesn't exist
 func IsNotExistsError(err error) bool {
-	if err != nil {
-		return err.(awserr.Error).Code() == iam.ErrCodeNoSuchEntityException
-	}
-	return false
+	return IsErrorCode(err, iam.ErrCodeNoSuchEntityException)
 }
 
 // MustParse is a wrapper around Parse that swallows errors declaratively
@@ -53,21 +69,45 @@ func ARNify(input ...string) ([]awsarn.ARN, error) {
 	return arns, nil
 }
 
+// ErrInstance matches all InstanceErrors with errors.Is. Their ErrorCodes match those of the given code.
+var ErrInstance = errors.New("AWS instance error")
+
 type InstanceError struct {
 	Code    ErrorCode
 	Message string
+	// Err is the error that caused this one, if any
+	Err error
 }
 
 func (is InstanceError) Error() string {
 	return is.Message
 }
 
+func (is InstanceError) Unwrap() error {
+	return is.Err
+}
+
+// Is makes errors.Is match ErrInstance and the ErrorCode of the InstanceError
+func (is InstanceError) Is(target error) bool {
+	if code, ok := target.(ErrorCode); ok {
+		return is.Code == code
+	}
+	return target == ErrInstance
+}
+
 func (is InstanceError) IsOfErrorCode(code ErrorCode) bool {
 	return is.Code == code
 }
 
+// ErrorCode tells InstanceErrors apart. It is an error itself, so it can be matched with errors.Is, e.g.
+//
+//	if errors.Is(err, aws.ErrAWSInstanceNotYetCreated) { ... }
 type ErrorCode string
 
+func (code ErrorCode) Error() string {
+	return string(code)
+}
+
 const ErrAWSInstanceNotYetCreated ErrorCode = "AWS Instance has not been created"
 
 func NewInstanceError(code ErrorCode, msg string) InstanceError {
@@ -1,11 +1,17 @@
 package aws
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 const TestArnString = "arn:aws:iam::123456789012:policy/somepolicyname"
@@ -51,6 +57,21 @@ func TestInstanceError(t *testing.T) {
 	assert.True(t, getTestInstanceError().IsOfErrorCode(ErrAWSInstanceNotYetCreated))
 }
 
+func TestInstanceError_Wrapping(t *testing.T) {
+	cause := errors.New("boom")
+	err := fmt.Errorf("attaching: %w", InstanceError{Code: ErrAWSInstanceNotYetCreated, Message: "not yet",
+		Err: cause})
+
+	assert.True(t, errors.Is(err, ErrInstance))
+	assert.True(t, errors.Is(err, ErrAWSInstanceNotYetCreated))
+	assert.False(t, errors.Is(err, ErrorCode("other")))
+	assert.True(t, errors.Is(err, cause))
+	var instanceErr InstanceError
+	assert.True(t, errors.As(err, &instanceErr))
+	assert.Equal(t, ErrAWSInstanceNotYetCreated, instanceErr.Code)
+	assert.False(t, errors.Is(errors.New("not yet"), ErrInstance))
+}
+
 func TestNewInstanceError(t *testing.T) {
 	assert.Equal(t, InstanceError{
 		Code:    ErrAWSInstanceNotYetCreated,
@@ -64,3 +85,19 @@ func TestNewInstanceNotYetCreatedError(t *testing.T) {
 		Message: "differentstring",
 	}, NewInstanceNotYetCreatedError("differentstring"))
 }
+
+func TestIsErrorCode(t *testing.T) {
+	aerr := awserr.New(iam.ErrCodeNoSuchEntityException, "no such user", nil)
+	wrapped := cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{Message: "not found", Err: aerr}}
+
+	assert.True(t, IsErrorCode(aerr, iam.ErrCodeNoSuchEntityException))
+	assert.True(t, IsErrorCode(wrapped, "Other", iam.ErrCodeNoSuchEntityException))
+	assert.False(t, IsErrorCode(aerr, iam.ErrCodeEntityAlreadyExistsException))
+	assert.True(t, IsNotExistsError(wrapped))
+	assert.False(t, IsAlreadyExistsError(wrapped))
+
+	// Errors that didn't come from AWS used to panic
+	assert.False(t, IsNotExistsError(errors.New("boom")))
+	assert.False(t, IsAlreadyExistsError(errors.New("boom")))
+	assert.False(t, IsErrorCode(nil, iam.ErrCodeNoSuchEntityException))
+}
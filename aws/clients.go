@@ -0,0 +1,28 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// Clients bundles the AWS service clients used by CloudObjects, so that many objects can share the same clients.
+// Any of them may be replaced, e.g. by a client with added middleware or by a mock in tests.
+type Clients struct {
+	S3  s3iface.S3API
+	RDS rdsiface.RDSAPI
+	KMS kmsiface.KMSAPI
+}
+
+// NewClients returns Clients for all services, created from the given session
+func NewClients(session client.ConfigProvider) *Clients {
+	return &Clients{
+		S3:  s3.New(session),
+		RDS: rds.New(session),
+		KMS: kms.New(session),
+	}
+}
@@ -0,0 +1,117 @@
+package fake_test
+
+import (
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/aws/fake"
+	"github.com/redradrat/cloud-objects/aws/iam"
+)
+
+func TestBackend_Session(t *testing.T) {
+	backend := fake.NewBackend()
+
+	// All sessions share the state of their Backend
+	_, err := awss3.New(backend.Session()).CreateBucket(&awss3.CreateBucketInput{Bucket: awssdk.String("bkt")})
+	require.NoError(t, err)
+	out, err := awss3.New(backend.Session()).ListBuckets(&awss3.ListBucketsInput{})
+	require.NoError(t, err)
+	require.Len(t, out.Buckets, 1)
+	assert.Equal(t, "bkt", awssdk.StringValue(out.Buckets[0].Name))
+
+	// Other Backends don't
+	out, err = awss3.New(fake.NewBackend().Session()).ListBuckets(&awss3.ListBucketsInput{})
+	require.NoError(t, err)
+	assert.Empty(t, out.Buckets)
+
+	// Outputs are copies
+	out, err = awss3.New(backend.Session()).ListBuckets(&awss3.ListBucketsInput{})
+	require.NoError(t, err)
+	out.Buckets[0].Name = awssdk.String("changed")
+	out, err = awss3.New(backend.Session()).ListBuckets(&awss3.ListBucketsInput{})
+	require.NoError(t, err)
+	assert.Equal(t, "bkt", awssdk.StringValue(out.Buckets[0].Name))
+}
+
+func TestBackend_Errors(t *testing.T) {
+	svc := awss3.New(fake.NewBackend().Session())
+
+	_, err := svc.DeleteBucket(&awss3.DeleteBucketInput{Bucket: awssdk.String("missing")})
+	assertErrorCode(t, awss3.ErrCodeNoSuchBucket, err)
+
+	_, err = svc.GetBucketPolicy(&awss3.GetBucketPolicyInput{Bucket: awssdk.String("missing")})
+	assertErrorCode(t, fake.NotImplementedErrCode, err)
+}
+
+func TestBackend_IAM(t *testing.T) {
+	backend := fake.NewBackend()
+	doc := iam.PolicyDocument{
+		Version: iam.PolicyVersion20121017,
+		Statement: []iam.StatementEntry{{
+			Effect:   "Allow",
+			Action:   iam.StringList{"s3:GetObject"},
+			Resource: iam.StringList{"*"},
+		}},
+	}
+
+	group, err := iam.NewGroup("devs", backend.Session())
+	require.NoError(t, err)
+	_, err = group.Create(&iam.GroupSpec{})
+	require.NoError(t, err)
+
+	policy, err := iam.NewPolicy("read", backend.Session())
+	require.NoError(t, err)
+	_, err = policy.Create(&iam.PolicySpec{Description: "read", PolicyDocument: doc})
+	require.NoError(t, err)
+
+	user, err := iam.NewUser("alice", backend.Session())
+	require.NoError(t, err)
+	secrets, err := user.Create(&iam.UserSpec{ProgrammaticAccess: true, Groups: []string{"devs"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, secrets.Map())
+	assert.Equal(t, []string{"clobjx-grp-devs"}, user.Status().(*iam.UserStatus).Groups)
+
+	att, err := iam.NewPolicyAttachment("read", iam.UserAttachmentType, "alice", backend.Session())
+	require.NoError(t, err)
+	_, err = att.Create(&iam.PolicyAttachmentSpec{Policy: "read", Type: iam.UserAttachmentType, Target: "alice"})
+	require.NoError(t, err)
+
+	// A new document makes for a new default version, which reads back the same
+	doc.Statement[0].Action = iam.StringList{"s3:GetObject", "s3:ListBucket"}
+	_, err = policy.Update(&iam.PolicySpec{Description: "read", PolicyDocument: doc})
+	require.NoError(t, err)
+	require.NoError(t, policy.Read())
+	assert.Equal(t, doc, policy.Status().(*iam.PolicyStatus).PolicyDocument)
+
+	// Users with access keys, groups or policies can't be deleted, like at AWS
+	_, err = awsiam.New(backend.Session()).DeleteUser(&awsiam.DeleteUserInput{UserName: user.ID().StringPtr()})
+	assertErrorCode(t, awsiam.ErrCodeDeleteConflictException, err)
+
+	// Groups remove their members on deletion
+	require.NoError(t, group.Delete(false))
+	require.NoError(t, user.Read())
+	assert.Empty(t, user.Status().(*iam.UserStatus).Groups)
+
+	// Users and Policies clean up after themselves
+	require.NoError(t, user.Delete(false))
+	require.NoError(t, policy.Delete(false))
+	for _, obj := range []interface{ Exists() (bool, error) }{user, group, policy, att} {
+		exists, err := obj.Exists()
+		require.NoError(t, err)
+		assert.False(t, exists)
+	}
+}
+
+func assertErrorCode(t *testing.T, code string, err error) {
+	t.Helper()
+	aerr, ok := err.(awserr.Error)
+	if assert.True(t, ok, "error %v is no awserr.Error", err) {
+		assert.Equal(t, code, aerr.Code())
+	}
+}
@@ -0,0 +1,750 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+)
+
+const (
+	maxAccessKeys     = 2
+	maxPolicyVersions = 5
+
+	// awsManagedPolicyDocument is the document of all AWS managed policies, whose real documents the fake doesn't know
+	awsManagedPolicyDocument = `{"Version":"2012-10-17","Statement":[]}`
+)
+
+type iamUser struct {
+	user         *awsiam.User
+	loginProfile *awsiam.LoginProfile
+	accessKeys   []*awsiam.AccessKeyMetadata
+	policies     map[string]bool
+}
+
+type iamGroup struct {
+	group    *awsiam.Group
+	members  map[string]bool
+	policies map[string]bool
+}
+
+type iamRole struct {
+	role     *awsiam.Role
+	policies map[string]bool
+}
+
+type iamPolicy struct {
+	policy      *awsiam.Policy
+	versions    []*awsiam.PolicyVersion
+	nextVersion int
+}
+
+type iamState struct {
+	users  map[string]*iamUser
+	groups map[string]*iamGroup
+	roles  map[string]*iamRole
+	// policies are the customer managed policies by ARN
+	policies map[string]*iamPolicy
+}
+
+func newIAMState() iamState {
+	return iamState{
+		users:    map[string]*iamUser{},
+		groups:   map[string]*iamGroup{},
+		roles:    map[string]*iamRole{},
+		policies: map[string]*iamPolicy{},
+	}
+}
+
+func (b *Backend) serveIAM(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	// Users
+	case *awsiam.CreateUserInput:
+		name := awssdk.StringValue(in.UserName)
+		if _, ok := b.iam.users[name]; ok {
+			return nil, entityAlreadyExists("user", name)
+		}
+		path := iamPath(in.Path)
+		user := &iamUser{
+			user: &awsiam.User{
+				Arn:        awssdk.String(iamARN("user", path, name)),
+				CreateDate: now(),
+				Path:       awssdk.String(path),
+				UserId:     awssdk.String(b.nextID("AIDA", 16)),
+				UserName:   awssdk.String(name),
+			},
+			policies: map[string]bool{},
+		}
+		b.iam.users[name] = user
+		return &awsiam.CreateUserOutput{User: user.user}, nil
+	case *awsiam.GetUserInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.GetUserOutput{User: user.user}, nil
+	case *awsiam.UpdateUserInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if newName := awssdk.StringValue(in.NewUserName); newName != "" && newName != *user.user.UserName {
+			if _, ok := b.iam.users[newName]; ok {
+				return nil, entityAlreadyExists("user", newName)
+			}
+			for _, group := range b.iam.groups {
+				if group.members[*user.user.UserName] {
+					delete(group.members, *user.user.UserName)
+					group.members[newName] = true
+				}
+			}
+			delete(b.iam.users, *user.user.UserName)
+			user.user.UserName = awssdk.String(newName)
+			b.iam.users[newName] = user
+		}
+		if in.NewPath != nil {
+			user.user.Path = awssdk.String(iamPath(in.NewPath))
+		}
+		user.user.Arn = awssdk.String(iamARN("user", *user.user.Path, *user.user.UserName))
+		return &awsiam.UpdateUserOutput{}, nil
+	case *awsiam.DeleteUserInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		name := awssdk.StringValue(user.user.UserName)
+		if user.loginProfile != nil || len(user.accessKeys) != 0 || len(user.policies) != 0 ||
+			len(b.groupsForUser(name)) != 0 {
+			return nil, deleteConflict("user", name,
+				"login profile, access keys, group memberships and attached policies")
+		}
+		delete(b.iam.users, name)
+		return &awsiam.DeleteUserOutput{}, nil
+	case *awsiam.CreateLoginProfileInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if user.loginProfile != nil {
+			return nil, entityAlreadyExists("login profile for user", *user.user.UserName)
+		}
+		if awssdk.StringValue(in.Password) == "" {
+			return nil, awserr.New(awsiam.ErrCodePasswordPolicyViolationException, "password must not be empty", nil)
+		}
+		user.loginProfile = &awsiam.LoginProfile{
+			CreateDate:            now(),
+			PasswordResetRequired: awssdk.Bool(awssdk.BoolValue(in.PasswordResetRequired)),
+			UserName:              user.user.UserName,
+		}
+		return &awsiam.CreateLoginProfileOutput{LoginProfile: user.loginProfile}, nil
+	case *awsiam.GetLoginProfileInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if user.loginProfile == nil {
+			return nil, noSuchEntity("login profile for user", *user.user.UserName)
+		}
+		return &awsiam.GetLoginProfileOutput{LoginProfile: user.loginProfile}, nil
+	case *awsiam.DeleteLoginProfileInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if user.loginProfile == nil {
+			return nil, noSuchEntity("login profile for user", *user.user.UserName)
+		}
+		user.loginProfile = nil
+		return &awsiam.DeleteLoginProfileOutput{}, nil
+	case *awsiam.CreateAccessKeyInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if len(user.accessKeys) >= maxAccessKeys {
+			return nil, awserr.New(awsiam.ErrCodeLimitExceededException, fmt.Sprintf(
+				"cannot exceed quota for AccessKeysPerUser: %d", maxAccessKeys), nil)
+		}
+		key := &awsiam.AccessKey{
+			AccessKeyId:     awssdk.String(b.nextID("AKIA", 16)),
+			CreateDate:      now(),
+			SecretAccessKey: awssdk.String(b.nextID("fakesecret", 30)),
+			Status:          awssdk.String(awsiam.StatusTypeActive),
+			UserName:        user.user.UserName,
+		}
+		user.accessKeys = append(user.accessKeys, &awsiam.AccessKeyMetadata{
+			AccessKeyId: key.AccessKeyId,
+			CreateDate:  key.CreateDate,
+			Status:      key.Status,
+			UserName:    key.UserName,
+		})
+		return &awsiam.CreateAccessKeyOutput{AccessKey: key}, nil
+	case *awsiam.ListAccessKeysInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.ListAccessKeysOutput{AccessKeyMetadata: user.accessKeys, IsTruncated: awssdk.Bool(false)}, nil
+	case *awsiam.DeleteAccessKeyInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range user.accessKeys {
+			if awssdk.StringValue(key.AccessKeyId) == awssdk.StringValue(in.AccessKeyId) {
+				user.accessKeys = append(user.accessKeys[:i], user.accessKeys[i+1:]...)
+				return &awsiam.DeleteAccessKeyOutput{}, nil
+			}
+		}
+		return nil, noSuchEntity("access key", awssdk.StringValue(in.AccessKeyId))
+	case *awsiam.ListGroupsForUserInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		out := &awsiam.ListGroupsForUserOutput{IsTruncated: awssdk.Bool(false)}
+		for _, name := range b.groupsForUser(*user.user.UserName) {
+			out.Groups = append(out.Groups, b.iam.groups[name].group)
+		}
+		return out, nil
+
+	// Groups
+	case *awsiam.CreateGroupInput:
+		name := awssdk.StringValue(in.GroupName)
+		if _, ok := b.iam.groups[name]; ok {
+			return nil, entityAlreadyExists("group", name)
+		}
+		path := iamPath(in.Path)
+		group := &iamGroup{
+			group: &awsiam.Group{
+				Arn:        awssdk.String(iamARN("group", path, name)),
+				CreateDate: now(),
+				GroupId:    awssdk.String(b.nextID("AGPA", 16)),
+				GroupName:  awssdk.String(name),
+				Path:       awssdk.String(path),
+			},
+			members:  map[string]bool{},
+			policies: map[string]bool{},
+		}
+		b.iam.groups[name] = group
+		return &awsiam.CreateGroupOutput{Group: group.group}, nil
+	case *awsiam.GetGroupInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		out := &awsiam.GetGroupOutput{Group: group.group, IsTruncated: awssdk.Bool(false)}
+		for _, name := range sortedKeys(group.members) {
+			out.Users = append(out.Users, b.iam.users[name].user)
+		}
+		return out, nil
+	case *awsiam.UpdateGroupInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		if newName := awssdk.StringValue(in.NewGroupName); newName != "" && newName != *group.group.GroupName {
+			if _, ok := b.iam.groups[newName]; ok {
+				return nil, entityAlreadyExists("group", newName)
+			}
+			delete(b.iam.groups, *group.group.GroupName)
+			group.group.GroupName = awssdk.String(newName)
+			b.iam.groups[newName] = group
+		}
+		if in.NewPath != nil {
+			group.group.Path = awssdk.String(iamPath(in.NewPath))
+		}
+		group.group.Arn = awssdk.String(iamARN("group", *group.group.Path, *group.group.GroupName))
+		return &awsiam.UpdateGroupOutput{}, nil
+	case *awsiam.DeleteGroupInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		if len(group.members) != 0 || len(group.policies) != 0 {
+			return nil, deleteConflict("group", *group.group.GroupName, "members and attached policies")
+		}
+		delete(b.iam.groups, *group.group.GroupName)
+		return &awsiam.DeleteGroupOutput{}, nil
+	case *awsiam.AddUserToGroupInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		group.members[*user.user.UserName] = true
+		return &awsiam.AddUserToGroupOutput{}, nil
+	case *awsiam.RemoveUserFromGroupInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		if !group.members[*user.user.UserName] {
+			return nil, noSuchEntity("member of group "+*group.group.GroupName, *user.user.UserName)
+		}
+		delete(group.members, *user.user.UserName)
+		return &awsiam.RemoveUserFromGroupOutput{}, nil
+
+	// Roles
+	case *awsiam.CreateRoleInput:
+		name := awssdk.StringValue(in.RoleName)
+		if _, ok := b.iam.roles[name]; ok {
+			return nil, entityAlreadyExists("role", name)
+		}
+		if err := malformedPolicyDocument(in.AssumeRolePolicyDocument); err != nil {
+			return nil, err
+		}
+		path := iamPath(in.Path)
+		role := &iamRole{
+			role: &awsiam.Role{
+				Arn:                      awssdk.String(iamARN("role", path, name)),
+				AssumeRolePolicyDocument: awssdk.String(url.QueryEscape(*in.AssumeRolePolicyDocument)),
+				CreateDate:               now(),
+				Description:              in.Description,
+				MaxSessionDuration:       awssdk.Int64(3600),
+				Path:                     awssdk.String(path),
+				RoleId:                   awssdk.String(b.nextID("AROA", 16)),
+				RoleName:                 awssdk.String(name),
+			},
+			policies: map[string]bool{},
+		}
+		if awssdk.Int64Value(in.MaxSessionDuration) != 0 {
+			role.role.MaxSessionDuration = in.MaxSessionDuration
+		}
+		b.iam.roles[name] = role
+		return &awsiam.CreateRoleOutput{Role: role.role}, nil
+	case *awsiam.GetRoleInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.GetRoleOutput{Role: role.role}, nil
+	case *awsiam.UpdateRoleInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		if in.Description != nil {
+			role.role.Description = in.Description
+		}
+		if in.MaxSessionDuration != nil {
+			role.role.MaxSessionDuration = in.MaxSessionDuration
+		}
+		return &awsiam.UpdateRoleOutput{}, nil
+	case *awsiam.UpdateAssumeRolePolicyInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		if err := malformedPolicyDocument(in.PolicyDocument); err != nil {
+			return nil, err
+		}
+		role.role.AssumeRolePolicyDocument = awssdk.String(url.QueryEscape(*in.PolicyDocument))
+		return &awsiam.UpdateAssumeRolePolicyOutput{}, nil
+	case *awsiam.DeleteRoleInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		if len(role.policies) != 0 {
+			return nil, deleteConflict("role", *role.role.RoleName, "attached policies")
+		}
+		delete(b.iam.roles, *role.role.RoleName)
+		return &awsiam.DeleteRoleOutput{}, nil
+
+	// Policies
+	case *awsiam.CreatePolicyInput:
+		name := awssdk.StringValue(in.PolicyName)
+		path := iamPath(in.Path)
+		policyARN := iamARN("policy", path, name)
+		if _, ok := b.iam.policies[policyARN]; ok {
+			return nil, entityAlreadyExists("policy", name)
+		}
+		if err := malformedPolicyDocument(in.PolicyDocument); err != nil {
+			return nil, err
+		}
+		policy := &iamPolicy{policy: &awsiam.Policy{
+			Arn:          awssdk.String(policyARN),
+			CreateDate:   now(),
+			Description:  in.Description,
+			IsAttachable: awssdk.Bool(true),
+			Path:         awssdk.String(path),
+			PolicyId:     awssdk.String(b.nextID("ANPA", 16)),
+			PolicyName:   awssdk.String(name),
+		}}
+		policy.addVersion(*in.PolicyDocument, true)
+		b.iam.policies[policyARN] = policy
+		return &awsiam.CreatePolicyOutput{Policy: b.policyWithCounts(policy)}, nil
+	case *awsiam.GetPolicyInput:
+		if isAWSManagedPolicy(in.PolicyArn) {
+			return &awsiam.GetPolicyOutput{Policy: awsManagedPolicy(*in.PolicyArn)}, nil
+		}
+		policy, err := b.policy(in.PolicyArn)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.GetPolicyOutput{Policy: b.policyWithCounts(policy)}, nil
+	case *awsiam.ListPoliciesInput:
+		out := &awsiam.ListPoliciesOutput{IsTruncated: awssdk.Bool(false)}
+		// AWS managed policies aren't known to the fake, so all there is to list are customer managed ones
+		if awssdk.StringValue(in.Scope) == awsiam.PolicyScopeTypeAws {
+			return out, nil
+		}
+		for _, policyARN := range sortedKeys(b.iam.policies) {
+			policy := b.policyWithCounts(b.iam.policies[policyARN])
+			if awssdk.BoolValue(in.OnlyAttached) && awssdk.Int64Value(policy.AttachmentCount) == 0 {
+				continue
+			}
+			if !strings.HasPrefix(*policy.Path, awssdk.StringValue(in.PathPrefix)) {
+				continue
+			}
+			out.Policies = append(out.Policies, policy)
+		}
+		return out, nil
+	case *awsiam.DeletePolicyInput:
+		policy, err := b.policy(in.PolicyArn)
+		if err != nil {
+			return nil, err
+		}
+		if len(policy.versions) > 1 || awssdk.Int64Value(b.policyWithCounts(policy).AttachmentCount) != 0 {
+			return nil, deleteConflict("policy", *policy.policy.PolicyName, "non-default versions and attachments")
+		}
+		delete(b.iam.policies, *policy.policy.Arn)
+		return &awsiam.DeletePolicyOutput{}, nil
+	case *awsiam.CreatePolicyVersionInput:
+		policy, err := b.policy(in.PolicyArn)
+		if err != nil {
+			return nil, err
+		}
+		if len(policy.versions) >= maxPolicyVersions {
+			return nil, awserr.New(awsiam.ErrCodeLimitExceededException, fmt.Sprintf(
+				"a managed policy can have up to %d versions", maxPolicyVersions), nil)
+		}
+		if err := malformedPolicyDocument(in.PolicyDocument); err != nil {
+			return nil, err
+		}
+		version := policy.addVersion(*in.PolicyDocument, awssdk.BoolValue(in.SetAsDefault))
+		return &awsiam.CreatePolicyVersionOutput{PolicyVersion: version}, nil
+	case *awsiam.GetPolicyVersionInput:
+		if isAWSManagedPolicy(in.PolicyArn) {
+			return &awsiam.GetPolicyVersionOutput{PolicyVersion: &awsiam.PolicyVersion{
+				Document:         awssdk.String(url.QueryEscape(awsManagedPolicyDocument)),
+				IsDefaultVersion: awssdk.Bool(true),
+				VersionId:        awssdk.String("v1"),
+			}}, nil
+		}
+		policy, err := b.policy(in.PolicyArn)
+		if err != nil {
+			return nil, err
+		}
+		version, _ := policy.version(in.VersionId)
+		if version == nil {
+			return nil, noSuchEntity("policy version", awssdk.StringValue(in.VersionId))
+		}
+		return &awsiam.GetPolicyVersionOutput{PolicyVersion: version}, nil
+	case *awsiam.ListPolicyVersionsInput:
+		policy, err := b.policy(in.PolicyArn)
+		if err != nil {
+			return nil, err
+		}
+		out := &awsiam.ListPolicyVersionsOutput{IsTruncated: awssdk.Bool(false)}
+		// Versions are listed without their documents
+		for _, version := range policy.versions {
+			out.Versions = append(out.Versions, &awsiam.PolicyVersion{
+				CreateDate:       version.CreateDate,
+				IsDefaultVersion: version.IsDefaultVersion,
+				VersionId:        version.VersionId,
+			})
+		}
+		return out, nil
+	case *awsiam.DeletePolicyVersionInput:
+		policy, err := b.policy(in.PolicyArn)
+		if err != nil {
+			return nil, err
+		}
+		version, i := policy.version(in.VersionId)
+		if version == nil {
+			return nil, noSuchEntity("policy version", awssdk.StringValue(in.VersionId))
+		}
+		if awssdk.BoolValue(version.IsDefaultVersion) {
+			return nil, deleteConflict("policy version", *version.VersionId, "its policy, as it is the default")
+		}
+		policy.versions = append(policy.versions[:i], policy.versions[i+1:]...)
+		return &awsiam.DeletePolicyVersionOutput{}, nil
+	case *awsiam.ListEntitiesForPolicyInput:
+		if _, err := b.policy(in.PolicyArn); err != nil {
+			return nil, err
+		}
+		policyARN := awssdk.StringValue(in.PolicyArn)
+		out := &awsiam.ListEntitiesForPolicyOutput{IsTruncated: awssdk.Bool(false)}
+		for _, name := range sortedKeys(b.iam.users) {
+			if user := b.iam.users[name]; user.policies[policyARN] {
+				out.PolicyUsers = append(out.PolicyUsers, &awsiam.PolicyUser{UserId: user.user.UserId,
+					UserName: user.user.UserName})
+			}
+		}
+		for _, name := range sortedKeys(b.iam.groups) {
+			if group := b.iam.groups[name]; group.policies[policyARN] {
+				out.PolicyGroups = append(out.PolicyGroups, &awsiam.PolicyGroup{GroupId: group.group.GroupId,
+					GroupName: group.group.GroupName})
+			}
+		}
+		for _, name := range sortedKeys(b.iam.roles) {
+			if role := b.iam.roles[name]; role.policies[policyARN] {
+				out.PolicyRoles = append(out.PolicyRoles, &awsiam.PolicyRole{RoleId: role.role.RoleId,
+					RoleName: role.role.RoleName})
+			}
+		}
+		return out, nil
+
+	// Attachments
+	case *awsiam.AttachUserPolicyInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.AttachUserPolicyOutput{}, b.attachPolicy(user.policies, in.PolicyArn)
+	case *awsiam.AttachGroupPolicyInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.AttachGroupPolicyOutput{}, b.attachPolicy(group.policies, in.PolicyArn)
+	case *awsiam.AttachRolePolicyInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.AttachRolePolicyOutput{}, b.attachPolicy(role.policies, in.PolicyArn)
+	case *awsiam.DetachUserPolicyInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.DetachUserPolicyOutput{}, detachPolicy(user.policies, in.PolicyArn)
+	case *awsiam.DetachGroupPolicyInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.DetachGroupPolicyOutput{}, detachPolicy(group.policies, in.PolicyArn)
+	case *awsiam.DetachRolePolicyInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.DetachRolePolicyOutput{}, detachPolicy(role.policies, in.PolicyArn)
+	case *awsiam.ListAttachedUserPoliciesInput:
+		user, err := b.user(in.UserName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.ListAttachedUserPoliciesOutput{AttachedPolicies: attachedPolicies(user.policies),
+			IsTruncated: awssdk.Bool(false)}, nil
+	case *awsiam.ListAttachedGroupPoliciesInput:
+		group, err := b.group(in.GroupName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.ListAttachedGroupPoliciesOutput{AttachedPolicies: attachedPolicies(group.policies),
+			IsTruncated: awssdk.Bool(false)}, nil
+	case *awsiam.ListAttachedRolePoliciesInput:
+		role, err := b.role(in.RoleName)
+		if err != nil {
+			return nil, err
+		}
+		return &awsiam.ListAttachedRolePoliciesOutput{AttachedPolicies: attachedPolicies(role.policies),
+			IsTruncated: awssdk.Bool(false)}, nil
+	}
+	return nil, notImplemented(awsiam.ServiceName, op)
+}
+
+func (b *Backend) user(name *string) (*iamUser, error) {
+	user, ok := b.iam.users[awssdk.StringValue(name)]
+	if !ok {
+		return nil, noSuchEntity("user", awssdk.StringValue(name))
+	}
+	return user, nil
+}
+
+func (b *Backend) group(name *string) (*iamGroup, error) {
+	group, ok := b.iam.groups[awssdk.StringValue(name)]
+	if !ok {
+		return nil, noSuchEntity("group", awssdk.StringValue(name))
+	}
+	return group, nil
+}
+
+func (b *Backend) role(name *string) (*iamRole, error) {
+	role, ok := b.iam.roles[awssdk.StringValue(name)]
+	if !ok {
+		return nil, noSuchEntity("role", awssdk.StringValue(name))
+	}
+	return role, nil
+}
+
+func (b *Backend) policy(policyARN *string) (*iamPolicy, error) {
+	policy, ok := b.iam.policies[awssdk.StringValue(policyARN)]
+	if !ok {
+		return nil, noSuchEntity("policy", awssdk.StringValue(policyARN))
+	}
+	return policy, nil
+}
+
+// policyWithCounts returns the policy with its current attachment count, which IAM reports along with it
+func (b *Backend) policyWithCounts(policy *iamPolicy) *awsiam.Policy {
+	var count int64
+	for _, user := range b.iam.users {
+		if user.policies[*policy.policy.Arn] {
+			count++
+		}
+	}
+	for _, group := range b.iam.groups {
+		if group.policies[*policy.policy.Arn] {
+			count++
+		}
+	}
+	for _, role := range b.iam.roles {
+		if role.policies[*policy.policy.Arn] {
+			count++
+		}
+	}
+	out := *policy.policy
+	out.AttachmentCount = awssdk.Int64(count)
+	return &out
+}
+
+func (b *Backend) groupsForUser(name string) []string {
+	var out []string
+	for _, groupName := range sortedKeys(b.iam.groups) {
+		if b.iam.groups[groupName].members[name] {
+			out = append(out, groupName)
+		}
+	}
+	return out
+}
+
+func (b *Backend) attachPolicy(policies map[string]bool, policyARN *string) error {
+	if !isAWSManagedPolicy(policyARN) {
+		if _, err := b.policy(policyARN); err != nil {
+			return err
+		}
+	}
+	policies[awssdk.StringValue(policyARN)] = true
+	return nil
+}
+
+func detachPolicy(policies map[string]bool, policyARN *string) error {
+	if !policies[awssdk.StringValue(policyARN)] {
+		return noSuchEntity("attached policy", awssdk.StringValue(policyARN))
+	}
+	delete(policies, awssdk.StringValue(policyARN))
+	return nil
+}
+
+// awsManagedPolicy returns what the fake knows about the AWS managed policy with the given ARN, which isn't much
+func awsManagedPolicy(policyARN string) *awsiam.Policy {
+	return &awsiam.Policy{
+		Arn:              awssdk.String(policyARN),
+		DefaultVersionId: awssdk.String("v1"),
+		IsAttachable:     awssdk.Bool(true),
+		Path:             awssdk.String("/"),
+		PolicyName:       awssdk.String(policyName(policyARN)),
+	}
+}
+
+func policyName(policyARN string) string {
+	return policyARN[strings.LastIndex(policyARN, "/")+1:]
+}
+
+func attachedPolicies(policies map[string]bool) []*awsiam.AttachedPolicy {
+	var out []*awsiam.AttachedPolicy
+	for _, policyARN := range sortedKeys(policies) {
+		out = append(out, &awsiam.AttachedPolicy{
+			PolicyArn:  awssdk.String(policyARN),
+			PolicyName: awssdk.String(policyName(policyARN)),
+		})
+	}
+	return out
+}
+
+func (p *iamPolicy) addVersion(document string, setAsDefault bool) *awsiam.PolicyVersion {
+	p.nextVersion++
+	version := &awsiam.PolicyVersion{
+		CreateDate:       now(),
+		Document:         awssdk.String(url.QueryEscape(document)),
+		IsDefaultVersion: awssdk.Bool(setAsDefault),
+		VersionId:        awssdk.String(fmt.Sprintf("v%d", p.nextVersion)),
+	}
+	if setAsDefault {
+		for _, v := range p.versions {
+			v.IsDefaultVersion = awssdk.Bool(false)
+		}
+		p.policy.DefaultVersionId = version.VersionId
+		p.policy.UpdateDate = version.CreateDate
+	}
+	p.versions = append(p.versions, version)
+	return version
+}
+
+func (p *iamPolicy) version(id *string) (*awsiam.PolicyVersion, int) {
+	for i, version := range p.versions {
+		if awssdk.StringValue(version.VersionId) == awssdk.StringValue(id) {
+			return version, i
+		}
+	}
+	return nil, -1
+}
+
+// isAWSManagedPolicy tells whether the ARN is the one of an AWS managed policy (e.g.
+// "arn:aws:iam::aws:policy/ReadOnlyAccess"). The fake treats all of them as existing.
+func isAWSManagedPolicy(policyARN *string) bool {
+	parsed, err := awsarn.Parse(awssdk.StringValue(policyARN))
+	return err == nil && parsed.Service == "iam" && parsed.AccountID == "aws" &&
+		strings.HasPrefix(parsed.Resource, "policy/")
+}
+
+func malformedPolicyDocument(document *string) error {
+	if !json.Valid([]byte(awssdk.StringValue(document))) {
+		return awserr.New(awsiam.ErrCodeMalformedPolicyDocumentException, "syntax errors in policy", nil)
+	}
+	return nil
+}
+
+func iamPath(path *string) string {
+	if awssdk.StringValue(path) == "" {
+		return "/"
+	}
+	return *path
+}
+
+func iamARN(resourceType, path, name string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:%s%s%s", AccountID, resourceType, path, name)
+}
+
+func noSuchEntity(what, name string) error {
+	return awserr.New(awsiam.ErrCodeNoSuchEntityException, fmt.Sprintf("the %s with name %s cannot be found",
+		what, name), nil)
+}
+
+func entityAlreadyExists(what, name string) error {
+	return awserr.New(awsiam.ErrCodeEntityAlreadyExistsException, fmt.Sprintf("%s with name %s already exists",
+		what, name), nil)
+}
+
+func deleteConflict(what, name, blockers string) error {
+	return awserr.New(awsiam.ErrCodeDeleteConflictException, fmt.Sprintf("cannot delete %s %s, delete its %s first",
+		what, name, blockers), nil)
+}
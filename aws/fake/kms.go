@@ -0,0 +1,163 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+)
+
+type kmsState struct {
+	keys map[string]*awskms.KeyMetadata
+	// aliases maps alias names (e.g. "alias/mykey") to key IDs
+	aliases map[string]string
+}
+
+func newKMSState() kmsState {
+	return kmsState{keys: map[string]*awskms.KeyMetadata{}, aliases: map[string]string{}}
+}
+
+func (b *Backend) serveKMS(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *awskms.CreateKeyInput:
+		return b.createKey(in), nil
+	case *awskms.CreateAliasInput:
+		name := awssdk.StringValue(in.AliasName)
+		if !strings.HasPrefix(name, "alias/") || strings.HasPrefix(name, "alias/aws/") {
+			return nil, awserr.New(awskms.ErrCodeInvalidAliasNameException,
+				fmt.Sprintf("alias '%s' must start with 'alias/' and not with 'alias/aws/'", name), nil)
+		}
+		if _, ok := b.kms.aliases[name]; ok {
+			return nil, awserr.New(awskms.ErrCodeAlreadyExistsException,
+				fmt.Sprintf("an alias with the name %s already exists", arn("kms", Region, name)), nil)
+		}
+		key, err := b.key(in.TargetKeyId)
+		if err != nil {
+			return nil, err
+		}
+		b.kms.aliases[name] = awssdk.StringValue(key.KeyId)
+		return &awskms.CreateAliasOutput{}, nil
+	case *awskms.DeleteAliasInput:
+		name := awssdk.StringValue(in.AliasName)
+		if _, ok := b.kms.aliases[name]; !ok {
+			return nil, awserr.New(awskms.ErrCodeNotFoundException,
+				fmt.Sprintf("alias %s is not found", arn("kms", Region, name)), nil)
+		}
+		delete(b.kms.aliases, name)
+		return &awskms.DeleteAliasOutput{}, nil
+	case *awskms.DescribeKeyInput:
+		key, err := b.key(in.KeyId)
+		if err != nil {
+			return nil, err
+		}
+		return &awskms.DescribeKeyOutput{KeyMetadata: key}, nil
+	case *awskms.EnableKeyInput:
+		key, err := b.modifiableKey(in.KeyId)
+		if err != nil {
+			return nil, err
+		}
+		key.Enabled = awssdk.Bool(true)
+		key.KeyState = awssdk.String(awskms.KeyStateEnabled)
+		return &awskms.EnableKeyOutput{}, nil
+	case *awskms.DisableKeyInput:
+		key, err := b.modifiableKey(in.KeyId)
+		if err != nil {
+			return nil, err
+		}
+		key.Enabled = awssdk.Bool(false)
+		key.KeyState = awssdk.String(awskms.KeyStateDisabled)
+		return &awskms.DisableKeyOutput{}, nil
+	case *awskms.ScheduleKeyDeletionInput:
+		key, err := b.modifiableKey(in.KeyId)
+		if err != nil {
+			return nil, err
+		}
+		days := awssdk.Int64Value(in.PendingWindowInDays)
+		if days == 0 {
+			days = 30
+		}
+		if days < 7 || days > 30 {
+			return nil, awserr.New("ValidationException", "pending window must be between 7 and 30 days", nil)
+		}
+		key.Enabled = awssdk.Bool(false)
+		key.KeyState = awssdk.String(awskms.KeyStatePendingDeletion)
+		key.DeletionDate = awssdk.Time(now().Add(time.Duration(days) * 24 * time.Hour))
+		return &awskms.ScheduleKeyDeletionOutput{KeyId: key.Arn, DeletionDate: key.DeletionDate}, nil
+	case *awskms.CancelKeyDeletionInput:
+		key, err := b.key(in.KeyId)
+		if err != nil {
+			return nil, err
+		}
+		if awssdk.StringValue(key.KeyState) != awskms.KeyStatePendingDeletion {
+			return nil, invalidKeyState(key)
+		}
+		key.KeyState = awssdk.String(awskms.KeyStateDisabled)
+		key.DeletionDate = nil
+		return &awskms.CancelKeyDeletionOutput{KeyId: key.Arn}, nil
+	}
+	return nil, notImplemented(awskms.ServiceName, op)
+}
+
+func (b *Backend) createKey(in *awskms.CreateKeyInput) *awskms.CreateKeyOutput {
+	b.seq++
+	id := fmt.Sprintf("%08x-0000-4000-8000-%012x", b.seq, b.seq)
+	key := &awskms.KeyMetadata{
+		AWSAccountId:          awssdk.String(AccountID),
+		Arn:                   awssdk.String(arn("kms", Region, "key/"+id)),
+		CreationDate:          now(),
+		CustomerMasterKeySpec: awssdk.String(awskms.CustomerMasterKeySpecSymmetricDefault),
+		Description:           awssdk.String(awssdk.StringValue(in.Description)),
+		Enabled:               awssdk.Bool(true),
+		KeyId:                 awssdk.String(id),
+		KeyManager:            awssdk.String(awskms.KeyManagerTypeCustomer),
+		KeyState:              awssdk.String(awskms.KeyStateEnabled),
+		KeyUsage:              awssdk.String(awskms.KeyUsageTypeEncryptDecrypt),
+		Origin:                awssdk.String(awskms.OriginTypeAwsKms),
+	}
+	if spec := awssdk.StringValue(in.CustomerMasterKeySpec); spec != "" {
+		key.CustomerMasterKeySpec = awssdk.String(spec)
+	}
+	if usage := awssdk.StringValue(in.KeyUsage); usage != "" {
+		key.KeyUsage = awssdk.String(usage)
+	}
+	b.kms.keys[id] = key
+	return &awskms.CreateKeyOutput{KeyMetadata: key}
+}
+
+// key looks up a key by its ID, its ARN, an alias name or an alias ARN, the same ways KMS accepts
+func (b *Backend) key(ref *string) (*awskms.KeyMetadata, error) {
+	id := awssdk.StringValue(ref)
+	if parsed, err := awsarn.Parse(id); err == nil {
+		id = strings.TrimPrefix(parsed.Resource, "key/")
+	}
+	if strings.HasPrefix(id, "alias/") {
+		id = b.kms.aliases[id]
+	}
+	key, ok := b.kms.keys[id]
+	if !ok {
+		return nil, awserr.New(awskms.ErrCodeNotFoundException,
+			fmt.Sprintf("key '%s' does not exist", awssdk.StringValue(ref)), nil)
+	}
+	return key, nil
+}
+
+// modifiableKey looks up a key like key does, but fails for keys that are pending deletion
+func (b *Backend) modifiableKey(ref *string) (*awskms.KeyMetadata, error) {
+	key, err := b.key(ref)
+	if err != nil {
+		return nil, err
+	}
+	if awssdk.StringValue(key.KeyState) == awskms.KeyStatePendingDeletion {
+		return nil, invalidKeyState(key)
+	}
+	return key, nil
+}
+
+func invalidKeyState(key *awskms.KeyMetadata) error {
+	return awserr.New(awskms.ErrCodeInvalidStateException, fmt.Sprintf("%s is %s",
+		awssdk.StringValue(key.Arn), awssdk.StringValue(key.KeyState)), nil)
+}
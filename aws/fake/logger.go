@@ -0,0 +1,54 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LogEntry is a line logged to a Logger
+type LogEntry struct {
+	Level   string
+	Message string
+	// Attrs holds the keys and values logged along with the message
+	Attrs map[string]interface{}
+}
+
+// Logger is a cloudobject.Logger keeping everything logged to it in memory, for tests to check
+type Logger struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// Entries returns everything logged so far
+func (l *Logger) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LogEntry(nil), l.entries...)
+}
+
+func (l *Logger) DebugContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("debug", msg, args)
+}
+
+func (l *Logger) InfoContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("info", msg, args)
+}
+
+func (l *Logger) WarnContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("warn", msg, args)
+}
+
+func (l *Logger) ErrorContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("error", msg, args)
+}
+
+func (l *Logger) log(level, msg string, args []interface{}) {
+	attrs := make(map[string]interface{}, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[fmt.Sprint(args[i])] = args[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, LogEntry{Level: level, Message: msg, Attrs: attrs})
+}
@@ -0,0 +1,723 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	awsrds "github.com/aws/aws-sdk-go/service/rds"
+)
+
+const (
+	// VpcID is the ID of the VPC all DB SubnetGroups are in
+	VpcID = "vpc-fake"
+
+	availableState = "available"
+)
+
+var (
+	defaultEngineVersions = map[string]string{"postgres": "12.2", "mysql": "8.0.17", "mariadb": "10.4.8",
+		"aurora-postgresql": "11.6", "aurora-mysql": "5.7.mysql_aurora.2.07.2"}
+	defaultPorts = map[string]int64{"postgres": 5432, "mysql": 3306, "mariadb": 3306, "aurora-postgresql": 5432,
+		"aurora-mysql": 3306}
+)
+
+// snapshot keeps the instance a DB Snapshot was taken of, as that's what gets restored
+type snapshot struct {
+	snapshot *awsrds.DBSnapshot
+	instance awsrds.DBInstance
+}
+
+type rdsState struct {
+	instances        map[string]*awsrds.DBInstance
+	snapshots        map[string]*snapshot
+	clusters         map[string]*awsrds.DBCluster
+	clusterSnapshots map[string]*clusterSnapshot
+	subnetGroups     map[string]*awsrds.DBSubnetGroup
+	// passwords maps DB Instance and DB Cluster identifiers to their master user passwords, which RDS never hands
+	// out
+	passwords map[string]string
+}
+
+func newRDSState() rdsState {
+	return rdsState{
+		instances:        map[string]*awsrds.DBInstance{},
+		snapshots:        map[string]*snapshot{},
+		clusters:         map[string]*awsrds.DBCluster{},
+		clusterSnapshots: map[string]*clusterSnapshot{},
+		subnetGroups:     map[string]*awsrds.DBSubnetGroup{},
+		passwords:        map[string]string{},
+	}
+}
+
+// SetDBInstanceStatus sets the status of a DB Instance, e.g. to simulate an instance that isn't available
+func (b *Backend) SetDBInstanceStatus(id, status string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ins, err := b.instance(awssdk.String(id))
+	if err != nil {
+		return err
+	}
+	ins.DBInstanceStatus = awssdk.String(status)
+	return nil
+}
+
+// MasterUserPassword returns the master user password the DB Instance or DB Cluster was last given
+func (b *Backend) MasterUserPassword(id string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.instance(awssdk.String(id)); err != nil {
+		if _, clusterErr := b.cluster(awssdk.String(id)); clusterErr != nil {
+			return "", err
+		}
+	}
+	return b.rds.passwords[id], nil
+}
+
+func (b *Backend) serveRDS(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *awsrds.CreateDBInstanceInput:
+		ins, err := b.createDBInstance(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.CreateDBInstanceOutput{DBInstance: ins}, nil
+	case *awsrds.RestoreDBInstanceFromDBSnapshotInput:
+		ins, err := b.restoreDBInstance(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.RestoreDBInstanceFromDBSnapshotOutput{DBInstance: ins}, nil
+	case *awsrds.CreateDBInstanceReadReplicaInput:
+		ins, err := b.createDBInstanceReadReplica(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.CreateDBInstanceReadReplicaOutput{DBInstance: ins}, nil
+	case *awsrds.DescribeDBInstancesInput:
+		out := &awsrds.DescribeDBInstancesOutput{}
+		if in.DBInstanceIdentifier != nil {
+			ins, err := b.instance(in.DBInstanceIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			out.DBInstances = []*awsrds.DBInstance{ins}
+			return out, nil
+		}
+		for _, id := range sortedKeys(b.rds.instances) {
+			out.DBInstances = append(out.DBInstances, b.rds.instances[id])
+		}
+		return out, nil
+	case *awsrds.ModifyDBInstanceInput:
+		ins, err := b.modifyDBInstance(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.ModifyDBInstanceOutput{DBInstance: ins}, nil
+	case *awsrds.DeleteDBInstanceInput:
+		ins, err := b.deleteDBInstance(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.DeleteDBInstanceOutput{DBInstance: ins}, nil
+	case *awsrds.DescribeDBSnapshotsInput:
+		out := &awsrds.DescribeDBSnapshotsOutput{}
+		if in.DBSnapshotIdentifier != nil {
+			snap, ok := b.rds.snapshots[awssdk.StringValue(in.DBSnapshotIdentifier)]
+			if !ok {
+				return nil, awserr.New(awsrds.ErrCodeDBSnapshotNotFoundFault, fmt.Sprintf(
+					"DBSnapshot %s not found", awssdk.StringValue(in.DBSnapshotIdentifier)), nil)
+			}
+			out.DBSnapshots = []*awsrds.DBSnapshot{snap.snapshot}
+			return out, nil
+		}
+		for _, id := range sortedKeys(b.rds.snapshots) {
+			snap := b.rds.snapshots[id]
+			if in.DBInstanceIdentifier == nil ||
+				awssdk.StringValue(snap.snapshot.DBInstanceIdentifier) == awssdk.StringValue(in.DBInstanceIdentifier) {
+				out.DBSnapshots = append(out.DBSnapshots, snap.snapshot)
+			}
+		}
+		return out, nil
+	case *awsrds.DeleteDBSnapshotInput:
+		id := awssdk.StringValue(in.DBSnapshotIdentifier)
+		snap, ok := b.rds.snapshots[id]
+		if !ok {
+			return nil, awserr.New(awsrds.ErrCodeDBSnapshotNotFoundFault, fmt.Sprintf("DBSnapshot %s not found", id), nil)
+		}
+		delete(b.rds.snapshots, id)
+		snap.snapshot.Status = awssdk.String("deleted")
+		return &awsrds.DeleteDBSnapshotOutput{DBSnapshot: snap.snapshot}, nil
+	case *awsrds.CreateDBSubnetGroupInput:
+		name := awssdk.StringValue(in.DBSubnetGroupName)
+		if _, ok := b.rds.subnetGroups[name]; ok {
+			return nil, awserr.New(awsrds.ErrCodeDBSubnetGroupAlreadyExistsFault, fmt.Sprintf(
+				"DB Subnet Group %s already exists", name), nil)
+		}
+		group := &awsrds.DBSubnetGroup{
+			DBSubnetGroupArn:         awssdk.String(arn("rds", Region, "subgrp:"+name)),
+			DBSubnetGroupDescription: in.DBSubnetGroupDescription,
+			DBSubnetGroupName:        awssdk.String(name),
+			SubnetGroupStatus:        awssdk.String("Complete"),
+			Subnets:                  subnets(in.SubnetIds),
+			VpcId:                    awssdk.String(VpcID),
+		}
+		b.rds.subnetGroups[name] = group
+		return &awsrds.CreateDBSubnetGroupOutput{DBSubnetGroup: group}, nil
+	case *awsrds.DescribeDBSubnetGroupsInput:
+		out := &awsrds.DescribeDBSubnetGroupsOutput{}
+		if in.DBSubnetGroupName != nil {
+			group, err := b.subnetGroup(in.DBSubnetGroupName)
+			if err != nil {
+				return nil, err
+			}
+			out.DBSubnetGroups = []*awsrds.DBSubnetGroup{group}
+			return out, nil
+		}
+		for _, name := range sortedKeys(b.rds.subnetGroups) {
+			out.DBSubnetGroups = append(out.DBSubnetGroups, b.rds.subnetGroups[name])
+		}
+		return out, nil
+	case *awsrds.ModifyDBSubnetGroupInput:
+		group, err := b.subnetGroup(in.DBSubnetGroupName)
+		if err != nil {
+			return nil, err
+		}
+		if in.DBSubnetGroupDescription != nil {
+			group.DBSubnetGroupDescription = in.DBSubnetGroupDescription
+		}
+		group.Subnets = subnets(in.SubnetIds)
+		return &awsrds.ModifyDBSubnetGroupOutput{DBSubnetGroup: group}, nil
+	case *awsrds.DeleteDBSubnetGroupInput:
+		group, err := b.subnetGroup(in.DBSubnetGroupName)
+		if err != nil {
+			return nil, err
+		}
+		for _, ins := range b.rds.instances {
+			if ins.DBSubnetGroup != nil &&
+				awssdk.StringValue(ins.DBSubnetGroup.DBSubnetGroupName) == awssdk.StringValue(group.DBSubnetGroupName) {
+				return nil, awserr.New(awsrds.ErrCodeInvalidDBSubnetGroupStateFault, fmt.Sprintf(
+					"DB Subnet Group %s is in use by DB Instance %s", awssdk.StringValue(group.DBSubnetGroupName),
+					awssdk.StringValue(ins.DBInstanceIdentifier)), nil)
+			}
+		}
+		for _, cluster := range b.rds.clusters {
+			if awssdk.StringValue(cluster.DBSubnetGroup) == awssdk.StringValue(group.DBSubnetGroupName) {
+				return nil, awserr.New(awsrds.ErrCodeInvalidDBSubnetGroupStateFault, fmt.Sprintf(
+					"DB Subnet Group %s is in use by DB Cluster %s", awssdk.StringValue(group.DBSubnetGroupName),
+					awssdk.StringValue(cluster.DBClusterIdentifier)), nil)
+			}
+		}
+		delete(b.rds.subnetGroups, awssdk.StringValue(group.DBSubnetGroupName))
+		return &awsrds.DeleteDBSubnetGroupOutput{}, nil
+	}
+	return b.serveRDSCluster(op, params)
+}
+
+func (b *Backend) instance(id *string) (*awsrds.DBInstance, error) {
+	ins, ok := b.rds.instances[awssdk.StringValue(id)]
+	if !ok {
+		return nil, awserr.New(awsrds.ErrCodeDBInstanceNotFoundFault, fmt.Sprintf("DBInstance %s not found",
+			awssdk.StringValue(id)), nil)
+	}
+	return ins, nil
+}
+
+func (b *Backend) subnetGroup(name *string) (*awsrds.DBSubnetGroup, error) {
+	group, ok := b.rds.subnetGroups[awssdk.StringValue(name)]
+	if !ok {
+		return nil, awserr.New(awsrds.ErrCodeDBSubnetGroupNotFoundFault, fmt.Sprintf("DB Subnet Group %s not found",
+			awssdk.StringValue(name)), nil)
+	}
+	return group, nil
+}
+
+func (b *Backend) createDBInstance(in *awsrds.CreateDBInstanceInput) (*awsrds.DBInstance, error) {
+	if in.DBClusterIdentifier != nil {
+		return b.createClusterInstance(in)
+	}
+	id := awssdk.StringValue(in.DBInstanceIdentifier)
+	if _, ok := b.rds.instances[id]; ok {
+		return nil, awserr.New(awsrds.ErrCodeDBInstanceAlreadyExistsFault, fmt.Sprintf(
+			"DB Instance %s already exists", id), nil)
+	}
+	engine := awssdk.StringValue(in.Engine)
+	if engine == "" || awssdk.StringValue(in.DBInstanceClass) == "" || awssdk.StringValue(in.MasterUsername) == "" {
+		return nil, invalidParameterValue("Engine, DBInstanceClass and MasterUsername are required")
+	}
+	if awssdk.Int64Value(in.AllocatedStorage) <= 0 {
+		return nil, invalidParameterValue("AllocatedStorage must be positive")
+	}
+
+	ins := &awsrds.DBInstance{
+		AllocatedStorage:           in.AllocatedStorage,
+		AutoMinorVersionUpgrade:    awssdk.Bool(awssdk.BoolValue(in.AutoMinorVersionUpgrade)),
+		BackupRetentionPeriod:      awssdk.Int64(awssdk.Int64Value(in.BackupRetentionPeriod)),
+		CopyTagsToSnapshot:         awssdk.Bool(awssdk.BoolValue(in.CopyTagsToSnapshot)),
+		DBInstanceClass:            in.DBInstanceClass,
+		DBName:                     in.DBName,
+		Engine:                     awssdk.String(engine),
+		EngineVersion:              awssdk.String(defaultEngineVersions[engine]),
+		MasterUsername:             in.MasterUsername,
+		MaxAllocatedStorage:        in.MaxAllocatedStorage,
+		MonitoringInterval:         awssdk.Int64(awssdk.Int64Value(in.MonitoringInterval)),
+		PerformanceInsightsEnabled: awssdk.Bool(awssdk.BoolValue(in.EnablePerformanceInsights)),
+		PreferredBackupWindow:      awssdk.String("03:00-03:30"),
+		PreferredMaintenanceWindow: awssdk.String("sun:05:00-sun:05:30"),
+		PubliclyAccessible:         awssdk.Bool(awssdk.BoolValue(in.PubliclyAccessible)),
+		StorageEncrypted:           awssdk.Bool(awssdk.BoolValue(in.StorageEncrypted)),
+		StorageType:                awssdk.String("gp2"),
+	}
+	if v := awssdk.StringValue(in.EngineVersion); v != "" {
+		ins.EngineVersion = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.StorageType); v != "" {
+		ins.StorageType = awssdk.String(v)
+	}
+	if in.Iops != nil {
+		ins.Iops = in.Iops
+	}
+	if ins.MonitoringInterval != nil && *ins.MonitoringInterval != 0 {
+		ins.MonitoringRoleArn = in.MonitoringRoleArn
+	}
+	if awssdk.BoolValue(ins.PerformanceInsightsEnabled) {
+		ins.PerformanceInsightsRetentionPeriod = awssdk.Int64(7)
+		if in.PerformanceInsightsRetentionPeriod != nil {
+			ins.PerformanceInsightsRetentionPeriod = in.PerformanceInsightsRetentionPeriod
+		}
+	}
+	if v := awssdk.StringValue(in.PreferredBackupWindow); v != "" {
+		ins.PreferredBackupWindow = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredMaintenanceWindow); v != "" {
+		ins.PreferredMaintenanceWindow = awssdk.String(v)
+	}
+	if awssdk.BoolValue(ins.StorageEncrypted) {
+		key, err := b.usableKey(in.KmsKeyId)
+		if err != nil {
+			return nil, err
+		}
+		ins.KmsKeyId = key.Arn
+	}
+
+	err := b.placeInstance(ins, in.DBSubnetGroupName, in.AvailabilityZone, in.MultiAZ, in.Port,
+		in.VpcSecurityGroupIds, in.DeletionProtection)
+	if err != nil {
+		return nil, err
+	}
+	b.addInstance(id, ins)
+	b.rds.passwords[id] = awssdk.StringValue(in.MasterUserPassword)
+	return ins, nil
+}
+
+func (b *Backend) restoreDBInstance(in *awsrds.RestoreDBInstanceFromDBSnapshotInput) (*awsrds.DBInstance, error) {
+	id := awssdk.StringValue(in.DBInstanceIdentifier)
+	if _, ok := b.rds.instances[id]; ok {
+		return nil, awserr.New(awsrds.ErrCodeDBInstanceAlreadyExistsFault, fmt.Sprintf(
+			"DB Instance %s already exists", id), nil)
+	}
+	snap, ok := b.rds.snapshots[awssdk.StringValue(in.DBSnapshotIdentifier)]
+	if !ok {
+		return nil, awserr.New(awsrds.ErrCodeDBSnapshotNotFoundFault, fmt.Sprintf("DBSnapshot %s not found",
+			awssdk.StringValue(in.DBSnapshotIdentifier)), nil)
+	}
+	if awssdk.BoolValue(snap.snapshot.Encrypted) {
+		if _, err := b.usableKey(snap.snapshot.KmsKeyId); err != nil {
+			return nil, err
+		}
+	}
+
+	// The restored instance is what the snapshot was taken of, with the given settings on top
+	restored := snap.instance
+	ins := &restored
+	if v := awssdk.StringValue(in.DBInstanceClass); v != "" {
+		ins.DBInstanceClass = awssdk.String(v)
+	}
+	if in.AutoMinorVersionUpgrade != nil {
+		ins.AutoMinorVersionUpgrade = in.AutoMinorVersionUpgrade
+	}
+	if in.CopyTagsToSnapshot != nil {
+		ins.CopyTagsToSnapshot = in.CopyTagsToSnapshot
+	}
+	if in.PubliclyAccessible != nil {
+		ins.PubliclyAccessible = in.PubliclyAccessible
+	}
+	if v := awssdk.StringValue(in.StorageType); v != "" {
+		ins.StorageType = awssdk.String(v)
+	}
+	if in.Iops != nil {
+		ins.Iops = in.Iops
+	}
+	port := in.Port
+	if awssdk.Int64Value(port) == 0 {
+		port = snap.snapshot.Port
+	}
+	err := b.placeInstance(ins, in.DBSubnetGroupName, in.AvailabilityZone, in.MultiAZ, port,
+		in.VpcSecurityGroupIds, in.DeletionProtection)
+	if err != nil {
+		return nil, err
+	}
+	b.addInstance(id, ins)
+	b.rds.passwords[id] = b.rds.passwords[awssdk.StringValue(snap.snapshot.DBInstanceIdentifier)]
+	return ins, nil
+}
+
+// createDBInstanceReadReplica creates a replica of an instance of the Backend. Sources in "other regions" are given
+// by their ARN, and are served from the very same state.
+func (b *Backend) createDBInstanceReadReplica(in *awsrds.CreateDBInstanceReadReplicaInput) (*awsrds.DBInstance,
+	error) {
+	id := awssdk.StringValue(in.DBInstanceIdentifier)
+	if _, ok := b.rds.instances[id]; ok {
+		return nil, awserr.New(awsrds.ErrCodeDBInstanceAlreadyExistsFault, fmt.Sprintf(
+			"DB Instance %s already exists", id), nil)
+	}
+	sourceRef := instanceIdentifier(awssdk.StringValue(in.SourceDBInstanceIdentifier))
+	source, err := b.instance(awssdk.String(sourceRef))
+	if err != nil {
+		return nil, err
+	}
+	if source.DBClusterIdentifier != nil || source.ReadReplicaSourceDBInstanceIdentifier != nil {
+		return nil, invalidParameterValue(fmt.Sprintf("DB Instance %s can't be the source of a read replica",
+			sourceRef))
+	}
+	if awssdk.StringValue(source.DBInstanceStatus) != availableState {
+		return nil, awserr.New(awsrds.ErrCodeInvalidDBInstanceStateFault, fmt.Sprintf(
+			"DB Instance %s is not in available state", sourceRef), nil)
+	}
+	if awssdk.Int64Value(source.BackupRetentionPeriod) == 0 {
+		return nil, awserr.New(awsrds.ErrCodeInvalidDBInstanceStateFault, fmt.Sprintf(
+			"automated backups are not enabled for DB Instance %s", sourceRef), nil)
+	}
+
+	// The replica is a copy of its source, with the given settings on top
+	replica := *source
+	ins := &replica
+	ins.BackupRetentionPeriod = awssdk.Int64(0)
+	ins.ReadReplicaDBInstanceIdentifiers = nil
+	ins.ReadReplicaSourceDBInstanceIdentifier = in.SourceDBInstanceIdentifier
+	if v := awssdk.StringValue(in.DBInstanceClass); v != "" {
+		ins.DBInstanceClass = awssdk.String(v)
+	}
+	if in.AutoMinorVersionUpgrade != nil {
+		ins.AutoMinorVersionUpgrade = in.AutoMinorVersionUpgrade
+	}
+	if in.CopyTagsToSnapshot != nil {
+		ins.CopyTagsToSnapshot = in.CopyTagsToSnapshot
+	}
+	if in.PubliclyAccessible != nil {
+		ins.PubliclyAccessible = in.PubliclyAccessible
+	}
+	if awssdk.BoolValue(source.StorageEncrypted) {
+		keyRef := in.KmsKeyId
+		if awssdk.StringValue(keyRef) == "" {
+			if awssdk.StringValue(in.SourceRegion) != "" {
+				return nil, invalidParameterValue("KmsKeyId is required for cross-region read replicas of " +
+					"encrypted DB Instances")
+			}
+			keyRef = source.KmsKeyId
+		}
+		key, err := b.usableKey(keyRef)
+		if err != nil {
+			return nil, err
+		}
+		ins.KmsKeyId = key.Arn
+	} else if awssdk.StringValue(in.KmsKeyId) != "" {
+		return nil, awserr.New("InvalidParameterCombination",
+			"KmsKeyId can only be specified for read replicas of encrypted DB Instances", nil)
+	}
+
+	subnetGroup := in.DBSubnetGroupName
+	if awssdk.StringValue(subnetGroup) == "" && source.DBSubnetGroup != nil {
+		subnetGroup = source.DBSubnetGroup.DBSubnetGroupName
+	}
+	securityGroups := in.VpcSecurityGroupIds
+	if securityGroups == nil {
+		for _, sg := range source.VpcSecurityGroups {
+			securityGroups = append(securityGroups, sg.VpcSecurityGroupId)
+		}
+	}
+	err = b.placeInstance(ins, subnetGroup, in.AvailabilityZone, in.MultiAZ, in.Port, securityGroups,
+		in.DeletionProtection)
+	if err != nil {
+		return nil, err
+	}
+	b.addInstance(id, ins)
+	source.ReadReplicaDBInstanceIdentifiers = append(source.ReadReplicaDBInstanceIdentifiers,
+		awssdk.String(id))
+	b.rds.passwords[id] = b.rds.passwords[sourceRef]
+	return ins, nil
+}
+
+// placeInstance sets up the networking of a new DB Instance, which works the same for created and restored ones
+func (b *Backend) placeInstance(ins *awsrds.DBInstance, subnetGroup, zone *string, multiAZ *bool, port *int64,
+	securityGroups []*string, deletionProtection *bool) error {
+
+	if awssdk.StringValue(zone) != "" && awssdk.BoolValue(multiAZ) {
+		return awserr.New("InvalidParameterCombination",
+			"requesting a specific availability zone is not valid for Multi-AZ instances", nil)
+	}
+	ins.DBSubnetGroup = nil
+	if awssdk.StringValue(subnetGroup) != "" {
+		group, err := b.subnetGroup(subnetGroup)
+		if err != nil {
+			return err
+		}
+		ins.DBSubnetGroup = group
+	}
+	ins.MultiAZ = awssdk.Bool(awssdk.BoolValue(multiAZ))
+	ins.AvailabilityZone = awssdk.String(Region + "a")
+	if awssdk.StringValue(zone) != "" {
+		ins.AvailabilityZone = zone
+	}
+	if awssdk.BoolValue(ins.MultiAZ) {
+		ins.SecondaryAvailabilityZone = awssdk.String(Region + "b")
+	}
+	ins.VpcSecurityGroups = securityGroupMemberships(securityGroups)
+	ins.DeletionProtection = awssdk.Bool(awssdk.BoolValue(deletionProtection))
+
+	if awssdk.Int64Value(port) == 0 {
+		port = awssdk.Int64(defaultPorts[awssdk.StringValue(ins.Engine)])
+	}
+	ins.DbInstancePort = port
+	return nil
+}
+
+func (b *Backend) addInstance(id string, ins *awsrds.DBInstance) {
+	ins.DBInstanceIdentifier = awssdk.String(id)
+	ins.DBInstanceArn = awssdk.String(arn("rds", Region, "db:"+id))
+	ins.DBInstanceStatus = awssdk.String(availableState)
+	ins.DbiResourceId = awssdk.String(b.nextID("db-", 26))
+	ins.Endpoint = &awsrds.Endpoint{
+		Address: awssdk.String(fmt.Sprintf("%s.fake.%s.rds.amazonaws.com", id, Region)),
+		Port:    ins.DbInstancePort,
+	}
+	ins.InstanceCreateTime = now()
+	b.rds.instances[id] = ins
+}
+
+func (b *Backend) modifyDBInstance(in *awsrds.ModifyDBInstanceInput) (*awsrds.DBInstance, error) {
+	ins, err := b.instance(in.DBInstanceIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if v := awssdk.StringValue(in.DBSubnetGroupName); v != "" {
+		group, err := b.subnetGroup(in.DBSubnetGroupName)
+		if err != nil {
+			return nil, err
+		}
+		ins.DBSubnetGroup = group
+	}
+	if in.AllocatedStorage != nil {
+		if *in.AllocatedStorage < awssdk.Int64Value(ins.AllocatedStorage) {
+			return nil, invalidParameterValue("AllocatedStorage can't be decreased")
+		}
+		ins.AllocatedStorage = in.AllocatedStorage
+	}
+	if v := awssdk.StringValue(in.DBInstanceClass); v != "" {
+		ins.DBInstanceClass = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.EngineVersion); v != "" {
+		ins.EngineVersion = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredBackupWindow); v != "" {
+		ins.PreferredBackupWindow = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredMaintenanceWindow); v != "" {
+		ins.PreferredMaintenanceWindow = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.StorageType); v != "" {
+		ins.StorageType = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.MasterUserPassword); v != "" {
+		b.rds.passwords[awssdk.StringValue(ins.DBInstanceIdentifier)] = v
+	}
+	if in.VpcSecurityGroupIds != nil {
+		ins.VpcSecurityGroups = securityGroupMemberships(in.VpcSecurityGroupIds)
+	}
+	if in.MultiAZ != nil {
+		ins.MultiAZ = in.MultiAZ
+		ins.SecondaryAvailabilityZone = nil
+		if *in.MultiAZ {
+			ins.SecondaryAvailabilityZone = awssdk.String(Region + "b")
+		}
+	}
+	if in.EnablePerformanceInsights != nil {
+		ins.PerformanceInsightsEnabled = in.EnablePerformanceInsights
+		ins.PerformanceInsightsRetentionPeriod = nil
+		if *in.EnablePerformanceInsights {
+			ins.PerformanceInsightsRetentionPeriod = awssdk.Int64(7)
+			if in.PerformanceInsightsRetentionPeriod != nil {
+				ins.PerformanceInsightsRetentionPeriod = in.PerformanceInsightsRetentionPeriod
+			}
+		}
+	}
+	if in.BackupRetentionPeriod != nil && *in.BackupRetentionPeriod == 0 &&
+		len(ins.ReadReplicaDBInstanceIdentifiers) > 0 {
+		return nil, awserr.New("InvalidParameterCombination",
+			"automated backups can't be disabled for DB Instances with read replicas", nil)
+	}
+	for _, field := range []struct {
+		in  *int64
+		out **int64
+	}{
+		{in.BackupRetentionPeriod, &ins.BackupRetentionPeriod},
+		{in.Iops, &ins.Iops},
+		{in.MaxAllocatedStorage, &ins.MaxAllocatedStorage},
+		{in.MonitoringInterval, &ins.MonitoringInterval},
+	} {
+		if field.in != nil {
+			*field.out = field.in
+		}
+	}
+	for _, field := range []struct {
+		in  *bool
+		out **bool
+	}{
+		{in.AutoMinorVersionUpgrade, &ins.AutoMinorVersionUpgrade},
+		{in.CopyTagsToSnapshot, &ins.CopyTagsToSnapshot},
+		{in.DeletionProtection, &ins.DeletionProtection},
+		{in.PubliclyAccessible, &ins.PubliclyAccessible},
+	} {
+		if field.in != nil {
+			*field.out = field.in
+		}
+	}
+	return ins, nil
+}
+
+func (b *Backend) deleteDBInstance(in *awsrds.DeleteDBInstanceInput) (*awsrds.DBInstance, error) {
+	ins, err := b.instance(in.DBInstanceIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if ins.DBClusterIdentifier != nil {
+		return b.deleteClusterInstance(ins, in)
+	}
+	id := awssdk.StringValue(ins.DBInstanceIdentifier)
+	if awssdk.BoolValue(ins.DeletionProtection) {
+		return nil, awserr.New("InvalidParameterCombination",
+			"cannot delete protected DB Instance, please disable deletion protection and try again", nil)
+	}
+	if awssdk.StringValue(ins.DBInstanceStatus) != availableState {
+		return nil, awserr.New(awsrds.ErrCodeInvalidDBInstanceStateFault, fmt.Sprintf(
+			"DB Instance %s is not in available state", id), nil)
+	}
+
+	if ins.ReadReplicaSourceDBInstanceIdentifier != nil && !awssdk.BoolValue(in.SkipFinalSnapshot) {
+		return nil, awserr.New("InvalidParameterCombination",
+			"FinalDBSnapshotIdentifier can not be specified when deleting a read replica", nil)
+	}
+
+	if !awssdk.BoolValue(in.SkipFinalSnapshot) {
+		snapID := awssdk.StringValue(in.FinalDBSnapshotIdentifier)
+		if snapID == "" {
+			return nil, awserr.New("InvalidParameterCombination",
+				"FinalDBSnapshotIdentifier is required unless SkipFinalSnapshot is specified", nil)
+		}
+		if _, ok := b.rds.snapshots[snapID]; ok {
+			return nil, awserr.New(awsrds.ErrCodeDBSnapshotAlreadyExistsFault, fmt.Sprintf(
+				"cannot create the snapshot because a snapshot with the identifier %s already exists", snapID), nil)
+		}
+		b.rds.snapshots[snapID] = &snapshot{
+			snapshot: &awsrds.DBSnapshot{
+				AllocatedStorage:     ins.AllocatedStorage,
+				AvailabilityZone:     ins.AvailabilityZone,
+				DBInstanceIdentifier: ins.DBInstanceIdentifier,
+				DBSnapshotArn:        awssdk.String(arn("rds", Region, "snapshot:"+snapID)),
+				DBSnapshotIdentifier: awssdk.String(snapID),
+				Encrypted:            ins.StorageEncrypted,
+				Engine:               ins.Engine,
+				EngineVersion:        ins.EngineVersion,
+				InstanceCreateTime:   ins.InstanceCreateTime,
+				Iops:                 ins.Iops,
+				KmsKeyId:             ins.KmsKeyId,
+				MasterUsername:       ins.MasterUsername,
+				Port:                 ins.DbInstancePort,
+				SnapshotCreateTime:   now(),
+				SnapshotType:         awssdk.String("manual"),
+				Status:               awssdk.String(availableState),
+				StorageType:          ins.StorageType,
+				VpcId:                awssdk.String(VpcID),
+			},
+			instance: *ins,
+		}
+	}
+
+	b.detachReplica(ins)
+	delete(b.rds.instances, id)
+	ins.DBInstanceStatus = awssdk.String("deleting")
+	return ins, nil
+}
+
+// detachReplica drops the DB Instance from the replicas of its source. If it's a source itself, its replicas are
+// promoted to standalone instances, like RDS does.
+func (b *Backend) detachReplica(ins *awsrds.DBInstance) {
+	id := awssdk.StringValue(ins.DBInstanceIdentifier)
+	if ref := awssdk.StringValue(ins.ReadReplicaSourceDBInstanceIdentifier); ref != "" {
+		if source, ok := b.rds.instances[instanceIdentifier(ref)]; ok {
+			var replicas []*string
+			for _, replica := range source.ReadReplicaDBInstanceIdentifiers {
+				if awssdk.StringValue(replica) != id {
+					replicas = append(replicas, replica)
+				}
+			}
+			source.ReadReplicaDBInstanceIdentifiers = replicas
+		}
+	}
+	for _, replica := range ins.ReadReplicaDBInstanceIdentifiers {
+		if promoted, ok := b.rds.instances[awssdk.StringValue(replica)]; ok {
+			promoted.ReadReplicaSourceDBInstanceIdentifier = nil
+		}
+	}
+}
+
+// instanceIdentifier returns the identifier of the DB Instance given by its identifier or its ARN
+func instanceIdentifier(ref string) string {
+	if i := strings.LastIndex(ref, ":db:"); i >= 0 {
+		return ref[i+len(":db:"):]
+	}
+	return ref
+}
+
+// usableKey looks up the KMS key an encrypted DB Instance is created with, which RDS needs to be enabled
+func (b *Backend) usableKey(ref *string) (*awskms.KeyMetadata, error) {
+	if awssdk.StringValue(ref) == "" {
+		return nil, invalidParameterValue("encrypted DB Instances need a KMS key in the fake backend")
+	}
+	key, err := b.key(ref)
+	if err != nil || awssdk.StringValue(key.KeyState) != awskms.KeyStateEnabled {
+		return nil, awserr.New(awsrds.ErrCodeKMSKeyNotAccessibleFault, fmt.Sprintf(
+			"the specified KMS key %s does not exist, is not enabled or you do not have permissions to access it",
+			awssdk.StringValue(ref)), nil)
+	}
+	return key, nil
+}
+
+func subnets(ids []*string) []*awsrds.Subnet {
+	var out []*awsrds.Subnet
+	for _, id := range ids {
+		out = append(out, &awsrds.Subnet{
+			SubnetAvailabilityZone: &awsrds.AvailabilityZone{Name: awssdk.String(Region + "a")},
+			SubnetIdentifier:       id,
+			SubnetStatus:           awssdk.String("Active"),
+		})
+	}
+	return out
+}
+
+func securityGroupMemberships(ids []*string) []*awsrds.VpcSecurityGroupMembership {
+	var out []*awsrds.VpcSecurityGroupMembership
+	for _, id := range ids {
+		out = append(out, &awsrds.VpcSecurityGroupMembership{
+			Status:             awssdk.String("active"),
+			VpcSecurityGroupId: id,
+		})
+	}
+	return out
+}
+
+func invalidParameterValue(msg string) error {
+	return awserr.New("InvalidParameterValue", msg, nil)
+}
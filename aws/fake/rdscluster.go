@@ -0,0 +1,427 @@
+package fake
+
+import (
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsrds "github.com/aws/aws-sdk-go/service/rds"
+)
+
+// clusterSnapshot keeps the cluster a DB Cluster Snapshot was taken of, as that's what gets restored
+type clusterSnapshot struct {
+	snapshot *awsrds.DBClusterSnapshot
+	cluster  awsrds.DBCluster
+}
+
+// SetDBClusterStatus sets the status of a DB Cluster, e.g. to simulate a cluster that isn't available
+func (b *Backend) SetDBClusterStatus(id, status string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cluster, err := b.cluster(awssdk.String(id))
+	if err != nil {
+		return err
+	}
+	cluster.Status = awssdk.String(status)
+	return nil
+}
+
+func (b *Backend) serveRDSCluster(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *awsrds.CreateDBClusterInput:
+		cluster, err := b.createDBCluster(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.CreateDBClusterOutput{DBCluster: cluster}, nil
+	case *awsrds.RestoreDBClusterFromSnapshotInput:
+		cluster, err := b.restoreDBCluster(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.RestoreDBClusterFromSnapshotOutput{DBCluster: cluster}, nil
+	case *awsrds.DescribeDBClustersInput:
+		out := &awsrds.DescribeDBClustersOutput{}
+		if in.DBClusterIdentifier != nil {
+			cluster, err := b.cluster(in.DBClusterIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			out.DBClusters = []*awsrds.DBCluster{cluster}
+			return out, nil
+		}
+		for _, id := range sortedKeys(b.rds.clusters) {
+			out.DBClusters = append(out.DBClusters, b.rds.clusters[id])
+		}
+		return out, nil
+	case *awsrds.ModifyDBClusterInput:
+		cluster, err := b.modifyDBCluster(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.ModifyDBClusterOutput{DBCluster: cluster}, nil
+	case *awsrds.DeleteDBClusterInput:
+		cluster, err := b.deleteDBCluster(in)
+		if err != nil {
+			return nil, err
+		}
+		return &awsrds.DeleteDBClusterOutput{DBCluster: cluster}, nil
+	case *awsrds.DescribeDBClusterSnapshotsInput:
+		out := &awsrds.DescribeDBClusterSnapshotsOutput{}
+		if in.DBClusterSnapshotIdentifier != nil {
+			snap, err := b.clusterSnapshot(in.DBClusterSnapshotIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			out.DBClusterSnapshots = []*awsrds.DBClusterSnapshot{snap.snapshot}
+			return out, nil
+		}
+		for _, id := range sortedKeys(b.rds.clusterSnapshots) {
+			snap := b.rds.clusterSnapshots[id]
+			if in.DBClusterIdentifier == nil ||
+				awssdk.StringValue(snap.snapshot.DBClusterIdentifier) == awssdk.StringValue(in.DBClusterIdentifier) {
+				out.DBClusterSnapshots = append(out.DBClusterSnapshots, snap.snapshot)
+			}
+		}
+		return out, nil
+	case *awsrds.DeleteDBClusterSnapshotInput:
+		snap, err := b.clusterSnapshot(in.DBClusterSnapshotIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		delete(b.rds.clusterSnapshots, awssdk.StringValue(in.DBClusterSnapshotIdentifier))
+		snap.snapshot.Status = awssdk.String("deleted")
+		return &awsrds.DeleteDBClusterSnapshotOutput{DBClusterSnapshot: snap.snapshot}, nil
+	}
+	return nil, notImplemented(awsrds.ServiceName, op)
+}
+
+func (b *Backend) cluster(id *string) (*awsrds.DBCluster, error) {
+	cluster, ok := b.rds.clusters[awssdk.StringValue(id)]
+	if !ok {
+		return nil, awserr.New(awsrds.ErrCodeDBClusterNotFoundFault, fmt.Sprintf("DBCluster %s not found",
+			awssdk.StringValue(id)), nil)
+	}
+	return cluster, nil
+}
+
+func (b *Backend) clusterSnapshot(id *string) (*clusterSnapshot, error) {
+	snap, ok := b.rds.clusterSnapshots[awssdk.StringValue(id)]
+	if !ok {
+		return nil, awserr.New(awsrds.ErrCodeDBClusterSnapshotNotFoundFault, fmt.Sprintf(
+			"DBClusterSnapshot %s not found", awssdk.StringValue(id)), nil)
+	}
+	return snap, nil
+}
+
+func (b *Backend) createDBCluster(in *awsrds.CreateDBClusterInput) (*awsrds.DBCluster, error) {
+	id := awssdk.StringValue(in.DBClusterIdentifier)
+	if _, ok := b.rds.clusters[id]; ok {
+		return nil, awserr.New(awsrds.ErrCodeDBClusterAlreadyExistsFault, fmt.Sprintf(
+			"DB Cluster %s already exists", id), nil)
+	}
+	engine := awssdk.StringValue(in.Engine)
+	if _, ok := defaultPorts[engine]; !ok {
+		return nil, invalidParameterValue(fmt.Sprintf("invalid DB engine: %s", engine))
+	}
+	if awssdk.StringValue(in.MasterUsername) == "" || awssdk.StringValue(in.MasterUserPassword) == "" {
+		return nil, invalidParameterValue("MasterUsername and MasterUserPassword are required")
+	}
+
+	cluster := &awsrds.DBCluster{
+		BackupRetentionPeriod:      awssdk.Int64(1),
+		CopyTagsToSnapshot:         awssdk.Bool(awssdk.BoolValue(in.CopyTagsToSnapshot)),
+		DatabaseName:               in.DatabaseName,
+		Engine:                     awssdk.String(engine),
+		EngineMode:                 awssdk.String("provisioned"),
+		EngineVersion:              awssdk.String(defaultEngineVersions[engine]),
+		MasterUsername:             in.MasterUsername,
+		PreferredBackupWindow:      awssdk.String("03:00-03:30"),
+		PreferredMaintenanceWindow: awssdk.String("sun:05:00-sun:05:30"),
+		StorageEncrypted:           awssdk.Bool(awssdk.BoolValue(in.StorageEncrypted)),
+	}
+	if in.BackupRetentionPeriod != nil {
+		cluster.BackupRetentionPeriod = in.BackupRetentionPeriod
+	}
+	if v := awssdk.StringValue(in.EngineVersion); v != "" {
+		cluster.EngineVersion = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredBackupWindow); v != "" {
+		cluster.PreferredBackupWindow = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredMaintenanceWindow); v != "" {
+		cluster.PreferredMaintenanceWindow = awssdk.String(v)
+	}
+	if awssdk.BoolValue(cluster.StorageEncrypted) {
+		key, err := b.usableKey(in.KmsKeyId)
+		if err != nil {
+			return nil, err
+		}
+		cluster.KmsKeyId = key.Arn
+	}
+
+	err := b.placeCluster(cluster, in.DBSubnetGroupName, in.Port, in.VpcSecurityGroupIds, in.DeletionProtection)
+	if err != nil {
+		return nil, err
+	}
+	b.addCluster(id, cluster)
+	b.rds.passwords[id] = awssdk.StringValue(in.MasterUserPassword)
+	return cluster, nil
+}
+
+func (b *Backend) restoreDBCluster(in *awsrds.RestoreDBClusterFromSnapshotInput) (*awsrds.DBCluster, error) {
+	id := awssdk.StringValue(in.DBClusterIdentifier)
+	if _, ok := b.rds.clusters[id]; ok {
+		return nil, awserr.New(awsrds.ErrCodeDBClusterAlreadyExistsFault, fmt.Sprintf(
+			"DB Cluster %s already exists", id), nil)
+	}
+	snap, err := b.clusterSnapshot(in.SnapshotIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if awssdk.StringValue(in.Engine) != awssdk.StringValue(snap.snapshot.Engine) {
+		return nil, invalidParameterValue(fmt.Sprintf("the engine of the snapshot is %s",
+			awssdk.StringValue(snap.snapshot.Engine)))
+	}
+	if awssdk.BoolValue(snap.snapshot.StorageEncrypted) {
+		if _, err := b.usableKey(snap.snapshot.KmsKeyId); err != nil {
+			return nil, err
+		}
+	}
+
+	// The restored cluster is what the snapshot was taken of, with the given settings on top, but without instances
+	restored := snap.cluster
+	cluster := &restored
+	cluster.DBClusterMembers = nil
+	if in.CopyTagsToSnapshot != nil {
+		cluster.CopyTagsToSnapshot = in.CopyTagsToSnapshot
+	}
+	if v := awssdk.StringValue(in.EngineVersion); v != "" {
+		cluster.EngineVersion = awssdk.String(v)
+	}
+	port := in.Port
+	if awssdk.Int64Value(port) == 0 {
+		port = snap.snapshot.Port
+	}
+	err = b.placeCluster(cluster, in.DBSubnetGroupName, port, in.VpcSecurityGroupIds, in.DeletionProtection)
+	if err != nil {
+		return nil, err
+	}
+	b.addCluster(id, cluster)
+	b.rds.passwords[id] = b.rds.passwords[awssdk.StringValue(snap.snapshot.DBClusterIdentifier)]
+	return cluster, nil
+}
+
+// placeCluster sets up the networking of a new DB Cluster, which works the same for created and restored ones
+func (b *Backend) placeCluster(cluster *awsrds.DBCluster, subnetGroup *string, port *int64,
+	securityGroups []*string, deletionProtection *bool) error {
+	cluster.DBSubnetGroup = nil
+	if awssdk.StringValue(subnetGroup) != "" {
+		group, err := b.subnetGroup(subnetGroup)
+		if err != nil {
+			return err
+		}
+		cluster.DBSubnetGroup = group.DBSubnetGroupName
+	}
+	cluster.AvailabilityZones = awssdk.StringSlice([]string{Region + "a", Region + "b", Region + "c"})
+	cluster.VpcSecurityGroups = securityGroupMemberships(securityGroups)
+	cluster.DeletionProtection = awssdk.Bool(awssdk.BoolValue(deletionProtection))
+
+	if awssdk.Int64Value(port) == 0 {
+		port = awssdk.Int64(defaultPorts[awssdk.StringValue(cluster.Engine)])
+	}
+	cluster.Port = port
+	return nil
+}
+
+func (b *Backend) addCluster(id string, cluster *awsrds.DBCluster) {
+	cluster.DBClusterIdentifier = awssdk.String(id)
+	cluster.DBClusterArn = awssdk.String(arn("rds", Region, "cluster:"+id))
+	cluster.Status = awssdk.String(availableState)
+	cluster.DbClusterResourceId = awssdk.String(b.nextID("cluster-", 26))
+	cluster.Endpoint = awssdk.String(fmt.Sprintf("%s.cluster-fake.%s.rds.amazonaws.com", id, Region))
+	cluster.ReaderEndpoint = awssdk.String(fmt.Sprintf("%s.cluster-ro-fake.%s.rds.amazonaws.com", id, Region))
+	cluster.ClusterCreateTime = now()
+	b.rds.clusters[id] = cluster
+}
+
+func (b *Backend) modifyDBCluster(in *awsrds.ModifyDBClusterInput) (*awsrds.DBCluster, error) {
+	cluster, err := b.cluster(in.DBClusterIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if v := awssdk.StringValue(in.EngineVersion); v != "" {
+		cluster.EngineVersion = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredBackupWindow); v != "" {
+		cluster.PreferredBackupWindow = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.PreferredMaintenanceWindow); v != "" {
+		cluster.PreferredMaintenanceWindow = awssdk.String(v)
+	}
+	if v := awssdk.StringValue(in.MasterUserPassword); v != "" {
+		b.rds.passwords[awssdk.StringValue(cluster.DBClusterIdentifier)] = v
+	}
+	if in.VpcSecurityGroupIds != nil {
+		cluster.VpcSecurityGroups = securityGroupMemberships(in.VpcSecurityGroupIds)
+	}
+	if in.BackupRetentionPeriod != nil {
+		cluster.BackupRetentionPeriod = in.BackupRetentionPeriod
+	}
+	if in.Port != nil {
+		cluster.Port = in.Port
+	}
+	if in.CopyTagsToSnapshot != nil {
+		cluster.CopyTagsToSnapshot = in.CopyTagsToSnapshot
+	}
+	if in.DeletionProtection != nil {
+		cluster.DeletionProtection = in.DeletionProtection
+	}
+	return cluster, nil
+}
+
+func (b *Backend) deleteDBCluster(in *awsrds.DeleteDBClusterInput) (*awsrds.DBCluster, error) {
+	cluster, err := b.cluster(in.DBClusterIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	id := awssdk.StringValue(cluster.DBClusterIdentifier)
+	if awssdk.BoolValue(cluster.DeletionProtection) {
+		return nil, awserr.New("InvalidParameterCombination",
+			"cannot delete protected Cluster, please disable deletion protection and try again", nil)
+	}
+	if awssdk.StringValue(cluster.Status) != availableState {
+		return nil, awserr.New(awsrds.ErrCodeInvalidDBClusterStateFault, fmt.Sprintf(
+			"DB Cluster %s is not in available state", id), nil)
+	}
+	if len(cluster.DBClusterMembers) != 0 {
+		return nil, awserr.New(awsrds.ErrCodeInvalidDBClusterStateFault,
+			"cluster cannot be deleted, it still contains DB instances in non-deleting state", nil)
+	}
+
+	if !awssdk.BoolValue(in.SkipFinalSnapshot) {
+		snapID := awssdk.StringValue(in.FinalDBSnapshotIdentifier)
+		if snapID == "" {
+			return nil, awserr.New("InvalidParameterCombination",
+				"FinalDBSnapshotIdentifier is required unless SkipFinalSnapshot is specified", nil)
+		}
+		if _, ok := b.rds.clusterSnapshots[snapID]; ok {
+			return nil, awserr.New(awsrds.ErrCodeDBClusterSnapshotAlreadyExistsFault, fmt.Sprintf(
+				"cannot create the cluster snapshot because one with the identifier %s already exists", snapID), nil)
+		}
+		b.rds.clusterSnapshots[snapID] = &clusterSnapshot{
+			snapshot: &awsrds.DBClusterSnapshot{
+				AvailabilityZones:           cluster.AvailabilityZones,
+				ClusterCreateTime:           cluster.ClusterCreateTime,
+				DBClusterIdentifier:         cluster.DBClusterIdentifier,
+				DBClusterSnapshotArn:        awssdk.String(arn("rds", Region, "cluster-snapshot:"+snapID)),
+				DBClusterSnapshotIdentifier: awssdk.String(snapID),
+				Engine:                      cluster.Engine,
+				EngineVersion:               cluster.EngineVersion,
+				KmsKeyId:                    cluster.KmsKeyId,
+				MasterUsername:              cluster.MasterUsername,
+				Port:                        cluster.Port,
+				SnapshotCreateTime:          now(),
+				SnapshotType:                awssdk.String("manual"),
+				Status:                      awssdk.String(availableState),
+				StorageEncrypted:            cluster.StorageEncrypted,
+				VpcId:                       awssdk.String(VpcID),
+			},
+			cluster: *cluster,
+		}
+	}
+
+	delete(b.rds.clusters, id)
+	cluster.Status = awssdk.String("deleting")
+	return cluster, nil
+}
+
+// createClusterInstance creates a DB Instance in a DB Cluster. It gets its storage, credentials and networking from
+// the cluster, and becomes the writer if it's the first one.
+func (b *Backend) createClusterInstance(in *awsrds.CreateDBInstanceInput) (*awsrds.DBInstance, error) {
+	id := awssdk.StringValue(in.DBInstanceIdentifier)
+	if _, ok := b.rds.instances[id]; ok {
+		return nil, awserr.New(awsrds.ErrCodeDBInstanceAlreadyExistsFault, fmt.Sprintf(
+			"DB Instance %s already exists", id), nil)
+	}
+	cluster, err := b.cluster(in.DBClusterIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	if awssdk.StringValue(in.Engine) != awssdk.StringValue(cluster.Engine) {
+		return nil, invalidParameterValue(fmt.Sprintf("the engine of DB Cluster %s is %s",
+			awssdk.StringValue(cluster.DBClusterIdentifier), awssdk.StringValue(cluster.Engine)))
+	}
+	if awssdk.StringValue(in.DBInstanceClass) == "" {
+		return nil, invalidParameterValue("DBInstanceClass is required")
+	}
+	if in.AllocatedStorage != nil || in.MasterUserPassword != nil || in.StorageEncrypted != nil {
+		return nil, awserr.New("InvalidParameterCombination",
+			"AllocatedStorage, MasterUserPassword and StorageEncrypted are managed by the DB Cluster", nil)
+	}
+
+	ins := &awsrds.DBInstance{
+		AutoMinorVersionUpgrade:    awssdk.Bool(awssdk.BoolValue(in.AutoMinorVersionUpgrade)),
+		BackupRetentionPeriod:      cluster.BackupRetentionPeriod,
+		DBClusterIdentifier:        cluster.DBClusterIdentifier,
+		DBInstanceClass:            in.DBInstanceClass,
+		DBName:                     cluster.DatabaseName,
+		Engine:                     cluster.Engine,
+		EngineVersion:              cluster.EngineVersion,
+		KmsKeyId:                   cluster.KmsKeyId,
+		MasterUsername:             cluster.MasterUsername,
+		MonitoringInterval:         awssdk.Int64(awssdk.Int64Value(in.MonitoringInterval)),
+		PerformanceInsightsEnabled: awssdk.Bool(awssdk.BoolValue(in.EnablePerformanceInsights)),
+		PreferredBackupWindow:      cluster.PreferredBackupWindow,
+		PreferredMaintenanceWindow: cluster.PreferredMaintenanceWindow,
+		PubliclyAccessible:         awssdk.Bool(awssdk.BoolValue(in.PubliclyAccessible)),
+		StorageEncrypted:           cluster.StorageEncrypted,
+		StorageType:                awssdk.String("aurora"),
+	}
+	err = b.placeInstance(ins, cluster.DBSubnetGroup, in.AvailabilityZone, nil, cluster.Port, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	ins.VpcSecurityGroups = cluster.VpcSecurityGroups
+	b.addInstance(id, ins)
+	cluster.DBClusterMembers = append(cluster.DBClusterMembers, &awsrds.DBClusterMember{
+		DBInstanceIdentifier: awssdk.String(id),
+		IsClusterWriter:      awssdk.Bool(len(cluster.DBClusterMembers) == 0),
+		PromotionTier:        awssdk.Int64(1),
+	})
+	return ins, nil
+}
+
+// deleteClusterInstance deletes a DB Instance of a DB Cluster. If it was the writer, another instance is promoted.
+func (b *Backend) deleteClusterInstance(ins *awsrds.DBInstance, in *awsrds.DeleteDBInstanceInput) (
+	*awsrds.DBInstance, error) {
+	id := awssdk.StringValue(ins.DBInstanceIdentifier)
+	if awssdk.StringValue(in.FinalDBSnapshotIdentifier) != "" {
+		return nil, awserr.New("InvalidParameterCombination",
+			"FinalDBSnapshotIdentifier can not be specified when deleting a DB Instance of a DB Cluster", nil)
+	}
+	if awssdk.StringValue(ins.DBInstanceStatus) != availableState {
+		return nil, awserr.New(awsrds.ErrCodeInvalidDBInstanceStateFault, fmt.Sprintf(
+			"DB Instance %s is not in available state", id), nil)
+	}
+
+	if cluster, ok := b.rds.clusters[awssdk.StringValue(ins.DBClusterIdentifier)]; ok {
+		var members []*awsrds.DBClusterMember
+		var writerGone bool
+		for _, member := range cluster.DBClusterMembers {
+			if awssdk.StringValue(member.DBInstanceIdentifier) == id {
+				writerGone = awssdk.BoolValue(member.IsClusterWriter)
+				continue
+			}
+			members = append(members, member)
+		}
+		if writerGone && len(members) > 0 {
+			members[0].IsClusterWriter = awssdk.Bool(true)
+		}
+		cluster.DBClusterMembers = members
+	}
+
+	delete(b.rds.instances, id)
+	ins.DBInstanceStatus = awssdk.String("deleting")
+	return ins, nil
+}
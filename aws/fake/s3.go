@@ -0,0 +1,246 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+)
+
+const (
+	// OwnerID is the canonical user ID of the owner of all buckets
+	OwnerID = "fakefakefakefakefakefakefakefakefakefakefakefakefakefakefakefake"
+
+	allUsersGroupURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroupURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+type bucket struct {
+	created           time.Time
+	location          string
+	acl               string
+	versioning        *string
+	accelerate        *string
+	objectLock        bool
+	publicAccessBlock *awss3.PublicAccessBlockConfiguration
+	encryption        *awss3.ServerSideEncryptionConfiguration
+}
+
+type s3State struct {
+	buckets map[string]*bucket
+}
+
+func newS3State() s3State {
+	return s3State{buckets: map[string]*bucket{}}
+}
+
+func (b *Backend) serveS3(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *awss3.CreateBucketInput:
+		return b.createBucket(in)
+	case *awss3.DeleteBucketInput:
+		if _, err := b.bucket(in.Bucket); err != nil {
+			return nil, err
+		}
+		delete(b.s3.buckets, awssdk.StringValue(in.Bucket))
+		return &awss3.DeleteBucketOutput{}, nil
+	case *awss3.ListBucketsInput:
+		return b.listBuckets(), nil
+	case *awss3.GetBucketLocationInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		out := &awss3.GetBucketLocationOutput{}
+		if bkt.location != "" {
+			out.LocationConstraint = awssdk.String(bkt.location)
+		}
+		return out, nil
+	case *awss3.PutBucketAclInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		if !cannedACLs[awssdk.StringValue(in.ACL)] {
+			return nil, awserr.New("InvalidArgument", fmt.Sprintf("invalid canned ACL '%s'",
+				awssdk.StringValue(in.ACL)), nil)
+		}
+		bkt.acl = awssdk.StringValue(in.ACL)
+		return &awss3.PutBucketAclOutput{}, nil
+	case *awss3.GetBucketAclInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		return &awss3.GetBucketAclOutput{Owner: owner(), Grants: grants(bkt.acl)}, nil
+	case *awss3.PutBucketVersioningInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		status := in.VersioningConfiguration.Status
+		if bkt.objectLock && awssdk.StringValue(status) != awss3.BucketVersioningStatusEnabled {
+			return nil, awserr.New("InvalidBucketState",
+				"versioning cannot be suspended on buckets with object lock enabled", nil)
+		}
+		bkt.versioning = status
+		return &awss3.PutBucketVersioningOutput{}, nil
+	case *awss3.GetBucketVersioningInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		return &awss3.GetBucketVersioningOutput{Status: bkt.versioning}, nil
+	case *awss3.PutBucketAccelerateConfigurationInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		bkt.accelerate = in.AccelerateConfiguration.Status
+		return &awss3.PutBucketAccelerateConfigurationOutput{}, nil
+	case *awss3.GetBucketAccelerateConfigurationInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		return &awss3.GetBucketAccelerateConfigurationOutput{Status: bkt.accelerate}, nil
+	case *awss3.PutPublicAccessBlockInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		bkt.publicAccessBlock = in.PublicAccessBlockConfiguration
+		return &awss3.PutPublicAccessBlockOutput{}, nil
+	case *awss3.GetPublicAccessBlockInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		if bkt.publicAccessBlock == nil {
+			return nil, awserr.New("NoSuchPublicAccessBlockConfiguration",
+				"the public access block configuration was not found", nil)
+		}
+		return &awss3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: bkt.publicAccessBlock}, nil
+	case *awss3.PutBucketEncryptionInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		bkt.encryption = in.ServerSideEncryptionConfiguration
+		return &awss3.PutBucketEncryptionOutput{}, nil
+	case *awss3.GetBucketEncryptionInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		if bkt.encryption == nil {
+			return nil, awserr.New("ServerSideEncryptionConfigurationNotFoundError",
+				"the server side encryption configuration was not found", nil)
+		}
+		return &awss3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: bkt.encryption}, nil
+	case *awss3.GetObjectLockConfigurationInput:
+		bkt, err := b.bucket(in.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		if !bkt.objectLock {
+			return nil, awserr.New("ObjectLockConfigurationNotFoundError",
+				"object lock configuration does not exist for this bucket", nil)
+		}
+		return &awss3.GetObjectLockConfigurationOutput{ObjectLockConfiguration: &awss3.ObjectLockConfiguration{
+			ObjectLockEnabled: awssdk.String(awss3.ObjectLockEnabledEnabled),
+		}}, nil
+	}
+	return nil, notImplemented(awss3.ServiceName, op)
+}
+
+func (b *Backend) bucket(name *string) (*bucket, error) {
+	bkt, ok := b.s3.buckets[awssdk.StringValue(name)]
+	if !ok {
+		return nil, awserr.New(awss3.ErrCodeNoSuchBucket, "the specified bucket does not exist", nil)
+	}
+	return bkt, nil
+}
+
+func (b *Backend) createBucket(in *awss3.CreateBucketInput) (*awss3.CreateBucketOutput, error) {
+	name := awssdk.StringValue(in.Bucket)
+	if _, ok := b.s3.buckets[name]; ok {
+		return nil, awserr.New(awss3.ErrCodeBucketAlreadyOwnedByYou,
+			"your previous request to create the named bucket succeeded and you already own it", nil)
+	}
+	acl := awssdk.StringValue(in.ACL)
+	if acl == "" {
+		acl = awss3.BucketCannedACLPrivate
+	}
+	bkt := &bucket{
+		created:    *now(),
+		acl:        acl,
+		objectLock: awssdk.BoolValue(in.ObjectLockEnabledForBucket),
+		// Like AWS, buckets are encrypted with S3 managed keys by default
+		encryption: &awss3.ServerSideEncryptionConfiguration{Rules: []*awss3.ServerSideEncryptionRule{{
+			ApplyServerSideEncryptionByDefault: &awss3.ServerSideEncryptionByDefault{
+				SSEAlgorithm: awssdk.String(awss3.ServerSideEncryptionAes256),
+			},
+		}}},
+	}
+	if in.CreateBucketConfiguration != nil {
+		bkt.location = awssdk.StringValue(in.CreateBucketConfiguration.LocationConstraint)
+	}
+	// Object lock only works with versioning, so S3 enables it along with object lock
+	if bkt.objectLock {
+		bkt.versioning = awssdk.String(awss3.BucketVersioningStatusEnabled)
+	}
+	b.s3.buckets[name] = bkt
+	return &awss3.CreateBucketOutput{Location: awssdk.String("/" + name)}, nil
+}
+
+func (b *Backend) listBuckets() *awss3.ListBucketsOutput {
+	out := &awss3.ListBucketsOutput{Owner: owner()}
+	for name, bkt := range b.s3.buckets {
+		out.Buckets = append(out.Buckets, &awss3.Bucket{
+			Name:         awssdk.String(name),
+			CreationDate: awssdk.Time(bkt.created),
+		})
+	}
+	sort.Slice(out.Buckets, func(i, j int) bool {
+		return awssdk.StringValue(out.Buckets[i].Name) < awssdk.StringValue(out.Buckets[j].Name)
+	})
+	return out
+}
+
+var cannedACLs = map[string]bool{
+	awss3.BucketCannedACLPrivate:           true,
+	awss3.BucketCannedACLPublicRead:        true,
+	awss3.BucketCannedACLPublicReadWrite:   true,
+	awss3.BucketCannedACLAuthenticatedRead: true,
+}
+
+func owner() *awss3.Owner {
+	return &awss3.Owner{ID: awssdk.String(OwnerID)}
+}
+
+// grants returns the grants S3 reports for a bucket with the given canned ACL
+func grants(acl string) []*awss3.Grant {
+	out := []*awss3.Grant{{
+		Grantee:    &awss3.Grantee{ID: awssdk.String(OwnerID), Type: awssdk.String(awss3.TypeCanonicalUser)},
+		Permission: awssdk.String(awss3.PermissionFullControl),
+	}}
+	group := func(uri, permission string) *awss3.Grant {
+		return &awss3.Grant{
+			Grantee:    &awss3.Grantee{URI: awssdk.String(uri), Type: awssdk.String(awss3.TypeGroup)},
+			Permission: awssdk.String(permission),
+		}
+	}
+	switch acl {
+	case awss3.BucketCannedACLPublicRead:
+		out = append(out, group(allUsersGroupURI, awss3.PermissionRead))
+	case awss3.BucketCannedACLPublicReadWrite:
+		out = append(out, group(allUsersGroupURI, awss3.PermissionRead), group(allUsersGroupURI, awss3.PermissionWrite))
+	case awss3.BucketCannedACLAuthenticatedRead:
+		out = append(out, group(authenticatedUsersGroupURI, awss3.PermissionRead))
+	}
+	return out
+}
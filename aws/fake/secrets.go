@@ -0,0 +1,110 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awssecretsmanager "github.com/aws/aws-sdk-go/service/secretsmanager"
+	awsssm "github.com/aws/aws-sdk-go/service/ssm"
+)
+
+type secretsState struct {
+	// secrets maps secret names to their current value
+	secrets map[string]*awssecretsmanager.GetSecretValueOutput
+	// parameters maps SSM parameter names to the parameters
+	parameters map[string]*awsssm.Parameter
+}
+
+func newSecretsState() secretsState {
+	return secretsState{
+		secrets:    map[string]*awssecretsmanager.GetSecretValueOutput{},
+		parameters: map[string]*awsssm.Parameter{},
+	}
+}
+
+func (b *Backend) serveSecretsManager(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *awssecretsmanager.CreateSecretInput:
+		name := awssdk.StringValue(in.Name)
+		if _, ok := b.secrets.secrets[name]; ok {
+			return nil, awserr.New(awssecretsmanager.ErrCodeResourceExistsException,
+				fmt.Sprintf("the secret %s already exists", name), nil)
+		}
+		secret := &awssecretsmanager.GetSecretValueOutput{
+			ARN:  awssdk.String(arn("secretsmanager", Region, "secret:"+name+"-"+b.nextID("", 6))),
+			Name: awssdk.String(name),
+		}
+		b.secrets.secrets[name] = secret
+		b.putSecretValue(secret, in.SecretString)
+		return &awssecretsmanager.CreateSecretOutput{ARN: secret.ARN, Name: secret.Name, VersionId: secret.VersionId},
+			nil
+	case *awssecretsmanager.PutSecretValueInput:
+		secret, err := b.secret(in.SecretId)
+		if err != nil {
+			return nil, err
+		}
+		b.putSecretValue(secret, in.SecretString)
+		return &awssecretsmanager.PutSecretValueOutput{ARN: secret.ARN, Name: secret.Name,
+			VersionId: secret.VersionId}, nil
+	case *awssecretsmanager.GetSecretValueInput:
+		return b.secret(in.SecretId)
+	}
+	return nil, notImplemented(awssecretsmanager.ServiceName, op)
+}
+
+func (b *Backend) putSecretValue(secret *awssecretsmanager.GetSecretValueOutput, value *string) {
+	secret.SecretString = awssdk.String(awssdk.StringValue(value))
+	secret.VersionId = awssdk.String(b.nextID("", 32))
+	secret.CreatedDate = now()
+}
+
+// secret looks up a secret by its name or its ARN, the same ways Secrets Manager accepts
+func (b *Backend) secret(ref *string) (*awssecretsmanager.GetSecretValueOutput, error) {
+	id := awssdk.StringValue(ref)
+	for name, secret := range b.secrets.secrets {
+		if name == id || awssdk.StringValue(secret.ARN) == id {
+			return secret, nil
+		}
+	}
+	return nil, awserr.New(awssecretsmanager.ErrCodeResourceNotFoundException,
+		"Secrets Manager can't find the specified secret", nil)
+}
+
+func (b *Backend) serveSSM(op string, params interface{}) (interface{}, error) {
+	switch in := params.(type) {
+	case *awsssm.PutParameterInput:
+		name := awssdk.StringValue(in.Name)
+		if strings.Contains(name, "/") && !strings.HasPrefix(name, "/") {
+			return nil, awserr.New("ValidationException",
+				"parameter name must be a fully qualified name, starting with '/'", nil)
+		}
+		param, ok := b.secrets.parameters[name]
+		if ok && !awssdk.BoolValue(in.Overwrite) {
+			return nil, awserr.New(awsssm.ErrCodeParameterAlreadyExists,
+				"the parameter already exists. To overwrite this value, set the overwrite option in the request "+
+					"to true", nil)
+		}
+		if !ok {
+			param = &awsssm.Parameter{
+				ARN:     awssdk.String(arn("ssm", Region, "parameter/"+strings.TrimPrefix(name, "/"))),
+				Name:    awssdk.String(name),
+				Version: awssdk.Int64(0),
+			}
+			b.secrets.parameters[name] = param
+		}
+		param.Type = awssdk.String(awssdk.StringValue(in.Type))
+		param.Value = awssdk.String(awssdk.StringValue(in.Value))
+		param.Version = awssdk.Int64(awssdk.Int64Value(param.Version) + 1)
+		param.LastModifiedDate = now()
+		return &awsssm.PutParameterOutput{Version: param.Version}, nil
+	case *awsssm.GetParameterInput:
+		param, ok := b.secrets.parameters[awssdk.StringValue(in.Name)]
+		if !ok {
+			return nil, awserr.New(awsssm.ErrCodeParameterNotFound, "", nil)
+		}
+		return &awsssm.GetParameterOutput{Parameter: param}, nil
+	}
+	return nil, notImplemented(awsssm.ServiceName, op)
+}
@@ -1,15 +1,39 @@
 package iam
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
 	"strings"
 
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/service/iam"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 const PolicyVersion20121017 PolicyVersion = "2012-10-17"
 
+const (
+	UserTopic             = "usr"
+	RoleTopic             = "role"
+	GroupTopic            = "grp"
+	PolicyTopic           = "pol"
+	PolicyAttachmentTopic = "polatt"
+
+	UserKind             cloudobject.Kind = "user"
+	RoleKind             cloudobject.Kind = "role"
+	GroupKind            cloudobject.Kind = "group"
+	PolicyKind           cloudobject.Kind = "policy"
+	PolicyAttachmentKind cloudobject.Kind = "attachment"
+
+	// AvailableState is the State of every IAM object that exists, as IAM objects have no lifecycle states
+	AvailableState = "available"
+)
+
 type PolicyVersion string
 
 type PolicyDocument struct {
@@ -23,15 +47,143 @@ func FriendlyNamefromARN(arn awsarn.ARN) string {
 }
 
 type StatementEntry struct {
-	Sid       string                         `json:"Sid,omitempty"`
-	Effect    string                         `json:"Effect,omitempty"`
-	Principal map[string]string              `json:"Principal,omitempty"`
-	Action    []string                       `json:"Action,omitempty"`
-	Resource  []string                       `json:"Resource,omitempty"`
-	Condition map[string]map[string][]string `json:"Condition,omitempty"`
+	Sid         string                           `json:"Sid,omitempty"`
+	Effect      string                           `json:"Effect,omitempty"`
+	Principal   Principal                        `json:"Principal,omitempty"`
+	Action      StringList                       `json:"Action,omitempty"`
+	NotAction   StringList                       `json:"NotAction,omitempty"`
+	Resource    StringList                       `json:"Resource,omitempty"`
+	NotResource StringList                       `json:"NotResource,omitempty"`
+	Condition   map[string]map[string]StringList `json:"Condition,omitempty"`
+}
+
+// StringList is a list of strings in a policy document, where IAM also accepts a single string instead
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// Principal maps principal types (e.g. "AWS", "Service") to principals. IAM also accepts "*" for everybody, which
+// is the same as {"AWS": "*"}.
+type Principal map[string]StringList
+
+func (p *Principal) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*p = Principal{"AWS": StringList{single}}
+		return nil
+	}
+	var principals map[string]StringList
+	if err := json.Unmarshal(data, &principals); err != nil {
+		return err
+	}
+	*p = principals
+	return nil
+}
+
+// DecodePolicyDocument decodes a policy document as IAM returns it, which is URL-encoded JSON
+func DecodePolicyDocument(encoded string) (PolicyDocument, error) {
+	var pd PolicyDocument
+	doc, err := url.QueryUnescape(encoded)
+	if err != nil {
+		return pd, err
+	}
+	if err := json.Unmarshal([]byte(doc), &pd); err != nil {
+		return pd, fmt.Errorf("policy document is malformed: %w", err)
+	}
+	return pd, nil
+}
+
+// ParsePolicyDocument parses a policy document as it is written by hand, which is plain JSON. Unlike
+// DecodePolicyDocument, unknown fields are rejected, so typos don't silently widen or narrow a policy.
+func ParsePolicyDocument(data []byte) (PolicyDocument, error) {
+	var pd PolicyDocument
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&pd); err != nil {
+		return pd, fmt.Errorf("policy document is malformed: %w", err)
+	}
+	return pd, nil
+}
+
+// nameFromRef returns the name of an IAM object given by either its name or its ARN. ARNs need to be of the given
+// resource type (e.g. "role").
+func nameFromRef(ref, resourceType string) (string, error) {
+	if len(ref) == 0 {
+		return "", fmt.Errorf("given name is empty")
+	}
+	if !awsarn.IsARN(ref) {
+		return ref, nil
+	}
+	arn, err := awsarn.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if arn.Service != "iam" || !strings.HasPrefix(arn.Resource, resourceType+"/") {
+		return "", fmt.Errorf("given ARN '%s' is not the ARN of an IAM %s", ref, resourceType)
+	}
+	return FriendlyNamefromARN(arn), nil
+}
+
+// nonNil returns the given list, or an empty one if it is nil, so it renders as [] rather than null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
 }
 
 func Client(session client.ConfigProvider) *iam.IAM {
 	// Create a IAM service client.
 	return iam.New(session)
 }
+
+// Available is a cloudobject.ReadyFunc for IAM objects. IAM objects are ready to be used as soon as they exist.
+func Available(status cloudobject.Status) (bool, error) {
+	if status == nil {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported status"}}
+	}
+	return status.State() == AvailableState, nil
+}
+
+// validateName checks that the given name is usable for an IAM object of the given topic, whose full name can be
+// at most max characters long
+func validateName(name, topic string, max int) error {
+	if len(name) == 0 {
+		return fmt.Errorf("given name is empty")
+	}
+	if full := aws.CloudObjectResource(topic, name); len(full) > max {
+		return fmt.Errorf("given name results in '%s', which is longer than %d characters", full, max)
+	}
+	return nil
+}
+
+// isNoSuchEntityError tells whether IAM told us, that the object in question doesn't exist
+func isNoSuchEntityError(err error) bool {
+	return aws.IsNotExistsError(err)
+}
+
+// notExistsError turns IAM's NoSuchEntity errors on reading into cloudobject.NotExistsErrors wrapping them, and
+// leaves all others alone
+func notExistsError(err error, what string, id cloudobject.ID) error {
+	if isNoSuchEntityError(err) {
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("IAM %s with name '%s' not found", what, id.String()),
+			ID:      id,
+			Op:      "read",
+			Err:     err,
+		}}
+	}
+	return err
+}
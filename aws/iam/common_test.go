@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"net/url"
 	"testing"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
@@ -25,3 +26,56 @@ func TestClient(t *testing.T) {
 		SigningRegion: "us-east-1",
 	})
 }
+
+func getReferenceSession(t *testing.T) *session.Session {
+	sess, err := session.NewSession(&awssdk.Config{
+		Region: awssdk.String(ReferenceRegion)},
+	)
+	assert.NoError(t, err)
+	return sess
+}
+
+func TestDecodePolicyDocument(t *testing.T) {
+	// IAM returns documents URL-encoded and allows single strings in place of lists
+	encoded := url.QueryEscape(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*",` +
+		`"Action":"sts:AssumeRole","Condition":{"Bool":{"aws:SecureTransport":"true"}}}]}`)
+	pd, err := DecodePolicyDocument(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyDocument{
+		Version: PolicyVersion20121017,
+		Statement: []StatementEntry{{
+			Effect:    "Allow",
+			Principal: Principal{"AWS": {"*"}},
+			Action:    StringList{"sts:AssumeRole"},
+			Condition: map[string]map[string]StringList{"Bool": {"aws:SecureTransport": {"true"}}},
+		}},
+	}, pd)
+
+	_, err = DecodePolicyDocument("not a document")
+	assert.Error(t, err)
+}
+
+func TestParsePolicyDocument(t *testing.T) {
+	pd, err := ParsePolicyDocument(getMarshaledReferencePolicyDocument())
+	assert.NoError(t, err)
+	assert.Equal(t, getReferencePolicyDocument(), pd)
+
+	// Typos must not go unnoticed
+	_, err = ParsePolicyDocument([]byte(`{"Version":"2012-10-17","Statment":[]}`))
+	assert.Error(t, err)
+}
+
+func TestNameFromRef(t *testing.T) {
+	name, err := nameFromRef("myrole", "role")
+	assert.NoError(t, err)
+	assert.Equal(t, "myrole", name)
+
+	name, err = nameFromRef("arn:aws:iam::123456789012:role/service/myrole", "role")
+	assert.NoError(t, err)
+	assert.Equal(t, "myrole", name)
+
+	_, err = nameFromRef("arn:aws:iam::123456789012:user/myuser", "role")
+	assert.Error(t, err)
+	_, err = nameFromRef("", "role")
+	assert.Error(t, err)
+}
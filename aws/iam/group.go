@@ -1,20 +1,23 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/client"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
-func createGroup(svc iamiface.IAMAPI, gn string) (*awsiam.CreateGroupOutput, error) {
+func createGroup(ctx context.Context, svc iamiface.IAMAPI, gn string) (*awsiam.CreateGroupOutput, error) {
 
-	result, err := svc.CreateGroup(&awsiam.CreateGroupInput{
+	result, err := svc.CreateGroupWithContext(ctx, &awsiam.CreateGroupInput{
 		GroupName: awssdk.String(gn),
 	})
 	if err != nil {
@@ -24,9 +27,9 @@ func createGroup(svc iamiface.IAMAPI, gn string) (*awsiam.CreateGroupOutput, err
 	return result, nil
 }
 
-func updateGroup(svc iamiface.IAMAPI, groupName string, groupArn awsarn.ARN) (*awsiam.UpdateGroupOutput, error) {
+func updateGroup(ctx context.Context, svc iamiface.IAMAPI, groupName string, groupArn awsarn.ARN) (*awsiam.UpdateGroupOutput, error) {
 
-	result, err := svc.UpdateGroup(&awsiam.UpdateGroupInput{
+	result, err := svc.UpdateGroupWithContext(ctx, &awsiam.UpdateGroupInput{
 		GroupName:    awssdk.String(FriendlyNamefromARN(groupArn)),
 		NewGroupName: awssdk.String(groupName),
 	})
@@ -37,9 +40,9 @@ func updateGroup(svc iamiface.IAMAPI, groupName string, groupArn awsarn.ARN) (*a
 	return result, nil
 }
 
-func deleteGroup(svc iamiface.IAMAPI, groupArn awsarn.ARN) (*awsiam.DeleteGroupOutput, error) {
+func deleteGroup(ctx context.Context, svc iamiface.IAMAPI, groupArn awsarn.ARN) (*awsiam.DeleteGroupOutput, error) {
 
-	getGroupOutput, err := svc.GetGroup(&awsiam.GetGroupInput{
+	getGroupOutput, err := svc.GetGroupWithContext(ctx, &awsiam.GetGroupInput{
 		GroupName: awssdk.String(FriendlyNamefromARN(groupArn)),
 	})
 	if err != nil {
@@ -52,61 +55,70 @@ func deleteGroup(svc iamiface.IAMAPI, groupArn awsarn.ARN) (*awsiam.DeleteGroupO
 			return nil, err
 		}
 
-		if err := removeUserFromGroup(svc, userArn, groupArn); err != nil {
+		if err := removeUserFromGroup(ctx, svc, userArn, groupArn); err != nil {
 			return nil, err
 		}
 	}
 
-	res, err := svc.DeleteGroup(&awsiam.DeleteGroupInput{
+	res, err := svc.DeleteGroupWithContext(ctx, &awsiam.DeleteGroupInput{
 		GroupName: awssdk.String(FriendlyNamefromARN(groupArn)),
 	})
-	if err != nil {
-		if err.(awserr.Error).Code() != awsiam.ErrCodeNoSuchEntityException {
-			return nil, err
-		}
+	if err != nil && !isNoSuchEntityError(err) {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func getGroup(svc iamiface.IAMAPI, groupArn awsarn.ARN) (*awsiam.GetGroupOutput, error) {
+func getGroup(ctx context.Context, svc iamiface.IAMAPI, groupName string) (*awsiam.GetGroupOutput, error) {
 
-	result, err := svc.GetGroup(&awsiam.GetGroupInput{
-		GroupName: awssdk.String(FriendlyNamefromARN(groupArn)),
+	result, err := svc.GetGroupWithContext(ctx, &awsiam.GetGroupInput{
+		GroupName: awssdk.String(groupName),
 	})
-
 	if err != nil {
-		fmt.Println("Error", err)
 		return nil, err
 	}
 
 	return result, nil
 }
 
-func addUserToGroup(svc iamiface.IAMAPI, userArn, groupArn awsarn.ARN) error {
-	_, err := svc.AddUserToGroup(&awsiam.AddUserToGroupInput{
-		GroupName: awssdk.String(FriendlyNamefromARN(groupArn)),
-		UserName:  awssdk.String(FriendlyNamefromARN(userArn)),
+func addUserToGroup(ctx context.Context, svc iamiface.IAMAPI, userArn, groupArn awsarn.ARN) error {
+	return addGroupMember(ctx, svc, FriendlyNamefromARN(groupArn), FriendlyNamefromARN(userArn))
+}
+
+func removeUserFromGroup(ctx context.Context, svc iamiface.IAMAPI, userArn, groupArn awsarn.ARN) error {
+	return removeGroupMember(ctx, svc, FriendlyNamefromARN(groupArn), FriendlyNamefromARN(userArn))
+}
+
+// addGroupMember adds the user to the group. Adding a member twice is fine with IAM.
+func addGroupMember(ctx context.Context, svc iamiface.IAMAPI, groupName, userName string) error {
+	_, err := svc.AddUserToGroupWithContext(ctx, &awsiam.AddUserToGroupInput{
+		GroupName: awssdk.String(groupName),
+		UserName:  awssdk.String(userName),
 	})
-	if err != nil && !aws.IsAlreadyExistsError(err) {
+	if err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func removeUserFromGroup(svc iamiface.IAMAPI, userArn, groupArn awsarn.ARN) error {
-	_, err := svc.RemoveUserFromGroup(&awsiam.RemoveUserFromGroupInput{
-		GroupName: awssdk.String(FriendlyNamefromARN(groupArn)),
-		UserName:  awssdk.String(FriendlyNamefromARN(userArn)),
+// removeGroupMember removes the user from the group, if it still is a member
+func removeGroupMember(ctx context.Context, svc iamiface.IAMAPI, groupName, userName string) error {
+	_, err := svc.RemoveUserFromGroupWithContext(ctx, &awsiam.RemoveUserFromGroupInput{
+		GroupName: awssdk.String(groupName),
+		UserName:  awssdk.String(userName),
 	})
-	if err != nil && !aws.IsNotExistsError(err) {
+	if err != nil && !isNoSuchEntityError(err) {
 		return err
 	}
 
 	return nil
 }
 
+// GroupInstance manages an IAM Group through the aws.Instance interface, with a client passed per call.
+//
+// Deprecated: use Group, which implements cloudobject.CloudObject.
 type GroupInstance struct {
 	Name string
 	arn  awsarn.ARN
@@ -125,8 +137,13 @@ func NewExistingGroupInstance(name string, arn awsarn.ARN) *GroupInstance {
 
 // Reconcile creates or updates an AWS Group
 func (g *GroupInstance) Create(svc iamiface.IAMAPI) error {
+	return g.CreateWithContext(context.Background(), svc)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (g *GroupInstance) CreateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	var newarn awsarn.ARN
-	out, err := createGroup(svc, g.Name)
+	out, err := createGroup(ctx, svc, g.Name)
 	if err != nil {
 		return err
 	}
@@ -139,11 +156,16 @@ func (g *GroupInstance) Create(svc iamiface.IAMAPI) error {
 }
 
 func (g *GroupInstance) Update(svc iamiface.IAMAPI) error {
+	return g.UpdateWithContext(context.Background(), svc)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (g *GroupInstance) UpdateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !g.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Group '%s' not yet created", g.Name))
 	}
 
-	_, err := updateGroup(svc, g.Name, g.arn)
+	_, err := updateGroup(ctx, svc, g.Name, g.arn)
 	if err != nil {
 		return err
 	}
@@ -151,11 +173,16 @@ func (g *GroupInstance) Update(svc iamiface.IAMAPI) error {
 }
 
 func (g *GroupInstance) Delete(svc iamiface.IAMAPI) error {
+	return g.DeleteWithContext(context.Background(), svc)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (g *GroupInstance) DeleteWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !g.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Group '%s' not yet created", g.Name))
 	}
 
-	_, err := deleteGroup(svc, g.arn)
+	_, err := deleteGroup(ctx, svc, g.arn)
 	if err != nil {
 		return err
 	}
@@ -171,15 +198,290 @@ func (g *GroupInstance) IsCreated(svc iamiface.IAMAPI) bool {
 }
 
 func (g *GroupInstance) AddUser(svc iamiface.IAMAPI, userArn awsarn.ARN) error {
+	return g.AddUserWithContext(context.Background(), svc, userArn)
+}
+
+// AddUserWithContext is the same as AddUser with the addition of the ability to pass a context
+func (g *GroupInstance) AddUserWithContext(ctx context.Context, svc iamiface.IAMAPI, userArn awsarn.ARN) error {
 	if !g.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Group '%s' not yet created", g.Name))
 	}
-	return addUserToGroup(svc, userArn, g.arn)
+	return addUserToGroup(ctx, svc, userArn, g.arn)
 }
 
 func (g *GroupInstance) RemoveUser(svc iamiface.IAMAPI, userArn awsarn.ARN) error {
+	return g.RemoveUserWithContext(context.Background(), svc, userArn)
+}
+
+// RemoveUserWithContext is the same as RemoveUser with the addition of the ability to pass a context
+func (g *GroupInstance) RemoveUserWithContext(ctx context.Context, svc iamiface.IAMAPI, userArn awsarn.ARN) error {
 	if !g.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Group '%s' not yet created", g.Name))
 	}
-	return removeUserFromGroup(svc, userArn, g.arn)
+	return removeUserFromGroup(ctx, svc, userArn, g.arn)
+}
+
+// Group represents the IAM Group CloudObject
+type Group struct {
+	name string
+	// existing Groups are named as given, without our naming scheme
+	existing bool
+	status   *GroupStatus
+	session  iamiface.IAMAPI
+	// logger is where the Group logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+func NewGroup(name string, session client.ConfigProvider) (*Group, error) {
+	if err := validateName(name, GroupTopic, 128); err != nil {
+		return nil, err
+	}
+
+	group := Group{
+		name:    name,
+		session: Client(session),
+	}
+
+	return &group, nil
+}
+
+// NewExistingGroup returns the Group with the given name or ARN as it is named at AWS, e.g. to inspect or adopt a
+// Group that was created elsewhere
+func NewExistingGroup(ref string, session client.ConfigProvider) (*Group, error) {
+	name, err := nameFromRef(ref, "group")
+	if err != nil {
+		return nil, err
+	}
+
+	group := Group{
+		name:     name,
+		existing: true,
+		session:  Client(session),
+	}
+
+	return &group, nil
+}
+
+func (g *Group) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return g.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (g *Group) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, g.logger, g.ID(), "create")
+	if _, ok := spec.(*GroupSpec); !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	exists, err := g.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	_, err = createGroup(ctx, g.session, g.ID().String())
+	if err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := g.ReadWithContext(aws.WithRetryCodes(ctx, awsiam.ErrCodeNoSuchEntityException)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (g *Group) Read() error {
+	return g.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context. The members of the Group
+// are part of its status.
+func (g *Group) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, g.logger, g.ID(), "read")
+	out, err := getGroup(ctx, g.session, g.ID().String())
+	if err != nil {
+		return notExistsError(err, "Group", g.ID())
+	}
+	status := GroupStatus{Group: *out.Group}
+	for _, user := range out.Users {
+		status.Users = append(status.Users, awssdk.StringValue(user.UserName))
+	}
+	g.status = &status
+
+	return nil
+}
+
+// Update has nothing to change on a Group, as long as it exists
+func (g *Group) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return g.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (g *Group) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, g.logger, g.ID(), "update")
+	if _, ok := spec.(*GroupSpec); !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+	return nil, g.ReadWithContext(ctx)
+}
+
+// Plan compares the given spec against the live IAM Group, without changing anything
+func (g *Group) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return g.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (g *Group) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, g.logger, g.ID(), "plan")
+	assertedSpec, ok := spec.(*GroupSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := g.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(g.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(g.ID(), g.status), nil
+}
+
+// Delete removes all members and managed policies from the Group and deletes it. Purge makes no difference.
+func (g *Group) Delete(purge bool) error {
+	return g.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (g *Group) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, g.logger, g.ID(), "delete")
+	exists, err := g.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	if err := detachAllPolicies(ctx, g.session, GroupAttachmentType, g.ID().String()); err != nil {
+		return err
+	}
+	arn, err := awsarn.Parse(g.status.ProviderID().String())
+	if err != nil {
+		return err
+	}
+	if _, err := deleteGroup(ctx, g.session, arn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *Group) Status() cloudobject.Status {
+	return g.status
+}
+
+func (g *Group) ID() cloudobject.ID {
+	if g.existing {
+		return cloudobject.ID(g.name)
+	}
+	return GroupID(g.name)
+}
+
+// GroupID returns the ID of the Group with the given name, e.g. to reference it from other cloud objects
+func GroupID(name string) cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(GroupTopic, name))
+}
+
+func (g *Group) Exists() (bool, error) {
+	return cloudobject.Exists(g)
+}
+
+func (g *Group) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, g)
+}
+
+// SetLogger makes the Group log its AWS calls to the given logger
+func (g *Group) SetLogger(logger cloudobject.Logger) {
+	g.logger = logger
+}
+
+//////////////
+/// STATUS ///
+//////////////
+
+type GroupStatus struct {
+	awsiam.Group
+
+	// Users holds the names of the Group members
+	Users []string
+}
+
+func (status *GroupStatus) String() string {
+	if status == nil {
+		return ""
+	}
+	return awsutil.Prettify(*status)
+}
+
+func (status *GroupStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return AvailableState
+}
+
+// GroupDetails are the key facts of an IAM Group, as given in its cloudobject.Summary
+type GroupDetails struct {
+	GroupName string   `json:"groupName" yaml:"groupName"`
+	GroupID   string   `json:"groupID" yaml:"groupID"`
+	Path      string   `json:"path" yaml:"path"`
+	Users     []string `json:"users" yaml:"users"`
+}
+
+func (status *GroupStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	return GroupDetails{
+		GroupName: awssdk.StringValue(status.GroupName),
+		GroupID:   awssdk.StringValue(status.GroupId),
+		Path:      awssdk.StringValue(status.Path),
+		Users:     nonNil(status.Users),
+	}
+}
+
+func (status *GroupStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
+	return cloudobject.ProviderID{
+		Type:  cloudobject.AWSProvider,
+		Value: awssdk.StringValue(status.Arn),
+	}
+}
+
+////////////
+/// SPEC ///
+////////////
+
+// GroupSpec is empty, as a Group has nothing to configure besides its name. Members and policies are managed
+// through Users and PolicyAttachments.
+type GroupSpec struct{}
+
+func (spec *GroupSpec) Valid() (bool, error) {
+	return true, nil
+}
+
+// Diff compares the spec against the given live status
+func (spec *GroupSpec) Diff(id cloudobject.ID, status *GroupStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	return cloudobject.Diff{ID: id}
 }
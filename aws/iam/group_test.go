@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -8,10 +9,12 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 ///////////////
@@ -24,7 +27,7 @@ const (
 	ReferenceGroupId           = "AGPA1234567890EXAMPLE"
 )
 
-func (m *mockIAMClient) AddUserToGroup(input *awsiam.AddUserToGroupInput) (*awsiam.AddUserToGroupOutput, error) {
+func (m *mockIAMClient) AddUserToGroupWithContext(_ awssdk.Context, input *awsiam.AddUserToGroupInput, _ ...request.Option) (*awsiam.AddUserToGroupOutput, error) {
 	if *input.UserName == FriendlyNamefromARN(getReferenceUserNonExistingArn()) {
 		return nil, awserr.New(awsiam.ErrCodeNoSuchEntityException, "user not found", fmt.Errorf("user not found"))
 	}
@@ -33,7 +36,7 @@ func (m *mockIAMClient) AddUserToGroup(input *awsiam.AddUserToGroupInput) (*awsi
 	return &awsiam.AddUserToGroupOutput{}, nil
 }
 
-func (m *mockIAMClient) CreateGroup(input *awsiam.CreateGroupInput) (*awsiam.CreateGroupOutput, error) {
+func (m *mockIAMClient) CreateGroupWithContext(_ awssdk.Context, input *awsiam.CreateGroupInput, _ ...request.Option) (*awsiam.CreateGroupOutput, error) {
 	if *input.GroupName == ReferenceExistingGroupName {
 		return nil, fmt.Errorf("Group already exists")
 	}
@@ -47,7 +50,7 @@ func (m *mockIAMClient) CreateGroup(input *awsiam.CreateGroupInput) (*awsiam.Cre
 	return out, nil
 }
 
-func (m *mockIAMClient) UpdateGroup(input *awsiam.UpdateGroupInput) (*awsiam.UpdateGroupOutput, error) {
+func (m *mockIAMClient) UpdateGroupWithContext(_ awssdk.Context, input *awsiam.UpdateGroupInput, _ ...request.Option) (*awsiam.UpdateGroupOutput, error) {
 
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, getReferenceUpdateGroupInput(), input)
@@ -55,14 +58,14 @@ func (m *mockIAMClient) UpdateGroup(input *awsiam.UpdateGroupInput) (*awsiam.Upd
 	return &awsiam.UpdateGroupOutput{}, nil
 }
 
-func (m *mockIAMClient) DeleteGroup(input *awsiam.DeleteGroupInput) (*awsiam.DeleteGroupOutput, error) {
+func (m *mockIAMClient) DeleteGroupWithContext(_ awssdk.Context, input *awsiam.DeleteGroupInput, _ ...request.Option) (*awsiam.DeleteGroupOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, awssdk.String(ReferenceExistingGroupName), input.GroupName)
 
 	return &awsiam.DeleteGroupOutput{}, nil
 }
 
-func (m *mockIAMClient) GetGroup(input *awsiam.GetGroupInput) (*awsiam.GetGroupOutput, error) {
+func (m *mockIAMClient) GetGroupWithContext(_ awssdk.Context, input *awsiam.GetGroupInput, _ ...request.Option) (*awsiam.GetGroupOutput, error) {
 	var ro *awsiam.GetGroupOutput
 	if awssdk.StringValue(input.GroupName) == FriendlyNamefromARN(getReferenceGroupNonExistingArn()) {
 		return ro, awserr.New(awsiam.ErrCodeNoSuchEntityException, "", fmt.Errorf("entity not found"))
@@ -182,10 +185,25 @@ func TestGroupInstance_createGroup(t *testing.T) {
 	// Setup Test
 	mockSvc := &mockIAMClient{t: t}
 
-	_, err := createGroup(mockSvc, "test/name")
+	_, err := createGroup(context.Background(), mockSvc, "test/name")
 	assert.Error(t, err)
 }
 
+func TestGroup_ReadExisting(t *testing.T) {
+	group, err := NewExistingGroup(ReferenceExistingGroupName, getReferenceSession(t))
+	assert.NoError(t, err)
+	assert.Equal(t, cloudobject.ID(ReferenceExistingGroupName), group.ID())
+
+	group.session = &mockIAMClient{t: t}
+	assert.NoError(t, group.Read())
+	assert.Equal(t, ReferenceGroupId, awssdk.StringValue(group.Status().(*GroupStatus).GroupId))
+
+	group, err = NewExistingGroup(FriendlyNamefromARN(getReferenceGroupNonExistingArn()), getReferenceSession(t))
+	assert.NoError(t, err)
+	group.session = &mockIAMClient{t: t}
+	assert.True(t, cloudobject.IsNotExistsError(group.Read()))
+}
+
 /////////////
 // HELPERS //
 /////////////
@@ -1,25 +1,28 @@
 package iam
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
-func createPolicy(svc iamiface.IAMAPI, polName, polDesc string, pd PolicyDocument) (*iam.CreatePolicyOutput, error) {
+func createPolicy(ctx context.Context, svc iamiface.IAMAPI, polName, polDesc string, pd PolicyDocument) (*iam.CreatePolicyOutput, error) {
 	b, err := json.Marshal(&pd)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := svc.CreatePolicy(&iam.CreatePolicyInput{
+	result, err := svc.CreatePolicyWithContext(ctx, &iam.CreatePolicyInput{
 		PolicyDocument: awssdk.String(string(b)),
 		Description:    awssdk.String(polDesc),
 		PolicyName:     awssdk.String(polName),
@@ -31,13 +34,13 @@ func createPolicy(svc iamiface.IAMAPI, polName, polDesc string, pd PolicyDocumen
 	return result, nil
 }
 
-func updatePolicy(svc iamiface.IAMAPI, policyArn awsarn.ARN, pd PolicyDocument) (*iam.CreatePolicyVersionOutput, error) {
+func updatePolicy(ctx context.Context, svc iamiface.IAMAPI, policyArn awsarn.ARN, pd PolicyDocument) (*iam.CreatePolicyVersionOutput, error) {
 	b, err := json.Marshal(&pd)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := svc.CreatePolicyVersion(&iam.CreatePolicyVersionInput{
+	result, err := svc.CreatePolicyVersionWithContext(ctx, &iam.CreatePolicyVersionInput{
 		PolicyDocument: awssdk.String(string(b)),
 		PolicyArn:      awssdk.String(policyArn.String()),
 		SetAsDefault:   awssdk.Bool(true),
@@ -49,10 +52,10 @@ func updatePolicy(svc iamiface.IAMAPI, policyArn awsarn.ARN, pd PolicyDocument)
 	return result, nil
 }
 
-func deletePolicy(svc iamiface.IAMAPI, arn awsarn.ARN) (*iam.DeletePolicyOutput, error) {
+func deletePolicy(ctx context.Context, svc iamiface.IAMAPI, arn awsarn.ARN) (*iam.DeletePolicyOutput, error) {
 
 	// To delete a policy, we need to delete all policy versions
-	listPolicyOut, err := svc.ListPolicyVersions(&iam.ListPolicyVersionsInput{
+	listPolicyOut, err := svc.ListPolicyVersionsWithContext(ctx, &iam.ListPolicyVersionsInput{
 		PolicyArn: awssdk.String(arn.String()),
 	})
 	if err != nil {
@@ -61,7 +64,7 @@ func deletePolicy(svc iamiface.IAMAPI, arn awsarn.ARN) (*iam.DeletePolicyOutput,
 
 	for _, version := range listPolicyOut.Versions {
 		if !awssdk.BoolValue(version.IsDefaultVersion) {
-			_, err := svc.DeletePolicyVersion(&iam.DeletePolicyVersionInput{
+			_, err := svc.DeletePolicyVersionWithContext(ctx, &iam.DeletePolicyVersionInput{
 				PolicyArn: awssdk.String(arn.String()),
 				VersionId: version.VersionId,
 			})
@@ -72,48 +75,45 @@ func deletePolicy(svc iamiface.IAMAPI, arn awsarn.ARN) (*iam.DeletePolicyOutput,
 	}
 
 	// Now we can delete the actual policy
-	res, err := svc.DeletePolicy(&iam.DeletePolicyInput{
+	res, err := svc.DeletePolicyWithContext(ctx, &iam.DeletePolicyInput{
 		PolicyArn: awssdk.String(arn.String()),
 	})
-	if err != nil {
-		if err.(awserr.Error).Code() != iam.ErrCodeNoSuchEntityException {
-			return nil, err
-		}
+	if err != nil && !isNoSuchEntityError(err) {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func getPolicy(svc iamiface.IAMAPI, arn awsarn.ARN) (*iam.GetPolicyOutput, error) {
+func getPolicy(ctx context.Context, svc iamiface.IAMAPI, arn string) (*iam.GetPolicyOutput, error) {
 
-	result, err := svc.GetPolicy(&iam.GetPolicyInput{
-		PolicyArn: awssdk.String(arn.String()),
+	result, err := svc.GetPolicyWithContext(ctx, &iam.GetPolicyInput{
+		PolicyArn: awssdk.String(arn),
 	})
-
 	if err != nil {
-		fmt.Println("Error", err)
 		return nil, err
 	}
 
 	return result, nil
 }
 
-func getPolicyVersion(svc iamiface.IAMAPI, po iam.GetPolicyOutput) (*iam.GetPolicyVersionOutput, error) {
+// getPolicyVersion returns the default version of the given policy, which is the one in effect
+func getPolicyVersion(ctx context.Context, svc iamiface.IAMAPI, policy *iam.Policy) (*iam.GetPolicyVersionOutput, error) {
 
-	result, err := svc.GetPolicyVersion(&iam.GetPolicyVersionInput{
-		PolicyArn: po.Policy.Arn,
-		VersionId: po.Policy.DefaultVersionId,
+	result, err := svc.GetPolicyVersionWithContext(ctx, &iam.GetPolicyVersionInput{
+		PolicyArn: policy.Arn,
+		VersionId: policy.DefaultVersionId,
 	})
-
 	if err != nil {
-		fmt.Println("Error", err)
 		return nil, err
 	}
 
 	return result, nil
-
 }
 
+// PolicyInstance manages an IAM Policy through the aws.Instance interface, with a client passed per call.
+//
+// Deprecated: use Policy, which implements cloudobject.CloudObject.
 type PolicyInstance struct {
 	Name           string
 	Description    string
@@ -134,37 +134,15 @@ func NewExistingPolicyInstance(name, description string, policyDoc PolicyDocumen
 	}
 }
 
-// Abandoned fetch implementation
-//func NewExistingPolicyInstance(svc iamiface.IAMAPI, arn awsarn.ARN) (*PolicyInstance, error) {
-//	var pi *PolicyInstance
-//	emptyarn := awsarn.ARN{}.String()
-//	if arn.String() == emptyarn {
-//		return pi, fmt.Errorf("given ARN is empty")
-//	}
-//
-//	out, err := getPolicy(svc, arn)
-//	if err != nil {
-//		return pi, err
-//	}
-//
-//	pdout, err := getPolicyVersion(svc, *out)
-//	if err != nil {
-//		return pi, err
-//	}
-//	var pd PolicyDocument
-//	json.Unmarshal([]byte(awssdk.StringValue(pdout.PolicyVersion.Document)), &pd)
-//	pi = &PolicyInstance{
-//		Name:           awssdk.StringValue(out.Policy.PolicyName),
-//		Description:    awssdk.StringValue(out.Policy.Description),
-//		PolicyDocument: pd,
-//		arn:            arn,
-//	}
-//}
-
 // Create attaches the referenced policy on referenced target type and returns the target ARN
 func (p *PolicyInstance) Create(svc iamiface.IAMAPI) error {
+	return p.CreateWithContext(context.Background(), svc)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (p *PolicyInstance) CreateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	var newarn awsarn.ARN
-	out, err := createPolicy(svc, p.Name, p.Description, p.PolicyDocument)
+	out, err := createPolicy(ctx, svc, p.Name, p.Description, p.PolicyDocument)
 	if err != nil {
 		return err
 	}
@@ -178,11 +156,16 @@ func (p *PolicyInstance) Create(svc iamiface.IAMAPI) error {
 
 // Update for PolicyInstance creates a new Policy version an sets it as active; then returns the arn
 func (p *PolicyInstance) Update(svc iamiface.IAMAPI) error {
+	return p.UpdateWithContext(context.Background(), svc)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (p *PolicyInstance) UpdateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !p.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
 	}
 
-	_, err := updatePolicy(svc, p.arn, p.PolicyDocument)
+	_, err := updatePolicy(ctx, svc, p.arn, p.PolicyDocument)
 	if err != nil {
 		return err
 	}
@@ -191,11 +174,16 @@ func (p *PolicyInstance) Update(svc iamiface.IAMAPI) error {
 
 // Delete removes the referenced Policy from referenced target type
 func (p *PolicyInstance) Delete(svc iamiface.IAMAPI) error {
+	return p.DeleteWithContext(context.Background(), svc)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (p *PolicyInstance) DeleteWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !p.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Policy '%s' not yet created", p.Name))
 	}
 
-	_, err := deletePolicy(svc, p.arn)
+	_, err := deletePolicy(ctx, svc, p.arn)
 	if err != nil {
 		return err
 	}
@@ -209,3 +197,417 @@ func (p *PolicyInstance) ARN() awsarn.ARN {
 func (p *PolicyInstance) IsCreated(svc iamiface.IAMAPI) bool {
 	return p.arn.String() != awsarn.ARN{}.String()
 }
+
+// Policy represents the IAM customer managed Policy CloudObject
+type Policy struct {
+	name string
+	// existing Policies are named as given, without our naming scheme
+	existing bool
+	// arn is set for existing Policies given by ARN, which also covers AWS managed ones
+	arn     string
+	status  *PolicyStatus
+	session iamiface.IAMAPI
+	// logger is where the Policy logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+func NewPolicy(name string, session client.ConfigProvider) (*Policy, error) {
+	if err := validateName(name, PolicyTopic, 128); err != nil {
+		return nil, err
+	}
+
+	policy := Policy{
+		name:    name,
+		session: Client(session),
+	}
+
+	return &policy, nil
+}
+
+// NewExistingPolicy returns the Policy with the given name or ARN as it is named at AWS, e.g. to inspect or adopt a
+// Policy that was created elsewhere. Only customer managed Policies can be found by name; AWS managed ones need to
+// be given by ARN.
+func NewExistingPolicy(ref string, session client.ConfigProvider) (*Policy, error) {
+	name, err := nameFromRef(ref, "policy")
+	if err != nil {
+		return nil, err
+	}
+
+	policy := Policy{
+		name:     name,
+		existing: true,
+		session:  Client(session),
+	}
+	if awsarn.IsARN(ref) {
+		policy.arn = ref
+	}
+
+	return &policy, nil
+}
+
+func (p *Policy) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return p.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (p *Policy) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, p.logger, p.ID(), "create")
+	assertedSpec, ok := spec.(*PolicySpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	exists, err := p.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	_, err = createPolicy(ctx, p.session, p.ID().String(), assertedSpec.Description, assertedSpec.PolicyDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := p.ReadWithContext(aws.WithRetryCodes(ctx, iam.ErrCodeNoSuchEntityException)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (p *Policy) Read() error {
+	return p.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context. The document of the
+// default version is decoded into the status.
+func (p *Policy) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, p.logger, p.ID(), "read")
+	policy, err := p.find(ctx)
+	if err != nil {
+		return err
+	}
+	version, err := getPolicyVersion(ctx, p.session, policy)
+	if err != nil {
+		return err
+	}
+	pd, err := DecodePolicyDocument(awssdk.StringValue(version.PolicyVersion.Document))
+	if err != nil {
+		return err
+	}
+	p.status = &PolicyStatus{Policy: *policy, PolicyDocument: pd}
+
+	return nil
+}
+
+// find fetches the Policy by ARN if we know it. Otherwise we look it up by name among all customer managed
+// policies, as policies can only be fetched by ARN.
+func (p *Policy) find(ctx context.Context) (*iam.Policy, error) {
+	if p.arn != "" {
+		out, err := getPolicy(ctx, p.session, p.arn)
+		if err != nil {
+			return nil, notExistsError(err, "Policy", p.ID())
+		}
+		return out.Policy, nil
+	}
+
+	var found *iam.Policy
+	err := p.session.ListPoliciesPagesWithContext(ctx, &iam.ListPoliciesInput{
+		Scope: awssdk.String(iam.PolicyScopeTypeLocal),
+	}, func(out *iam.ListPoliciesOutput, _ bool) bool {
+		for _, policy := range out.Policies {
+			if awssdk.StringValue(policy.PolicyName) == p.ID().String() {
+				found = policy
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("IAM Policy with name '%s' not found", p.ID().String()),
+			ID:      p.ID(),
+			Op:      "read",
+		}}
+	}
+	return found, nil
+}
+
+func (p *Policy) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return p.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context. A changed policy
+// document is applied as a new default version of the Policy.
+func (p *Policy) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, p.logger, p.ID(), "update")
+	assertedSpec, ok := spec.(*PolicySpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	// Let's update our status
+	if err := p.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+	diff := assertedSpec.Diff(p.ID(), p.status)
+	if err := diff.ImmutableChangesError(); err != nil {
+		return nil, err
+	}
+	if !diff.Changed("PolicyDocument") {
+		return nil, nil
+	}
+
+	arn, err := awsarn.Parse(p.status.ProviderID().String())
+	if err != nil {
+		return nil, err
+	}
+	if err := prunePolicyVersions(ctx, p.session, arn); err != nil {
+		return nil, err
+	}
+	if _, err := updatePolicy(ctx, p.session, arn, assertedSpec.PolicyDocument); err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update
+	if err := p.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Plan compares the given spec against the live IAM Policy, without changing anything
+func (p *Policy) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return p.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (p *Policy) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, p.logger, p.ID(), "plan")
+	assertedSpec, ok := spec.(*PolicySpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := p.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(p.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(p.ID(), p.status), nil
+}
+
+// Delete detaches the Policy from everything it's attached to and deletes it with all its versions. Purge makes no
+// difference.
+func (p *Policy) Delete(purge bool) error {
+	return p.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (p *Policy) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, p.logger, p.ID(), "delete")
+	exists, err := p.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	out, err := p.session.ListEntitiesForPolicyWithContext(ctx, &iam.ListEntitiesForPolicyInput{
+		PolicyArn: p.status.Arn,
+	})
+	if err != nil {
+		return err
+	}
+	// A Policy can only be deleted, once it's not attached anymore
+	policyArn := awssdk.StringValue(p.status.Arn)
+	for _, role := range out.PolicyRoles {
+		err := detachPolicy(ctx, p.session, RoleAttachmentType, policyArn, awssdk.StringValue(role.RoleName))
+		if err != nil {
+			return err
+		}
+	}
+	for _, user := range out.PolicyUsers {
+		err := detachPolicy(ctx, p.session, UserAttachmentType, policyArn, awssdk.StringValue(user.UserName))
+		if err != nil {
+			return err
+		}
+	}
+	for _, group := range out.PolicyGroups {
+		err := detachPolicy(ctx, p.session, GroupAttachmentType, policyArn, awssdk.StringValue(group.GroupName))
+		if err != nil {
+			return err
+		}
+	}
+
+	arn, err := awsarn.Parse(p.status.ProviderID().String())
+	if err != nil {
+		return err
+	}
+	if _, err := deletePolicy(ctx, p.session, arn); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *Policy) Status() cloudobject.Status {
+	return p.status
+}
+
+func (p *Policy) ID() cloudobject.ID {
+	if p.existing {
+		return cloudobject.ID(p.name)
+	}
+	return PolicyID(p.name)
+}
+
+// PolicyID returns the ID of the Policy with the given name, e.g. to reference it from other cloud objects
+func PolicyID(name string) cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(PolicyTopic, name))
+}
+
+func (p *Policy) Exists() (bool, error) {
+	return cloudobject.Exists(p)
+}
+
+func (p *Policy) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, p)
+}
+
+// SetLogger makes the Policy log its AWS calls to the given logger
+func (p *Policy) SetLogger(logger cloudobject.Logger) {
+	p.logger = logger
+}
+
+// maxPolicyVersions is how many versions IAM keeps of a managed policy
+const maxPolicyVersions = 5
+
+// prunePolicyVersions deletes the oldest non-default version of the policy, if there's no room for another one
+func prunePolicyVersions(ctx context.Context, svc iamiface.IAMAPI, arn awsarn.ARN) error {
+	out, err := svc.ListPolicyVersionsWithContext(ctx, &iam.ListPolicyVersionsInput{
+		PolicyArn: awssdk.String(arn.String()),
+	})
+	if err != nil {
+		return err
+	}
+	if len(out.Versions) < maxPolicyVersions {
+		return nil
+	}
+
+	var oldest *iam.PolicyVersion
+	for _, version := range out.Versions {
+		if awssdk.BoolValue(version.IsDefaultVersion) {
+			continue
+		}
+		if oldest == nil || awssdk.TimeValue(version.CreateDate).Before(awssdk.TimeValue(oldest.CreateDate)) {
+			oldest = version
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	_, err = svc.DeletePolicyVersionWithContext(ctx, &iam.DeletePolicyVersionInput{
+		PolicyArn: awssdk.String(arn.String()),
+		VersionId: oldest.VersionId,
+	})
+	return err
+}
+
+//////////////
+/// STATUS ///
+//////////////
+
+type PolicyStatus struct {
+	iam.Policy
+
+	// PolicyDocument is the decoded document of the default version of the Policy
+	PolicyDocument PolicyDocument
+}
+
+func (status *PolicyStatus) String() string {
+	if status == nil {
+		return ""
+	}
+	return awsutil.Prettify(*status)
+}
+
+func (status *PolicyStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return AvailableState
+}
+
+// PolicyDetails are the key facts of an IAM Policy, as given in its cloudobject.Summary
+type PolicyDetails struct {
+	PolicyName       string `json:"policyName" yaml:"policyName"`
+	PolicyID         string `json:"policyID" yaml:"policyID"`
+	Path             string `json:"path" yaml:"path"`
+	Description      string `json:"description,omitempty" yaml:"description,omitempty"`
+	DefaultVersionID string `json:"defaultVersionID" yaml:"defaultVersionID"`
+	AttachmentCount  int64  `json:"attachmentCount" yaml:"attachmentCount"`
+}
+
+func (status *PolicyStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	return PolicyDetails{
+		PolicyName:       awssdk.StringValue(status.PolicyName),
+		PolicyID:         awssdk.StringValue(status.PolicyId),
+		Path:             awssdk.StringValue(status.Path),
+		Description:      awssdk.StringValue(status.Description),
+		DefaultVersionID: awssdk.StringValue(status.DefaultVersionId),
+		AttachmentCount:  awssdk.Int64Value(status.AttachmentCount),
+	}
+}
+
+func (status *PolicyStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
+	return cloudobject.ProviderID{
+		Type:  cloudobject.AWSProvider,
+		Value: awssdk.StringValue(status.Arn),
+	}
+}
+
+////////////
+/// SPEC ///
+////////////
+
+type PolicySpec struct {
+	Description    string
+	PolicyDocument PolicyDocument
+}
+
+func (spec *PolicySpec) Valid() (bool, error) {
+	if len(spec.PolicyDocument.Statement) == 0 {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "policy document has no statements",
+		}}
+	}
+	return true, nil
+}
+
+// Diff compares the spec against the given live status. The description of a Policy can't be changed.
+func (spec *PolicySpec) Diff(id cloudobject.ID, status *PolicyStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+	diff.CompareImmutable("Description", awssdk.StringValue(status.Description), spec.Description)
+	diff.Compare("PolicyDocument", status.PolicyDocument, spec.PolicyDocument)
+	return diff
+}
@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -11,11 +12,13 @@ import (
 	"github.com/aws/aws-sdk-go/aws/arn"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 ///////////////
@@ -35,7 +38,7 @@ const (
 	ReferencePolicyId          = "ANPA1234567890EXAMPLE"
 )
 
-func (m *mockIAMClient) CreatePolicy(input *awsiam.CreatePolicyInput) (*awsiam.CreatePolicyOutput, error) {
+func (m *mockIAMClient) CreatePolicyWithContext(_ awssdk.Context, input *awsiam.CreatePolicyInput, _ ...request.Option) (*awsiam.CreatePolicyOutput, error) {
 	if strings.Contains(*input.PolicyName, "/") {
 		return nil, fmt.Errorf("malformed Policy Name")
 	}
@@ -44,28 +47,28 @@ func (m *mockIAMClient) CreatePolicy(input *awsiam.CreatePolicyInput) (*awsiam.C
 	return createMockCreatePolicyOutput(input), nil
 }
 
-func (m *mockIAMClient) CreatePolicyVersion(input *awsiam.CreatePolicyVersionInput) (*awsiam.CreatePolicyVersionOutput, error) {
+func (m *mockIAMClient) CreatePolicyVersionWithContext(_ awssdk.Context, input *awsiam.CreatePolicyVersionInput, _ ...request.Option) (*awsiam.CreatePolicyVersionOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, getReferenceCreatePolicyVersionInput(), input)
 	out := createMockCreatePolicyVersionOutput(input)
 	return out, nil
 }
 
-func (m *mockIAMClient) ListPolicyVersions(input *awsiam.ListPolicyVersionsInput) (*awsiam.ListPolicyVersionsOutput, error) {
+func (m *mockIAMClient) ListPolicyVersionsWithContext(_ awssdk.Context, input *awsiam.ListPolicyVersionsInput, _ ...request.Option) (*awsiam.ListPolicyVersionsOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, awssdk.String(getReferencePolicyExistingArn().String()), input.PolicyArn)
 	out := createMockListPolicyVersionsOutput()
 	return out, nil
 }
 
-func (m *mockIAMClient) DeletePolicyVersion(input *awsiam.DeletePolicyVersionInput) (*awsiam.DeletePolicyVersionOutput, error) {
+func (m *mockIAMClient) DeletePolicyVersionWithContext(_ awssdk.Context, input *awsiam.DeletePolicyVersionInput, _ ...request.Option) (*awsiam.DeletePolicyVersionOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, awssdk.String(getReferencePolicyExistingArn().String()), input.PolicyArn)
 	out := createMockDeletePolicyVersionsOutput()
 	return out, nil
 }
 
-func (m *mockIAMClient) DeletePolicy(input *awsiam.DeletePolicyInput) (*awsiam.DeletePolicyOutput, error) {
+func (m *mockIAMClient) DeletePolicyWithContext(_ awssdk.Context, input *awsiam.DeletePolicyInput, _ ...request.Option) (*awsiam.DeletePolicyOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.True(m.t, arn.IsARN(*input.PolicyArn))
 	assert.Equal(m.t, awssdk.String(getReferencePolicyExistingArn().String()), input.PolicyArn)
@@ -73,7 +76,7 @@ func (m *mockIAMClient) DeletePolicy(input *awsiam.DeletePolicyInput) (*awsiam.D
 	return &awsiam.DeletePolicyOutput{}, nil
 }
 
-func (m *mockIAMClient) GetPolicy(input *awsiam.GetPolicyInput) (*awsiam.GetPolicyOutput, error) {
+func (m *mockIAMClient) GetPolicyWithContext(_ awssdk.Context, input *awsiam.GetPolicyInput, _ ...request.Option) (*awsiam.GetPolicyOutput, error) {
 	var po *awsiam.GetPolicyOutput
 	if awssdk.StringValue(input.PolicyArn) == getReferencePolicyNonExistingArn().String() {
 		return po, awserr.New(awsiam.ErrCodeNoSuchEntityException, "", fmt.Errorf("entity not found"))
@@ -83,7 +86,7 @@ func (m *mockIAMClient) GetPolicy(input *awsiam.GetPolicyInput) (*awsiam.GetPoli
 	return createMockGetPolicyOutput(input), nil
 }
 
-func (m *mockIAMClient) GetPolicyVersion(input *awsiam.GetPolicyVersionInput) (*awsiam.GetPolicyVersionOutput, error) {
+func (m *mockIAMClient) GetPolicyVersionWithContext(_ awssdk.Context, input *awsiam.GetPolicyVersionInput, _ ...request.Option) (*awsiam.GetPolicyVersionOutput, error) {
 	var pvo *awsiam.GetPolicyVersionOutput
 	if awssdk.StringValue(input.PolicyArn) == getReferencePolicyNonExistingArn().String() {
 		return pvo, awserr.New(awsiam.ErrCodeNoSuchEntityException, "", fmt.Errorf("entity not found"))
@@ -228,10 +231,48 @@ func TestPolicyInstance_createPolicy(t *testing.T) {
 	// Setup Test
 	mockSvc := &mockIAMClient{t: t}
 
-	_, err := createPolicy(mockSvc, "test/name", "test", getReferencePolicyDocument())
+	_, err := createPolicy(context.Background(), mockSvc, "test/name", "test", getReferencePolicyDocument())
 	assert.Error(t, err)
 }
 
+func TestPolicySpec_Diff(t *testing.T) {
+	id := PolicyID(ReferencePolicyName)
+	spec := &PolicySpec{Description: ReferencePolicyDescription, PolicyDocument: getReferencePolicyDocument()}
+	assert.True(t, spec.Diff(id, nil).Create)
+
+	status := &PolicyStatus{
+		Policy:         awsiam.Policy{Description: awssdk.String(ReferencePolicyDescription)},
+		PolicyDocument: getReferencePolicyDocument(),
+	}
+	assert.False(t, spec.Diff(id, status).HasChanges())
+
+	// A changed document becomes a new policy version
+	status.PolicyDocument = PolicyDocument{Version: PolicyVersion20121017}
+	assert.Equal(t, cloudobject.UpdateDiffAction, spec.Diff(id, status).Action())
+	status.PolicyDocument = getReferencePolicyDocument()
+
+	// The description of a Policy can't be changed
+	spec.Description = "other"
+	assert.Equal(t, cloudobject.ReplaceDiffAction, spec.Diff(id, status).Action())
+}
+
+func TestPolicy_ReadExisting(t *testing.T) {
+	policy, err := NewExistingPolicy(getReferencePolicyExistingArn().String(), getReferenceSession(t))
+	assert.NoError(t, err)
+	assert.Equal(t, cloudobject.ID(ReferencePolicyName), policy.ID())
+
+	policy.session = &mockIAMClient{t: t}
+	assert.NoError(t, policy.Read())
+	status := policy.Status().(*PolicyStatus)
+	assert.Equal(t, ReferenceV2VersionId, awssdk.StringValue(status.DefaultVersionId))
+	assert.Equal(t, getReferencePolicyDocument(), status.PolicyDocument)
+
+	policy, err = NewExistingPolicy(getReferencePolicyNonExistingArn().String(), getReferenceSession(t))
+	assert.NoError(t, err)
+	policy.session = &mockIAMClient{t: t}
+	assert.True(t, cloudobject.IsNotExistsError(policy.Read()))
+}
+
 /////////////
 // HELPERS //
 /////////////
@@ -1,120 +1,142 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
-func createPolicyAttachment(svc iamiface.IAMAPI, attachType AttachmentType, policyArn, targetArn awsarn.ARN) error {
+func createPolicyAttachment(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, policyArn, targetArn awsarn.ARN) error {
+	return attachPolicy(ctx, svc, attachType, policyArn.String(), FriendlyNamefromARN(targetArn))
+}
+
+func deletePolicyAttachment(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, policyArn, targetArn awsarn.ARN) error {
+	return detachPolicy(ctx, svc, attachType, policyArn.String(), FriendlyNamefromARN(targetArn))
+}
+
+func getPolicyAttachment(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, policyArn, targetArn awsarn.ARN) (*iam.AttachedPolicy, error) {
+	aps, err := listAttachedPolicies(ctx, svc, attachType, FriendlyNamefromARN(targetArn))
+	if err != nil && !isNoSuchEntityError(err) {
+		return nil, err
+	}
+
+	for _, policy := range aps {
+		if *policy.PolicyName == FriendlyNamefromARN(policyArn) {
+			return policy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("policy not attached to specified target")
+}
+
+// attachPolicy attaches the policy with the given ARN to the role, user or group with the given name
+func attachPolicy(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, policyArn, target string) error {
 	var err error
 	switch attachType {
 	case RoleAttachmentType:
-		_, err = svc.AttachRolePolicy(&iam.AttachRolePolicyInput{
-			PolicyArn: awssdk.String(policyArn.String()),
-			RoleName:  awssdk.String(FriendlyNamefromARN(targetArn)),
+		_, err = svc.AttachRolePolicyWithContext(ctx, &iam.AttachRolePolicyInput{
+			PolicyArn: awssdk.String(policyArn),
+			RoleName:  awssdk.String(target),
 		})
 	case UserAttachmentType:
-		_, err = svc.AttachUserPolicy(&iam.AttachUserPolicyInput{
-			PolicyArn: awssdk.String(policyArn.String()),
-			UserName:  awssdk.String(FriendlyNamefromARN(targetArn)),
+		_, err = svc.AttachUserPolicyWithContext(ctx, &iam.AttachUserPolicyInput{
+			PolicyArn: awssdk.String(policyArn),
+			UserName:  awssdk.String(target),
 		})
 	case GroupAttachmentType:
-		_, err = svc.AttachGroupPolicy(&iam.AttachGroupPolicyInput{
-			PolicyArn: awssdk.String(policyArn.String()),
-			GroupName: awssdk.String(FriendlyNamefromARN(targetArn)),
+		_, err = svc.AttachGroupPolicyWithContext(ctx, &iam.AttachGroupPolicyInput{
+			PolicyArn: awssdk.String(policyArn),
+			GroupName: awssdk.String(target),
 		})
 	default:
 		return aws.NewInstanceError(ErrAttachmentTypeUnknown, fmt.Sprintf("unknown attachment type '%s", attachType))
 	}
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return err
 }
 
-func deletePolicyAttachment(svc iamiface.IAMAPI, attachType AttachmentType, policyArn, targetArn awsarn.ARN) error {
+// detachPolicy detaches the policy with the given ARN from the role, user or group with the given name. Policies
+// that aren't attached are fine.
+func detachPolicy(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, policyArn, target string) error {
 	var err error
-
 	switch attachType {
 	case RoleAttachmentType:
-		_, err = svc.DetachRolePolicy(&iam.DetachRolePolicyInput{
-			PolicyArn: awssdk.String(policyArn.String()),
-			RoleName:  awssdk.String(FriendlyNamefromARN(targetArn)),
+		_, err = svc.DetachRolePolicyWithContext(ctx, &iam.DetachRolePolicyInput{
+			PolicyArn: awssdk.String(policyArn),
+			RoleName:  awssdk.String(target),
 		})
 	case UserAttachmentType:
-		_, err = svc.DetachUserPolicy(&iam.DetachUserPolicyInput{
-			PolicyArn: awssdk.String(policyArn.String()),
-			UserName:  awssdk.String(FriendlyNamefromARN(targetArn)),
+		_, err = svc.DetachUserPolicyWithContext(ctx, &iam.DetachUserPolicyInput{
+			PolicyArn: awssdk.String(policyArn),
+			UserName:  awssdk.String(target),
 		})
 	case GroupAttachmentType:
-		_, err = svc.DetachGroupPolicy(&iam.DetachGroupPolicyInput{
-			PolicyArn: awssdk.String(policyArn.String()),
-			GroupName: awssdk.String(FriendlyNamefromARN(targetArn)),
+		_, err = svc.DetachGroupPolicyWithContext(ctx, &iam.DetachGroupPolicyInput{
+			PolicyArn: awssdk.String(policyArn),
+			GroupName: awssdk.String(target),
 		})
 	default:
 		return aws.NewInstanceError(ErrAttachmentTypeUnknown, fmt.Sprintf("unknown attachment type '%s", attachType))
 	}
-	if err != nil {
-		if err.(awserr.Error).Code() != iam.ErrCodeNoSuchEntityException {
-			return err
-		}
+	if err != nil && !isNoSuchEntityError(err) {
+		return err
 	}
-
 	return nil
 }
-func getPolicyAttachment(svc iamiface.IAMAPI, attachType AttachmentType, policyArn, targetArn awsarn.ARN) (*iam.AttachedPolicy, error) {
-	var aps []*iam.AttachedPolicy
 
+// listAttachedPolicies returns the managed policies attached to the role, user or group with the given name
+func listAttachedPolicies(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, target string) ([]*iam.AttachedPolicy, error) {
 	switch attachType {
 	case RoleAttachmentType:
-		out, err := svc.ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{
-			RoleName: awssdk.String(FriendlyNamefromARN(targetArn)),
+		out, err := svc.ListAttachedRolePoliciesWithContext(ctx, &iam.ListAttachedRolePoliciesInput{
+			RoleName: awssdk.String(target),
 		})
 		if err != nil {
-			if err.(awserr.Error).Code() != iam.ErrCodeNoSuchEntityException {
-				return nil, err
-			}
+			return nil, err
 		}
-		aps = out.AttachedPolicies
+		return out.AttachedPolicies, nil
 	case UserAttachmentType:
-		out, err := svc.ListAttachedUserPolicies(&iam.ListAttachedUserPoliciesInput{
-			UserName: awssdk.String(FriendlyNamefromARN(targetArn)),
+		out, err := svc.ListAttachedUserPoliciesWithContext(ctx, &iam.ListAttachedUserPoliciesInput{
+			UserName: awssdk.String(target),
 		})
 		if err != nil {
-			if err.(awserr.Error).Code() != iam.ErrCodeNoSuchEntityException {
-				return nil, err
-			}
+			return nil, err
 		}
-		aps = out.AttachedPolicies
+		return out.AttachedPolicies, nil
 	case GroupAttachmentType:
-		out, err := svc.ListAttachedGroupPolicies(&iam.ListAttachedGroupPoliciesInput{
-			GroupName: awssdk.String(FriendlyNamefromARN(targetArn)),
+		out, err := svc.ListAttachedGroupPoliciesWithContext(ctx, &iam.ListAttachedGroupPoliciesInput{
+			GroupName: awssdk.String(target),
 		})
 		if err != nil {
-			if err.(awserr.Error).Code() != iam.ErrCodeNoSuchEntityException {
-				return nil, err
-			}
+			return nil, err
 		}
-		aps = out.AttachedPolicies
+		return out.AttachedPolicies, nil
 	default:
 		return nil, aws.NewInstanceError(ErrAttachmentTypeUnknown, fmt.Sprintf("unknown attachment type '%s", attachType))
 	}
+}
 
+// detachAllPolicies detaches all managed policies from the role, user or group with the given name, which IAM
+// requires before deleting it
+func detachAllPolicies(ctx context.Context, svc iamiface.IAMAPI, attachType AttachmentType, target string) error {
+	aps, err := listAttachedPolicies(ctx, svc, attachType, target)
+	if err != nil {
+		return err
+	}
 	for _, policy := range aps {
-		if *policy.PolicyName == FriendlyNamefromARN(policyArn) {
-			return policy, nil
+		if err := detachPolicy(ctx, svc, attachType, awssdk.StringValue(policy.PolicyArn), target); err != nil {
+			return err
 		}
 	}
-
-	return nil, fmt.Errorf("policy not attached to specified target")
+	return nil
 }
 
 const (
@@ -126,6 +148,10 @@ const (
 
 type AttachmentType string
 
+// PolicyAttachmentInstance manages an IAM policy attachment through the aws.Instance interface, with a client
+// passed per call.
+//
+// Deprecated: use PolicyAttachment, which implements cloudobject.CloudObject.
 type PolicyAttachmentInstance struct {
 	PolicyRef awsarn.ARN
 	Type      AttachmentType
@@ -138,7 +164,12 @@ func NewPolicyAttachmentInstance(policyRef awsarn.ARN, attType AttachmentType, r
 
 // Create attaches the referenced policy on referenced target type
 func (pa *PolicyAttachmentInstance) Create(svc iamiface.IAMAPI) error {
-	if err := createPolicyAttachment(svc, pa.Type, pa.PolicyRef, pa.TargetRef); err != nil {
+	return pa.CreateWithContext(context.Background(), svc)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (pa *PolicyAttachmentInstance) CreateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
+	if err := createPolicyAttachment(ctx, svc, pa.Type, pa.PolicyRef, pa.TargetRef); err != nil {
 		return err
 	}
 	return nil
@@ -146,17 +177,27 @@ func (pa *PolicyAttachmentInstance) Create(svc iamiface.IAMAPI) error {
 
 // Update for PolicyAttachmentInstance doesn't do anything
 func (pa *PolicyAttachmentInstance) Update(svc iamiface.IAMAPI) error {
+	return pa.UpdateWithContext(context.Background(), svc)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (pa *PolicyAttachmentInstance) UpdateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	// PolicyAttachment not updateable
 	return nil
 }
 
 // Delete removes the referenced Policy from referenced target type
 func (pa *PolicyAttachmentInstance) Delete(svc iamiface.IAMAPI) error {
-	if !pa.IsCreated(svc) {
+	return pa.DeleteWithContext(context.Background(), svc)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (pa *PolicyAttachmentInstance) DeleteWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
+	if !pa.IsCreatedWithContext(ctx, svc) {
 		return aws.NewInstanceNotYetCreatedError("PolicyAttachment not yet created")
 	}
 
-	if err := deletePolicyAttachment(svc, pa.Type, pa.PolicyRef, pa.TargetRef); err != nil {
+	if err := deletePolicyAttachment(ctx, svc, pa.Type, pa.PolicyRef, pa.TargetRef); err != nil {
 		return err
 	}
 
@@ -169,6 +210,372 @@ func (pa *PolicyAttachmentInstance) ARN() awsarn.ARN {
 }
 
 func (pa *PolicyAttachmentInstance) IsCreated(svc iamiface.IAMAPI) bool {
-	_, err := getPolicyAttachment(svc, pa.Type, pa.PolicyRef, pa.TargetRef)
+	return pa.IsCreatedWithContext(context.Background(), svc)
+}
+
+// IsCreatedWithContext is the same as IsCreated with the addition of the ability to pass a context
+func (pa *PolicyAttachmentInstance) IsCreatedWithContext(ctx context.Context, svc iamiface.IAMAPI) bool {
+	_, err := getPolicyAttachment(ctx, svc, pa.Type, pa.PolicyRef, pa.TargetRef)
 	return err == nil
 }
+
+// PolicyAttachment represents the attachment of an IAM Policy to a Role, User or Group as a CloudObject. It has no
+// name of its own, it's identified by what it attaches to what.
+type PolicyAttachment struct {
+	// policy is the name of a Policy CloudObject, or the ARN of any IAM policy (e.g. an AWS managed one)
+	policy     string
+	attachType AttachmentType
+	// target is the name of a Role, User or Group CloudObject, or the ARN of any of them
+	target  string
+	status  *PolicyAttachmentStatus
+	session iamiface.IAMAPI
+	// logger is where the PolicyAttachment logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+func NewPolicyAttachment(policy string, attachType AttachmentType, target string,
+	session client.ConfigProvider) (*PolicyAttachment, error) {
+	if len(policy) == 0 {
+		return nil, fmt.Errorf("given policy is empty")
+	}
+	if len(target) == 0 {
+		return nil, fmt.Errorf("given target is empty")
+	}
+	if !attachType.Valid() {
+		return nil, aws.NewInstanceError(ErrAttachmentTypeUnknown, fmt.Sprintf("unknown attachment type '%s'",
+			attachType))
+	}
+
+	pa := PolicyAttachment{
+		policy:     policy,
+		attachType: attachType,
+		target:     target,
+		session:    Client(session),
+	}
+
+	return &pa, nil
+}
+
+func (pa *PolicyAttachment) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return pa.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (pa *PolicyAttachment) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, pa.logger, pa.ID(), "create")
+	assertedSpec, ok := spec.(*PolicyAttachmentSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+	if err := assertedSpec.Diff(pa.ID(), pa.references()).ImmutableChangesError(); err != nil {
+		return nil, err
+	}
+
+	exists, err := pa.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	policyArn, err := pa.policyARN(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachPolicy(ctx, pa.session, pa.attachType, policyArn, pa.targetName()); err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := pa.ReadWithContext(aws.WithRetryCodes(ctx, iam.ErrCodeNoSuchEntityException)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (pa *PolicyAttachment) Read() error {
+	return pa.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context. The attachment doesn't
+// exist, if either the policy or the target doesn't.
+func (pa *PolicyAttachment) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, pa.logger, pa.ID(), "read")
+	policyArn, err := pa.policyARN(ctx)
+	if err != nil {
+		return err
+	}
+	aps, err := listAttachedPolicies(ctx, pa.session, pa.attachType, pa.targetName())
+	if err != nil {
+		return notExistsError(err, string(pa.attachType), cloudobject.ID(pa.targetName()))
+	}
+
+	for _, policy := range aps {
+		if awssdk.StringValue(policy.PolicyArn) == policyArn {
+			pa.status = &PolicyAttachmentStatus{
+				AttachedPolicy: *policy,
+				Type:           pa.attachType,
+				Target:         pa.targetName(),
+			}
+			return nil
+		}
+	}
+
+	return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+		Message: fmt.Sprintf("IAM Policy '%s' is not attached to %s '%s'", policyArn, pa.attachType, pa.targetName()),
+		ID:      pa.ID(),
+		Op:      "read",
+	}}
+}
+
+// Update can't change anything about an attachment; it only tells if the spec asks for a different one
+func (pa *PolicyAttachment) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return pa.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (pa *PolicyAttachment) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, pa.logger, pa.ID(), "update")
+	assertedSpec, ok := spec.(*PolicyAttachmentSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	// Let's update our status
+	if err := pa.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return nil, assertedSpec.Diff(pa.ID(), pa.references()).ImmutableChangesError()
+}
+
+// Plan compares the given spec against the live attachment, without changing anything
+func (pa *PolicyAttachment) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return pa.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (pa *PolicyAttachment) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, pa.logger, pa.ID(), "plan")
+	assertedSpec, ok := spec.(*PolicyAttachmentSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := pa.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(pa.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(pa.ID(), pa.references()), nil
+}
+
+// Delete detaches the policy from the target. Purge makes no difference.
+func (pa *PolicyAttachment) Delete(purge bool) error {
+	return pa.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (pa *PolicyAttachment) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, pa.logger, pa.ID(), "delete")
+	exists, err := pa.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	return detachPolicy(ctx, pa.session, pa.attachType, awssdk.StringValue(pa.status.PolicyArn), pa.targetName())
+}
+
+func (pa *PolicyAttachment) Status() cloudobject.Status {
+	return pa.status
+}
+
+func (pa *PolicyAttachment) ID() cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(PolicyAttachmentTopic, fmt.Sprintf("%s-%s-%s",
+		refName(pa.policy), pa.attachType, refName(pa.target))))
+}
+
+func (pa *PolicyAttachment) Exists() (bool, error) {
+	return cloudobject.Exists(pa)
+}
+
+func (pa *PolicyAttachment) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, pa)
+}
+
+// SetLogger makes the PolicyAttachment log its AWS calls to the given logger
+func (pa *PolicyAttachment) SetLogger(logger cloudobject.Logger) {
+	pa.logger = logger
+}
+
+// References returns the IDs of the Policy and the target, as far as they are CloudObjects and not given by ARN
+func (pa *PolicyAttachment) References(_ cloudobject.CloudObjectSpec) []cloudobject.ID {
+	var refs []cloudobject.ID
+	if !awsarn.IsARN(pa.policy) {
+		refs = append(refs, PolicyID(pa.policy))
+	}
+	if !awsarn.IsARN(pa.target) {
+		refs = append(refs, pa.attachType.targetID(pa.target))
+	}
+	return refs
+}
+
+// references returns what the attachment attaches to what, in the form of a spec
+func (pa *PolicyAttachment) references() *PolicyAttachmentSpec {
+	return &PolicyAttachmentSpec{Policy: pa.policy, Type: pa.attachType, Target: pa.target}
+}
+
+// policyARN resolves the policy of the attachment to its ARN
+func (pa *PolicyAttachment) policyARN(ctx context.Context) (string, error) {
+	if awsarn.IsARN(pa.policy) {
+		return pa.policy, nil
+	}
+	policy := Policy{name: pa.policy, session: pa.session}
+	if err := policy.ReadWithContext(ctx); err != nil {
+		return "", err
+	}
+	return policy.Status().ProviderID().String(), nil
+}
+
+// targetName resolves the target of the attachment to its name at AWS
+func (pa *PolicyAttachment) targetName() string {
+	if awsarn.IsARN(pa.target) {
+		return FriendlyNamefromARN(aws.MustParse(pa.target))
+	}
+	return pa.attachType.targetID(pa.target).String()
+}
+
+// refName is the short name of a reference, that is given by either name or ARN
+func refName(ref string) string {
+	if awsarn.IsARN(ref) {
+		return FriendlyNamefromARN(aws.MustParse(ref))
+	}
+	return ref
+}
+
+func (attachType AttachmentType) String() string {
+	return string(attachType)
+}
+
+// Valid tells whether the AttachmentType is one we know
+func (attachType AttachmentType) Valid() bool {
+	switch attachType {
+	case RoleAttachmentType, UserAttachmentType, GroupAttachmentType:
+		return true
+	default:
+		return false
+	}
+}
+
+// targetID returns the ID of the CloudObject with the given name, that policies of this type are attached to
+func (attachType AttachmentType) targetID(name string) cloudobject.ID {
+	switch attachType {
+	case RoleAttachmentType:
+		return RoleID(name)
+	case UserAttachmentType:
+		return UserID(name)
+	default:
+		return GroupID(name)
+	}
+}
+
+//////////////
+/// STATUS ///
+//////////////
+
+type PolicyAttachmentStatus struct {
+	iam.AttachedPolicy
+
+	Type   AttachmentType
+	Target string
+}
+
+func (status *PolicyAttachmentStatus) String() string {
+	if status == nil {
+		return ""
+	}
+	return awsutil.Prettify(*status)
+}
+
+func (status *PolicyAttachmentStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return AvailableState
+}
+
+// PolicyAttachmentDetails are the key facts of an IAM PolicyAttachment, as given in its cloudobject.Summary
+type PolicyAttachmentDetails struct {
+	PolicyName string         `json:"policyName" yaml:"policyName"`
+	PolicyARN  string         `json:"policyARN" yaml:"policyARN"`
+	Type       AttachmentType `json:"type" yaml:"type"`
+	Target     string         `json:"target" yaml:"target"`
+}
+
+func (status *PolicyAttachmentStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	return PolicyAttachmentDetails{
+		PolicyName: awssdk.StringValue(status.PolicyName),
+		PolicyARN:  awssdk.StringValue(status.PolicyArn),
+		Type:       status.Type,
+		Target:     status.Target,
+	}
+}
+
+// ProviderID of an attachment is the ARN of the attached policy, as attachments have no ID of their own at AWS
+func (status *PolicyAttachmentStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
+	return cloudobject.ProviderID{
+		Type:  cloudobject.AWSProvider,
+		Value: awssdk.StringValue(status.PolicyArn),
+	}
+}
+
+////////////
+/// SPEC ///
+////////////
+
+type PolicyAttachmentSpec struct {
+	// Policy is the name of a Policy CloudObject, or the ARN of any IAM policy
+	Policy string
+	Type   AttachmentType
+	// Target is the name of a Role, User or Group CloudObject, depending on Type, or the ARN of any of them
+	Target string
+}
+
+func (spec *PolicyAttachmentSpec) Valid() (bool, error) {
+	if spec.Policy == "" || spec.Target == "" {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "policy attachment needs a policy and a target",
+		}}
+	}
+	if !spec.Type.Valid() {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("attachment type '%s' is invalid, must be one of role, user or group", spec.Type),
+		}}
+	}
+	return true, nil
+}
+
+// Diff compares the spec against what the attachment attaches. None of it can be changed.
+func (spec *PolicyAttachmentSpec) Diff(id cloudobject.ID, live *PolicyAttachmentSpec) cloudobject.Diff {
+	if live == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+	diff.CompareImmutable("Policy", live.Policy, spec.Policy)
+	diff.CompareImmutable("Type", live.Type, spec.Type)
+	diff.CompareImmutable("Target", live.Target, spec.Target)
+	return diff
+}
@@ -7,17 +7,19 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/arn"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 ///////////////
 // IAM MOCKS //
 ///////////////
 
-func (m *mockIAMClient) AttachRolePolicy(input *awsiam.AttachRolePolicyInput) (*awsiam.AttachRolePolicyOutput, error) {
+func (m *mockIAMClient) AttachRolePolicyWithContext(_ awssdk.Context, input *awsiam.AttachRolePolicyInput, _ ...request.Option) (*awsiam.AttachRolePolicyOutput, error) {
 	if *input.RoleName == ReferenceRoleName {
 		return &awsiam.AttachRolePolicyOutput{}, fmt.Errorf("referenced Role does not exist")
 	}
@@ -29,7 +31,7 @@ func (m *mockIAMClient) AttachRolePolicy(input *awsiam.AttachRolePolicyInput) (*
 	return createMockAttachRolePolicyOutput(input), nil
 }
 
-func (m *mockIAMClient) AttachUserPolicy(input *awsiam.AttachUserPolicyInput) (*awsiam.AttachUserPolicyOutput, error) {
+func (m *mockIAMClient) AttachUserPolicyWithContext(_ awssdk.Context, input *awsiam.AttachUserPolicyInput, _ ...request.Option) (*awsiam.AttachUserPolicyOutput, error) {
 	if *input.UserName == ReferenceUserName {
 		return &awsiam.AttachUserPolicyOutput{}, fmt.Errorf("referenced User does not exist")
 	}
@@ -42,7 +44,7 @@ func (m *mockIAMClient) AttachUserPolicy(input *awsiam.AttachUserPolicyInput) (*
 	return createMockAttachUserPolicyOutput(input), nil
 }
 
-func (m *mockIAMClient) AttachGroupPolicy(input *awsiam.AttachGroupPolicyInput) (*awsiam.AttachGroupPolicyOutput, error) {
+func (m *mockIAMClient) AttachGroupPolicyWithContext(_ awssdk.Context, input *awsiam.AttachGroupPolicyInput, _ ...request.Option) (*awsiam.AttachGroupPolicyOutput, error) {
 	if *input.GroupName == ReferenceGroupName {
 		return &awsiam.AttachGroupPolicyOutput{}, fmt.Errorf("referenced Group does not exist")
 	}
@@ -54,19 +56,19 @@ func (m *mockIAMClient) AttachGroupPolicy(input *awsiam.AttachGroupPolicyInput)
 	return createMockAttachGroupPolicyOutput(input), nil
 }
 
-func (m *mockIAMClient) ListAttachedRolePolicies(input *awsiam.ListAttachedRolePoliciesInput) (*awsiam.ListAttachedRolePoliciesOutput, error) {
+func (m *mockIAMClient) ListAttachedRolePoliciesWithContext(_ awssdk.Context, input *awsiam.ListAttachedRolePoliciesInput, _ ...request.Option) (*awsiam.ListAttachedRolePoliciesOutput, error) {
 	return createMockListAttachedRolePoliciesOutput(input), nil
 }
 
-func (m *mockIAMClient) ListAttachedUserPolicies(input *awsiam.ListAttachedUserPoliciesInput) (*awsiam.ListAttachedUserPoliciesOutput, error) {
+func (m *mockIAMClient) ListAttachedUserPoliciesWithContext(_ awssdk.Context, input *awsiam.ListAttachedUserPoliciesInput, _ ...request.Option) (*awsiam.ListAttachedUserPoliciesOutput, error) {
 	return createMockListAttachedUserPoliciesOutput(input), nil
 }
 
-func (m *mockIAMClient) ListAttachedGroupPolicies(input *awsiam.ListAttachedGroupPoliciesInput) (*awsiam.ListAttachedGroupPoliciesOutput, error) {
+func (m *mockIAMClient) ListAttachedGroupPoliciesWithContext(_ awssdk.Context, input *awsiam.ListAttachedGroupPoliciesInput, _ ...request.Option) (*awsiam.ListAttachedGroupPoliciesOutput, error) {
 	return createMockListAttachedGroupPoliciesOutput(input), nil
 }
 
-func (m *mockIAMClient) DetachRolePolicy(input *awsiam.DetachRolePolicyInput) (*awsiam.DetachRolePolicyOutput, error) {
+func (m *mockIAMClient) DetachRolePolicyWithContext(_ awssdk.Context, input *awsiam.DetachRolePolicyInput, _ ...request.Option) (*awsiam.DetachRolePolicyOutput, error) {
 	if *input.RoleName == ReferenceRoleName {
 		return &awsiam.DetachRolePolicyOutput{}, aws.NewInstanceError(aws.ErrAWSInstanceNotYetCreated, "referenced Role does not exist")
 	}
@@ -83,7 +85,7 @@ func (m *mockIAMClient) DetachRolePolicy(input *awsiam.DetachRolePolicyInput) (*
 	return &awsiam.DetachRolePolicyOutput{}, nil
 }
 
-func (m *mockIAMClient) DetachUserPolicy(input *awsiam.DetachUserPolicyInput) (*awsiam.DetachUserPolicyOutput, error) {
+func (m *mockIAMClient) DetachUserPolicyWithContext(_ awssdk.Context, input *awsiam.DetachUserPolicyInput, _ ...request.Option) (*awsiam.DetachUserPolicyOutput, error) {
 	if *input.UserName == ReferenceUserName {
 		return &awsiam.DetachUserPolicyOutput{}, aws.NewInstanceError(aws.ErrAWSInstanceNotYetCreated, "referenced Role does not exist")
 	}
@@ -100,7 +102,7 @@ func (m *mockIAMClient) DetachUserPolicy(input *awsiam.DetachUserPolicyInput) (*
 	return &awsiam.DetachUserPolicyOutput{}, nil
 }
 
-func (m *mockIAMClient) DetachGroupPolicy(input *awsiam.DetachGroupPolicyInput) (*awsiam.DetachGroupPolicyOutput, error) {
+func (m *mockIAMClient) DetachGroupPolicyWithContext(_ awssdk.Context, input *awsiam.DetachGroupPolicyInput, _ ...request.Option) (*awsiam.DetachGroupPolicyOutput, error) {
 	if *input.GroupName == ReferenceGroupName {
 		return &awsiam.DetachGroupPolicyOutput{}, aws.NewInstanceError(aws.ErrAWSInstanceNotYetCreated, "referenced Role does not exist")
 	}
@@ -261,6 +263,43 @@ func TestPolicyAttachmentInstance_ARN(t *testing.T) {
 	assert.True(t, awsarn.IsARN(getReferencePolicyAttachmentInstance().ARN().String()))
 }
 
+func TestNewPolicyAttachment(t *testing.T) {
+	pa, err := NewPolicyAttachment(ReferencePolicyName, RoleAttachmentType, ReferenceRoleName, getReferenceSession(t))
+	assert.NoError(t, err)
+	var _ cloudobject.CloudObject = pa
+	assert.Equal(t, cloudobject.ID("clobjx-polatt-"+ReferencePolicyName+"-role-"+ReferenceRoleName), pa.ID())
+	assert.Equal(t, []cloudobject.ID{PolicyID(ReferencePolicyName), RoleID(ReferenceRoleName)}, pa.References(nil))
+
+	// References given by ARN are managed elsewhere
+	pa, err = NewPolicyAttachment(getReferencePolicyExistingArn().String(), UserAttachmentType, ReferenceUserName,
+		getReferenceSession(t))
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudobject.ID{UserID(ReferenceUserName)}, pa.References(nil))
+	assert.Equal(t, UserID(ReferenceUserName).String(), pa.targetName())
+
+	_, err = NewPolicyAttachment(ReferencePolicyName, "bucket", ReferenceRoleName, getReferenceSession(t))
+	assert.Error(t, err)
+}
+
+func TestPolicyAttachmentSpec_Diff(t *testing.T) {
+	pa, err := NewPolicyAttachment(ReferencePolicyName, RoleAttachmentType, ReferenceRoleName, getReferenceSession(t))
+	assert.NoError(t, err)
+
+	spec := &PolicyAttachmentSpec{Policy: ReferencePolicyName, Type: RoleAttachmentType, Target: ReferenceRoleName}
+	ok, err := spec.Valid()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.NoError(t, spec.Diff(pa.ID(), pa.references()).ImmutableChangesError())
+
+	// An attachment can't be pointed somewhere else
+	spec.Target = "other"
+	assert.Error(t, spec.Diff(pa.ID(), pa.references()).ImmutableChangesError())
+
+	spec.Type = "bucket"
+	ok, _ = spec.Valid()
+	assert.False(t, ok)
+}
+
 /////////////
 // HELPERS //
 /////////////
@@ -1,26 +1,31 @@
 package iam
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/client"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
-func createRole(svc iamiface.IAMAPI, rn string, roleDesc string, sessionDuration int64, pd PolicyDocument) (*awsiam.CreateRoleOutput, error) {
+func createRole(ctx context.Context, svc iamiface.IAMAPI, rn string, roleDesc string, sessionDuration int64, pd PolicyDocument) (*awsiam.CreateRoleOutput, error) {
 
 	b, err := json.Marshal(&pd)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := svc.CreateRole(&awsiam.CreateRoleInput{
+	result, err := svc.CreateRoleWithContext(ctx, &awsiam.CreateRoleInput{
 		AssumeRolePolicyDocument: awssdk.String(string(b)),
 		Description:              awssdk.String(roleDesc),
 		MaxSessionDuration:       awssdk.Int64(sessionDuration),
@@ -33,9 +38,9 @@ func createRole(svc iamiface.IAMAPI, rn string, roleDesc string, sessionDuration
 	return result, nil
 }
 
-func updateRole(svc iamiface.IAMAPI, roleArn awsarn.ARN, roleDesc string) (*awsiam.UpdateRoleOutput, error) {
+func updateRole(ctx context.Context, svc iamiface.IAMAPI, roleArn awsarn.ARN, roleDesc string) (*awsiam.UpdateRoleOutput, error) {
 
-	result, err := svc.UpdateRole(&awsiam.UpdateRoleInput{
+	result, err := svc.UpdateRoleWithContext(ctx, &awsiam.UpdateRoleInput{
 		Description: awssdk.String(roleDesc),
 		RoleName:    awssdk.String(FriendlyNamefromARN(roleArn)),
 	})
@@ -46,34 +51,33 @@ func updateRole(svc iamiface.IAMAPI, roleArn awsarn.ARN, roleDesc string) (*awsi
 	return result, nil
 }
 
-func deleteRole(svc iamiface.IAMAPI, roleArn awsarn.ARN) (*awsiam.DeleteRoleOutput, error) {
+func deleteRole(ctx context.Context, svc iamiface.IAMAPI, roleArn awsarn.ARN) (*awsiam.DeleteRoleOutput, error) {
 
-	res, err := svc.DeleteRole(&awsiam.DeleteRoleInput{
+	res, err := svc.DeleteRoleWithContext(ctx, &awsiam.DeleteRoleInput{
 		RoleName: awssdk.String(FriendlyNamefromARN(roleArn)),
 	})
-	if err != nil {
-		if err.(awserr.Error).Code() != awsiam.ErrCodeNoSuchEntityException {
-			return nil, err
-		}
+	if err != nil && !isNoSuchEntityError(err) {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func getRole(svc iamiface.IAMAPI, roleArn awsarn.ARN) (*awsiam.GetRoleOutput, error) {
+func getRole(ctx context.Context, svc iamiface.IAMAPI, roleName string) (*awsiam.GetRoleOutput, error) {
 
-	result, err := svc.GetRole(&awsiam.GetRoleInput{
-		RoleName: awssdk.String(FriendlyNamefromARN(roleArn)),
+	result, err := svc.GetRoleWithContext(ctx, &awsiam.GetRoleInput{
+		RoleName: awssdk.String(roleName),
 	})
-
 	if err != nil {
-		fmt.Println("Error", err)
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// RoleInstance manages an IAM Role through the aws.Instance interface, with a client passed per call.
+//
+// Deprecated: use Role, which implements cloudobject.CloudObject.
 type RoleInstance struct {
 	Name               string
 	Description        string
@@ -96,41 +100,15 @@ func NewExistingRoleInstance(name string, description string, sessionDuration in
 	}
 }
 
-// An old fetch implementation; abandoned due to sync problems
-//func NewExistingRoleInstance(svc iamiface.IAMAPI, arn awsarn.ARN) (*RoleInstance, error) {
-//	var ri *RoleInstance
-//	emptyarn := awsarn.ARN{}.String()
-//	if arn.String() == emptyarn {
-//		return ri, fmt.Errorf("given ARN is empty")
-//	}
-//
-//	out, err := getRole(svc, arn)
-//	if err != nil {
-//		return ri, err
-//	}
-//
-//	var pd PolicyDocument
-//	policyJson, err := url.QueryUnescape(awssdk.StringValue(out.Role.AssumeRolePolicyDocument))
-//	if err != nil {
-//		return ri, err
-//	}
-//	if err = json.Unmarshal([]byte(policyJson), &pd); err != nil {
-//		return ri, err
-//	}
-//	ri = &RoleInstance{
-//		Name:           awssdk.StringValue(out.Role.RoleName),
-//		Description:    awssdk.StringValue(out.Role.Description),
-//		PolicyDocument: pd,
-//		arn:            arn,
-//	}
-//
-//	return ri, nil
-//}
-
 // Reconcile creates or updates an AWS Role
 func (r *RoleInstance) Create(svc iamiface.IAMAPI) error {
+	return r.CreateWithContext(context.Background(), svc)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (r *RoleInstance) CreateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	var newarn awsarn.ARN
-	out, err := createRole(svc, r.Name, r.Description, r.MaxSessionDuration, r.PolicyDocument)
+	out, err := createRole(ctx, svc, r.Name, r.Description, r.MaxSessionDuration, r.PolicyDocument)
 	if err != nil {
 		return err
 	}
@@ -143,11 +121,16 @@ func (r *RoleInstance) Create(svc iamiface.IAMAPI) error {
 }
 
 func (r *RoleInstance) Update(svc iamiface.IAMAPI) error {
+	return r.UpdateWithContext(context.Background(), svc)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (r *RoleInstance) UpdateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !r.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Role '%s' not yet created", r.Name))
 	}
 
-	_, err := updateRole(svc, r.arn, r.Description)
+	_, err := updateRole(ctx, svc, r.arn, r.Description)
 	if err != nil {
 		return err
 	}
@@ -155,11 +138,16 @@ func (r *RoleInstance) Update(svc iamiface.IAMAPI) error {
 }
 
 func (r *RoleInstance) Delete(svc iamiface.IAMAPI) error {
+	return r.DeleteWithContext(context.Background(), svc)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (r *RoleInstance) DeleteWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !r.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("Role '%s' not yet created", r.Name))
 	}
 
-	_, err := deleteRole(svc, r.arn)
+	_, err := deleteRole(ctx, svc, r.arn)
 	if err != nil {
 		return err
 	}
@@ -173,3 +161,388 @@ func (r *RoleInstance) ARN() awsarn.ARN {
 func (r *RoleInstance) IsCreated(svc iamiface.IAMAPI) bool {
 	return r.arn.String() != awsarn.ARN{}.String()
 }
+
+// DefaultMaxSessionDuration is how long sessions of a Role last at most (in seconds), if the spec doesn't say
+const DefaultMaxSessionDuration = 3600
+
+// Role represents the IAM Role CloudObject
+type Role struct {
+	name string
+	// existing Roles are named as given, without our naming scheme
+	existing bool
+	status   *RoleStatus
+	session  iamiface.IAMAPI
+	// logger is where the Role logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+func NewRole(name string, session client.ConfigProvider) (*Role, error) {
+	if err := validateName(name, RoleTopic, 64); err != nil {
+		return nil, err
+	}
+
+	role := Role{
+		name:    name,
+		session: Client(session),
+	}
+
+	return &role, nil
+}
+
+// NewExistingRole returns the Role with the given name or ARN as it is named at AWS, e.g. to inspect or adopt a
+// Role that was created elsewhere
+func NewExistingRole(ref string, session client.ConfigProvider) (*Role, error) {
+	name, err := nameFromRef(ref, "role")
+	if err != nil {
+		return nil, err
+	}
+
+	role := Role{
+		name:     name,
+		existing: true,
+		session:  Client(session),
+	}
+
+	return &role, nil
+}
+
+func (r *Role) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return r.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (r *Role) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "create")
+	assertedSpec, ok := spec.(*RoleSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	exists, err := r.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	_, err = createRole(ctx, r.session, r.ID().String(), assertedSpec.Description,
+		assertedSpec.maxSessionDuration(), assertedSpec.PolicyDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := r.ReadWithContext(aws.WithRetryCodes(ctx, awsiam.ErrCodeNoSuchEntityException)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (r *Role) Read() error {
+	return r.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context. The trust policy is
+// decoded into the status.
+func (r *Role) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "read")
+	out, err := getRole(ctx, r.session, r.ID().String())
+	if err != nil {
+		return notExistsError(err, "Role", r.ID())
+	}
+	pd, err := DecodePolicyDocument(awssdk.StringValue(out.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return err
+	}
+	r.status = &RoleStatus{Role: *out.Role, PolicyDocument: pd}
+
+	return nil
+}
+
+func (r *Role) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return r.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (r *Role) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "update")
+	assertedSpec, ok := spec.(*RoleSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	// Let's update our status
+	if err := r.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	diff := assertedSpec.Diff(r.ID(), r.status)
+	if !diff.HasChanges() {
+		return nil, nil
+	}
+
+	if diff.Changed("Description", "MaxSessionDuration") {
+		_, err := r.session.UpdateRoleWithContext(ctx, &awsiam.UpdateRoleInput{
+			Description:        awssdk.String(assertedSpec.Description),
+			MaxSessionDuration: awssdk.Int64(assertedSpec.maxSessionDuration()),
+			RoleName:           r.ID().StringPtr(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if diff.Changed("PolicyDocument") {
+		b, err := json.Marshal(&assertedSpec.PolicyDocument)
+		if err != nil {
+			return nil, err
+		}
+		_, err = r.session.UpdateAssumeRolePolicyWithContext(ctx, &awsiam.UpdateAssumeRolePolicyInput{
+			PolicyDocument: awssdk.String(string(b)),
+			RoleName:       r.ID().StringPtr(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// re-trigger status update
+	if err := r.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Plan compares the given spec against the live IAM Role, without changing anything
+func (r *Role) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return r.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (r *Role) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "plan")
+	assertedSpec, ok := spec.(*RoleSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := r.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(r.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(r.ID(), r.status), nil
+}
+
+// Delete detaches all managed policies from the Role and deletes it. Purge makes no difference.
+func (r *Role) Delete(purge bool) error {
+	return r.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (r *Role) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "delete")
+	exists, err := r.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	if err := detachAllPolicies(ctx, r.session, RoleAttachmentType, r.ID().String()); err != nil {
+		return err
+	}
+	_, err = r.session.DeleteRoleWithContext(ctx, &awsiam.DeleteRoleInput{
+		RoleName: r.ID().StringPtr(),
+	})
+	if err != nil && !isNoSuchEntityError(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Role) Status() cloudobject.Status {
+	return r.status
+}
+
+func (r *Role) ID() cloudobject.ID {
+	if r.existing {
+		return cloudobject.ID(r.name)
+	}
+	return RoleID(r.name)
+}
+
+// RoleID returns the ID of the Role with the given name, e.g. to reference it from other cloud objects
+func RoleID(name string) cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(RoleTopic, name))
+}
+
+func (r *Role) Exists() (bool, error) {
+	return cloudobject.Exists(r)
+}
+
+func (r *Role) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, r)
+}
+
+// SetLogger makes the Role log its AWS calls to the given logger
+func (r *Role) SetLogger(logger cloudobject.Logger) {
+	r.logger = logger
+}
+
+//////////////
+/// STATUS ///
+//////////////
+
+type RoleStatus struct {
+	awsiam.Role
+
+	// PolicyDocument is the decoded trust policy of the Role
+	PolicyDocument PolicyDocument
+}
+
+func (status *RoleStatus) String() string {
+	if status == nil {
+		return ""
+	}
+	return awsutil.Prettify(*status)
+}
+
+func (status *RoleStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return AvailableState
+}
+
+// RoleDetails are the key facts of an IAM Role, as given in its cloudobject.Summary
+type RoleDetails struct {
+	RoleName           string `json:"roleName" yaml:"roleName"`
+	RoleID             string `json:"roleID" yaml:"roleID"`
+	Path               string `json:"path" yaml:"path"`
+	Description        string `json:"description,omitempty" yaml:"description,omitempty"`
+	MaxSessionDuration int64  `json:"maxSessionDuration" yaml:"maxSessionDuration"`
+}
+
+func (status *RoleStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	return RoleDetails{
+		RoleName:           awssdk.StringValue(status.RoleName),
+		RoleID:             awssdk.StringValue(status.RoleId),
+		Path:               awssdk.StringValue(status.Path),
+		Description:        awssdk.StringValue(status.Description),
+		MaxSessionDuration: awssdk.Int64Value(status.MaxSessionDuration),
+	}
+}
+
+func (status *RoleStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
+	return cloudobject.ProviderID{
+		Type:  cloudobject.AWSProvider,
+		Value: awssdk.StringValue(status.Arn),
+	}
+}
+
+////////////
+/// SPEC ///
+////////////
+
+type RoleSpec struct {
+	Description string
+	// PolicyDocument is the trust policy, that tells who may assume the Role
+	PolicyDocument PolicyDocument
+	// MaxSessionDuration is in seconds, between 3600 and 43200. Defaults to DefaultMaxSessionDuration.
+	MaxSessionDuration int64
+}
+
+func (spec *RoleSpec) Valid() (bool, error) {
+	if len(spec.PolicyDocument.Statement) == 0 {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "trust policy document has no statements",
+		}}
+	}
+	if d := spec.MaxSessionDuration; d != 0 && (d < 3600 || d > 43200) {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("max session duration of %d seconds is not between 3600 and 43200", d),
+		}}
+	}
+	return true, nil
+}
+
+// Diff compares the spec against the given live status
+func (spec *RoleSpec) Diff(id cloudobject.ID, status *RoleStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+	diff.Compare("Description", awssdk.StringValue(status.Description), spec.Description)
+	diff.Compare("MaxSessionDuration", awssdk.Int64Value(status.MaxSessionDuration), spec.maxSessionDuration())
+	diff.Compare("PolicyDocument", status.PolicyDocument, spec.PolicyDocument)
+	return diff
+}
+
+func (spec *RoleSpec) maxSessionDuration() int64 {
+	if spec.MaxSessionDuration == 0 {
+		return DefaultMaxSessionDuration
+	}
+	return spec.MaxSessionDuration
+}
+
+// trustedServices are the services that can be given as trust policy preset
+var trustedServices = map[string]string{
+	"ec2":       "ec2.amazonaws.com",
+	"ecs-tasks": "ecs-tasks.amazonaws.com",
+	"eks":       "eks.amazonaws.com",
+	"lambda":    "lambda.amazonaws.com",
+	"rds":       "rds.amazonaws.com",
+}
+
+// TrustPolicyPresets returns the names of the services that can be given to TrustPolicyPreset
+func TrustPolicyPresets() []string {
+	presets := make([]string, 0, len(trustedServices))
+	for preset := range trustedServices {
+		presets = append(presets, preset)
+	}
+	sort.Strings(presets)
+	return presets
+}
+
+// TrustPolicyPreset returns the trust policy for a common case. The preset is either one of TrustPolicyPresets, to
+// let that AWS service assume the Role, or "account:<id>", to let the given AWS account assume the Role.
+func TrustPolicyPreset(preset string) (PolicyDocument, error) {
+	if account := strings.TrimPrefix(preset, "account:"); account != preset {
+		if len(account) != 12 {
+			return PolicyDocument{}, fmt.Errorf("'%s' is not an AWS account ID", account)
+		}
+		return AssumeRolePolicyDocument("AWS", fmt.Sprintf("arn:aws:iam::%s:root", account)), nil
+	}
+	service, ok := trustedServices[preset]
+	if !ok {
+		return PolicyDocument{}, fmt.Errorf("unknown trust policy preset '%s' (known presets: %s, account:<id>)",
+			preset, strings.Join(TrustPolicyPresets(), ", "))
+	}
+	return AssumeRolePolicyDocument("Service", service), nil
+}
+
+// AssumeRolePolicyDocument returns a trust policy, that allows the given principals to assume the Role
+func AssumeRolePolicyDocument(principalType string, principals ...string) PolicyDocument {
+	return PolicyDocument{
+		Version: PolicyVersion20121017,
+		Statement: []StatementEntry{{
+			Effect:    "Allow",
+			Principal: Principal{principalType: StringList(principals)},
+			Action:    StringList{"sts:AssumeRole"},
+		}},
+	}
+}
@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -8,10 +9,12 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 ///////////////
@@ -27,7 +30,7 @@ const (
 	ReferenceRegion              = "eu-west-1"
 )
 
-func (m *mockIAMClient) CreateRole(input *awsiam.CreateRoleInput) (*awsiam.CreateRoleOutput, error) {
+func (m *mockIAMClient) CreateRoleWithContext(_ awssdk.Context, input *awsiam.CreateRoleInput, _ ...request.Option) (*awsiam.CreateRoleOutput, error) {
 	if *input.RoleName == ReferenceExistingRoleName {
 		return nil, fmt.Errorf("Role already exists")
 	}
@@ -41,7 +44,7 @@ func (m *mockIAMClient) CreateRole(input *awsiam.CreateRoleInput) (*awsiam.Creat
 	return out, nil
 }
 
-func (m *mockIAMClient) UpdateRole(input *awsiam.UpdateRoleInput) (*awsiam.UpdateRoleOutput, error) {
+func (m *mockIAMClient) UpdateRoleWithContext(_ awssdk.Context, input *awsiam.UpdateRoleInput, _ ...request.Option) (*awsiam.UpdateRoleOutput, error) {
 
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, getReferenceUpdateRoleInput(), input)
@@ -49,14 +52,14 @@ func (m *mockIAMClient) UpdateRole(input *awsiam.UpdateRoleInput) (*awsiam.Updat
 	return &awsiam.UpdateRoleOutput{}, nil
 }
 
-func (m *mockIAMClient) DeleteRole(input *awsiam.DeleteRoleInput) (*awsiam.DeleteRoleOutput, error) {
+func (m *mockIAMClient) DeleteRoleWithContext(_ awssdk.Context, input *awsiam.DeleteRoleInput, _ ...request.Option) (*awsiam.DeleteRoleOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, awssdk.String(ReferenceExistingRoleName), input.RoleName)
 
 	return &awsiam.DeleteRoleOutput{}, nil
 }
 
-func (m *mockIAMClient) GetRole(input *awsiam.GetRoleInput) (*awsiam.GetRoleOutput, error) {
+func (m *mockIAMClient) GetRoleWithContext(_ awssdk.Context, input *awsiam.GetRoleInput, _ ...request.Option) (*awsiam.GetRoleOutput, error) {
 	var ro *awsiam.GetRoleOutput
 	if awssdk.StringValue(input.RoleName) == FriendlyNamefromARN(getReferenceRoleNonExistingArn()) {
 		return ro, awserr.New(awsiam.ErrCodeNoSuchEntityException, "", fmt.Errorf("entity not found"))
@@ -176,10 +179,81 @@ func TestRoleInstance_createRole(t *testing.T) {
 	// Setup Test
 	mockSvc := &mockIAMClient{t: t}
 
-	_, err := createRole(mockSvc, "test/name", "test", 3600, getReferencePolicyDocument())
+	_, err := createRole(context.Background(), mockSvc, "test/name", "test", 3600, getReferencePolicyDocument())
 	assert.Error(t, err)
 }
 
+func TestRoleSpec_Valid(t *testing.T) {
+	spec := &RoleSpec{PolicyDocument: getReferencePolicyDocument()}
+	ok, err := spec.Valid()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	spec.MaxSessionDuration = 60
+	ok, err = spec.Valid()
+	assert.False(t, ok)
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+
+	ok, _ = (&RoleSpec{}).Valid()
+	assert.False(t, ok)
+}
+
+func TestRoleSpec_Diff(t *testing.T) {
+	id := RoleID(ReferenceRoleName)
+	spec := &RoleSpec{Description: ReferenceRoleDescription, PolicyDocument: getReferencePolicyDocument()}
+	assert.True(t, spec.Diff(id, nil).Create)
+
+	// An unset MaxSessionDuration means the default
+	status := &RoleStatus{
+		Role: awsiam.Role{
+			Description:        awssdk.String(ReferenceRoleDescription),
+			MaxSessionDuration: awssdk.Int64(DefaultMaxSessionDuration),
+		},
+		PolicyDocument: getReferencePolicyDocument(),
+	}
+	assert.False(t, spec.Diff(id, status).HasChanges())
+
+	spec.MaxSessionDuration = 7200
+	assert.Equal(t, []cloudobject.Change{{Field: "MaxSessionDuration", Old: int64(3600), New: int64(7200)}},
+		spec.Diff(id, status).Changes)
+}
+
+func TestRole_ReadExisting(t *testing.T) {
+	role, err := NewExistingRole(fmt.Sprintf("arn:aws:iam::123456789012:role/%s", ReferenceRoleName),
+		getReferenceSession(t))
+	assert.NoError(t, err)
+	assert.Equal(t, cloudobject.ID(ReferenceRoleName), role.ID())
+
+	role.session = &mockIAMClient{t: t}
+	assert.NoError(t, role.Read())
+	status := role.Status().(*RoleStatus)
+	assert.Equal(t, ReferenceRoleDescription, awssdk.StringValue(status.Description))
+	assert.Equal(t, getReferencePolicyDocument(), status.PolicyDocument)
+
+	role, err = NewExistingRole(getReferenceRoleNonExistingArn().String(), getReferenceSession(t))
+	assert.NoError(t, err)
+	role.session = &mockIAMClient{t: t}
+	assert.True(t, cloudobject.IsNotExistsError(role.Read()))
+}
+
+func TestTrustPolicyPreset(t *testing.T) {
+	pd, err := TrustPolicyPreset("lambda")
+	assert.NoError(t, err)
+	assert.Equal(t, Principal{"Service": {"lambda.amazonaws.com"}}, pd.Statement[0].Principal)
+	ok, err := (&RoleSpec{PolicyDocument: pd}).Valid()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	pd, err = TrustPolicyPreset("account:123456789012")
+	assert.NoError(t, err)
+	assert.Equal(t, Principal{"AWS": {"arn:aws:iam::123456789012:root"}}, pd.Statement[0].Principal)
+
+	_, err = TrustPolicyPreset("account:1234")
+	assert.Error(t, err)
+	_, err = TrustPolicyPreset("nope")
+	assert.ErrorContains(t, err, "ec2, ecs-tasks, eks, lambda, rds")
+}
+
 /////////////
 // HELPERS //
 /////////////
@@ -1,21 +1,25 @@
 package iam
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/client"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/sethvargo/go-password/password"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
-func createUser(svc iamiface.IAMAPI, userName string) (*awsiam.CreateUserOutput, error) {
+func createUser(ctx context.Context, svc iamiface.IAMAPI, userName string) (*awsiam.CreateUserOutput, error) {
 
-	result, err := svc.CreateUser(&awsiam.CreateUserInput{
+	result, err := svc.CreateUserWithContext(ctx, &awsiam.CreateUserInput{
 		UserName: awssdk.String(userName),
 	})
 	if err != nil {
@@ -25,9 +29,9 @@ func createUser(svc iamiface.IAMAPI, userName string) (*awsiam.CreateUserOutput,
 	return result, nil
 }
 
-func updateUser(svc iamiface.IAMAPI, userName string, arn awsarn.ARN) (*awsiam.UpdateUserOutput, error) {
+func updateUser(ctx context.Context, svc iamiface.IAMAPI, userName string, arn awsarn.ARN) (*awsiam.UpdateUserOutput, error) {
 
-	result, err := svc.UpdateUser(&awsiam.UpdateUserInput{
+	result, err := svc.UpdateUserWithContext(ctx, &awsiam.UpdateUserInput{
 		NewUserName: awssdk.String(userName),
 		UserName:    awssdk.String(FriendlyNamefromARN(arn)),
 	})
@@ -38,13 +42,13 @@ func updateUser(svc iamiface.IAMAPI, userName string, arn awsarn.ARN) (*awsiam.U
 	return result, nil
 }
 
-func createLoginProfile(svc iamiface.IAMAPI, user string) (*LoginProfileCredentials, error) {
+func createLoginProfile(ctx context.Context, svc iamiface.IAMAPI, user string) (*LoginProfileCredentials, error) {
 	pass, err := password.Generate(20, 8, 4, false, false)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = svc.CreateLoginProfile(&awsiam.CreateLoginProfileInput{
+	_, err = svc.CreateLoginProfileWithContext(ctx, &awsiam.CreateLoginProfileInput{
 		Password:              awssdk.String(pass),
 		PasswordResetRequired: awssdk.Bool(false),
 		UserName:              awssdk.String(user),
@@ -56,10 +60,10 @@ func createLoginProfile(svc iamiface.IAMAPI, user string) (*LoginProfileCredenti
 	return NewLoginProfileCredentials(user, pass), nil
 }
 
-func deleteLoginProfile(svc iamiface.IAMAPI, arn awsarn.ARN) error {
+func deleteLoginProfile(ctx context.Context, svc iamiface.IAMAPI, arn awsarn.ARN) error {
 	user := FriendlyNamefromARN(arn)
 
-	_, err := svc.DeleteLoginProfile(&awsiam.DeleteLoginProfileInput{
+	_, err := svc.DeleteLoginProfileWithContext(ctx, &awsiam.DeleteLoginProfileInput{
 		UserName: awssdk.String(user),
 	})
 	if err != nil && !aws.IsNotExistsError(err) {
@@ -69,8 +73,8 @@ func deleteLoginProfile(svc iamiface.IAMAPI, arn awsarn.ARN) error {
 	return nil
 }
 
-func createAccessKey(svc iamiface.IAMAPI, user string) (*AccessKey, error) {
-	out, err := svc.CreateAccessKey(&awsiam.CreateAccessKeyInput{
+func createAccessKey(ctx context.Context, svc iamiface.IAMAPI, user string) (*AccessKey, error) {
+	out, err := svc.CreateAccessKeyWithContext(ctx, &awsiam.CreateAccessKeyInput{
 		UserName: awssdk.String(user),
 	})
 	if err != nil && !aws.IsAlreadyExistsError(err) {
@@ -83,10 +87,10 @@ func createAccessKey(svc iamiface.IAMAPI, user string) (*AccessKey, error) {
 	return NewAccessKey(id, secret), nil
 }
 
-func deleteAccessKeys(svc iamiface.IAMAPI, arn awsarn.ARN) error {
+func deleteAccessKeys(ctx context.Context, svc iamiface.IAMAPI, arn awsarn.ARN) error {
 	user := FriendlyNamefromARN(arn)
 
-	out, err := svc.ListAccessKeys(&awsiam.ListAccessKeysInput{
+	out, err := svc.ListAccessKeysWithContext(ctx, &awsiam.ListAccessKeysInput{
 		UserName: awssdk.String(user),
 	})
 	if err != nil {
@@ -94,7 +98,7 @@ func deleteAccessKeys(svc iamiface.IAMAPI, arn awsarn.ARN) error {
 	}
 
 	for _, key := range out.AccessKeyMetadata {
-		_, err := svc.DeleteAccessKey(&awsiam.DeleteAccessKeyInput{
+		_, err := svc.DeleteAccessKeyWithContext(ctx, &awsiam.DeleteAccessKeyInput{
 			AccessKeyId: key.AccessKeyId,
 			UserName:    awssdk.String(user),
 		})
@@ -106,28 +110,24 @@ func deleteAccessKeys(svc iamiface.IAMAPI, arn awsarn.ARN) error {
 	return nil
 }
 
-func deleteUser(svc iamiface.IAMAPI, arn awsarn.ARN) (*awsiam.DeleteUserOutput, error) {
+func deleteUser(ctx context.Context, svc iamiface.IAMAPI, arn awsarn.ARN) (*awsiam.DeleteUserOutput, error) {
 
-	res, err := svc.DeleteUser(&awsiam.DeleteUserInput{
+	res, err := svc.DeleteUserWithContext(ctx, &awsiam.DeleteUserInput{
 		UserName: awssdk.String(FriendlyNamefromARN(arn)),
 	})
-	if err != nil {
-		if err.(awserr.Error).Code() != awsiam.ErrCodeNoSuchEntityException {
-			return nil, err
-		}
+	if err != nil && !isNoSuchEntityError(err) {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func getUser(svc iamiface.IAMAPI, userArn awsarn.ARN) (*awsiam.GetUserOutput, error) {
+func getUser(ctx context.Context, svc iamiface.IAMAPI, userName string) (*awsiam.GetUserOutput, error) {
 
-	result, err := svc.GetUser(&awsiam.GetUserInput{
-		UserName: awssdk.String(FriendlyNamefromARN(userArn)),
+	result, err := svc.GetUserWithContext(ctx, &awsiam.GetUserInput{
+		UserName: awssdk.String(userName),
 	})
-
 	if err != nil {
-		fmt.Println("Error", err)
 		return nil, err
 	}
 
@@ -172,6 +172,9 @@ func (ak *AccessKey) Secret() string {
 	return ak.secret
 }
 
+// UserInstance manages an IAM User through the aws.Instance interface, with a client passed per call.
+//
+// Deprecated: use User, which implements cloudobject.CloudObject.
 type UserInstance struct {
 	Name                          string
 	LoginProfile                  bool
@@ -204,8 +207,13 @@ func NewExistingUserInstance(name string, loginProfile, existingLoginProfile, pr
 
 // Create creates an AWS User
 func (u *UserInstance) Create(svc iamiface.IAMAPI) error {
+	return u.CreateWithContext(context.Background(), svc)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (u *UserInstance) CreateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	var newarn awsarn.ARN
-	out, err := createUser(svc, u.Name)
+	out, err := createUser(ctx, svc, u.Name)
 	if err != nil {
 		return err
 	}
@@ -215,24 +223,29 @@ func (u *UserInstance) Create(svc iamiface.IAMAPI) error {
 	}
 	u.arn = newarn
 	// Create Access if required
-	if err = u.updateAccess(svc); err != nil {
+	if err = u.updateAccess(ctx, svc); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (u *UserInstance) Update(svc iamiface.IAMAPI) error {
+	return u.UpdateWithContext(context.Background(), svc)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (u *UserInstance) UpdateWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !u.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("User '%s' not yet created", u.Name))
 	}
 
-	_, err := updateUser(svc, u.Name, u.arn)
+	_, err := updateUser(ctx, svc, u.Name, u.arn)
 	if err != nil {
 		return err
 	}
 
 	// Update Access if required
-	if err = u.updateAccess(svc); err != nil {
+	if err = u.updateAccess(ctx, svc); err != nil {
 		return err
 	}
 
@@ -240,15 +253,20 @@ func (u *UserInstance) Update(svc iamiface.IAMAPI) error {
 }
 
 func (u *UserInstance) Delete(svc iamiface.IAMAPI) error {
+	return u.DeleteWithContext(context.Background(), svc)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (u *UserInstance) DeleteWithContext(ctx context.Context, svc iamiface.IAMAPI) error {
 	if !u.IsCreated(svc) {
 		return aws.NewInstanceNotYetCreatedError(fmt.Sprintf("User '%s' not yet created", u.Name))
 	}
 
-	if err := u.deleteAccess(svc); err != nil {
+	if err := u.deleteAccess(ctx, svc); err != nil {
 		return err
 	}
 
-	_, err := deleteUser(svc, u.arn)
+	_, err := deleteUser(ctx, svc, u.arn)
 	if err != nil {
 		return err
 	}
@@ -272,29 +290,29 @@ func (u *UserInstance) AccessKey() *AccessKey {
 	return u.programmaticAccessCredentials
 }
 
-func (u *UserInstance) updateAccess(svc iamiface.IAMAPI) error {
+func (u *UserInstance) updateAccess(ctx context.Context, svc iamiface.IAMAPI) error {
 	if u.LoginProfile && !u.existingLoginProfile {
-		creds, err := createLoginProfile(svc, u.Name)
+		creds, err := createLoginProfile(ctx, svc, u.Name)
 		if err != nil {
 			return err
 		}
 		u.loginProfileCredentials = creds
 	}
 	if !u.LoginProfile && u.existingLoginProfile {
-		if err := deleteLoginProfile(svc, u.arn); err != nil {
+		if err := deleteLoginProfile(ctx, svc, u.arn); err != nil {
 			return err
 		}
 		u.loginProfileCredentials = nil
 	}
 	if u.ProgrammaticAccess && !u.existingAccessKey {
-		creds, err := createAccessKey(svc, u.Name)
+		creds, err := createAccessKey(ctx, svc, u.Name)
 		if err != nil {
 			return err
 		}
 		u.programmaticAccessCredentials = creds
 	}
 	if !u.ProgrammaticAccess && u.existingAccessKey {
-		if err := deleteAccessKeys(svc, u.arn); err != nil {
+		if err := deleteAccessKeys(ctx, svc, u.arn); err != nil {
 			return err
 		}
 	}
@@ -302,16 +320,496 @@ func (u *UserInstance) updateAccess(svc iamiface.IAMAPI) error {
 	return nil
 }
 
-func (u *UserInstance) deleteAccess(svc iamiface.IAMAPI) error {
+func (u *UserInstance) deleteAccess(ctx context.Context, svc iamiface.IAMAPI) error {
 	if u.existingLoginProfile {
-		if err := deleteLoginProfile(svc, u.arn); err != nil {
+		if err := deleteLoginProfile(ctx, svc, u.arn); err != nil {
 			return err
 		}
 	}
 	if u.existingAccessKey {
-		if err := deleteAccessKeys(svc, u.arn); err != nil {
+		if err := deleteAccessKeys(ctx, svc, u.arn); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// User represents the IAM User CloudObject
+type User struct {
+	name string
+	// existing Users are named as given, without our naming scheme
+	existing bool
+	status   *UserStatus
+	session  iamiface.IAMAPI
+	// logger is where the User logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+func NewUser(name string, session client.ConfigProvider) (*User, error) {
+	if err := validateName(name, UserTopic, 64); err != nil {
+		return nil, err
+	}
+
+	user := User{
+		name:    name,
+		session: Client(session),
+	}
+
+	return &user, nil
+}
+
+// NewExistingUser returns the User with the given name or ARN as it is named at AWS, e.g. to inspect or adopt a
+// User that was created elsewhere
+func NewExistingUser(ref string, session client.ConfigProvider) (*User, error) {
+	name, err := nameFromRef(ref, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	user := User{
+		name:     name,
+		existing: true,
+		session:  Client(session),
+	}
+
+	return &user, nil
+}
+
+func (u *User) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return u.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context. The returned
+// UserSecrets hold the generated credentials; they can't be retrieved again later on.
+func (u *User) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, u.logger, u.ID(), "create")
+	assertedSpec, ok := spec.(*UserSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	exists, err := u.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, nil
+	}
+
+	if _, err := createUser(ctx, u.session, u.ID().String()); err != nil {
+		return nil, err
+	}
+	// AWS may not let us see the User we just created yet
+	if err := u.ReadWithContext(aws.WithRetryCodes(ctx, awsiam.ErrCodeNoSuchEntityException)); err != nil {
+		return nil, err
+	}
+
+	secrets, err := u.updateAccess(ctx, assertedSpec)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.updateGroups(ctx, assertedSpec); err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := u.ReadWithContext(aws.WithRetryCodes(ctx, awsiam.ErrCodeNoSuchEntityException)); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+func (u *User) Read() error {
+	return u.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context
+func (u *User) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, u.logger, u.ID(), "read")
+	out, err := getUser(ctx, u.session, u.ID().String())
+	if err != nil {
+		return notExistsError(err, "User", u.ID())
+	}
+	status := &UserStatus{User: *out.User}
+
+	// Whether the User has a login profile or access keys is not part of the User itself
+	_, err = u.session.GetLoginProfileWithContext(ctx, &awsiam.GetLoginProfileInput{
+		UserName: u.ID().StringPtr(),
+	})
+	if err != nil && !isNoSuchEntityError(err) {
+		return err
+	}
+	status.LoginProfile = err == nil
+
+	keys, err := u.session.ListAccessKeysWithContext(ctx, &awsiam.ListAccessKeysInput{
+		UserName: u.ID().StringPtr(),
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys.AccessKeyMetadata {
+		status.AccessKeyIDs = append(status.AccessKeyIDs, awssdk.StringValue(key.AccessKeyId))
+	}
+
+	err = u.session.ListGroupsForUserPagesWithContext(ctx, &awsiam.ListGroupsForUserInput{
+		UserName: u.ID().StringPtr(),
+	}, func(out *awsiam.ListGroupsForUserOutput, _ bool) bool {
+		for _, group := range out.Groups {
+			status.Groups = append(status.Groups, awssdk.StringValue(group.GroupName))
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(status.Groups)
+
+	u.status = status
+	return nil
+}
+
+func (u *User) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return u.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context. Newly generated
+// credentials are returned as UserSecrets.
+func (u *User) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, u.logger, u.ID(), "update")
+	assertedSpec, ok := spec.(*UserSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	// Let's update our status
+	if err := u.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+	diff := assertedSpec.Diff(u.ID(), u.status)
+	if !diff.HasChanges() {
+		return nil, nil
+	}
+
+	secrets, err := u.updateAccess(ctx, assertedSpec)
+	if err != nil {
+		return nil, err
+	}
+	if diff.Changed("Groups") {
+		if err := u.updateGroups(ctx, assertedSpec); err != nil {
+			return nil, err
+		}
+	}
+
+	// re-trigger status update
+	if err := u.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// Plan compares the given spec against the live IAM User, without changing anything
+func (u *User) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return u.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (u *User) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, u.logger, u.ID(), "plan")
+	assertedSpec, ok := spec.(*UserSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := u.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(u.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(u.ID(), u.status), nil
+}
+
+// Delete deletes the User, together with its credentials, group memberships and policy attachments. IAM keeps
+// nothing around after deletion, so purge makes no difference.
+func (u *User) Delete(purge bool) error {
+	return u.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (u *User) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, u.logger, u.ID(), "delete")
+	exists, err := u.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	// A User can only be deleted, once nothing refers to it anymore
+	if _, err := u.updateAccess(ctx, &UserSpec{}); err != nil {
+		return err
+	}
+	if err := u.updateGroups(ctx, &UserSpec{}); err != nil {
+		return err
+	}
+	if err := detachAllPolicies(ctx, u.session, UserAttachmentType, u.ID().String()); err != nil {
+		return err
+	}
+
+	_, err = u.session.DeleteUserWithContext(ctx, &awsiam.DeleteUserInput{
+		UserName: u.ID().StringPtr(),
+	})
+	if err != nil && !isNoSuchEntityError(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (u *User) Status() cloudobject.Status {
+	return u.status
+}
+
+func (u *User) ID() cloudobject.ID {
+	if u.existing {
+		return cloudobject.ID(u.name)
+	}
+	return UserID(u.name)
+}
+
+// UserID returns the ID of the User with the given name, e.g. to reference it from other cloud objects
+func UserID(name string) cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(UserTopic, name))
+}
+
+// References returns the IDs of the Groups the User should be a member of, as far as they are cloud objects
+func (u *User) References(spec cloudobject.CloudObjectSpec) []cloudobject.ID {
+	assertedSpec, ok := spec.(*UserSpec)
+	if !ok {
+		return nil
+	}
+	var refs []cloudobject.ID
+	for _, group := range assertedSpec.Groups {
+		if !awsarn.IsARN(group) {
+			refs = append(refs, GroupID(group))
+		}
+	}
+	return refs
+}
+
+func (u *User) Exists() (bool, error) {
+	return cloudobject.Exists(u)
+}
+
+func (u *User) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, u)
+}
+
+// SetLogger makes the User log its AWS calls to the given logger
+func (u *User) SetLogger(logger cloudobject.Logger) {
+	u.logger = logger
+}
+
+// updateAccess creates or deletes the login profile and access keys of the User, as the spec requires. It expects
+// an up to date status and returns the credentials it generated, if any.
+func (u *User) updateAccess(ctx context.Context, spec *UserSpec) (cloudobject.Secrets, error) {
+	var secrets UserSecrets
+	arn, err := awsarn.Parse(u.status.ProviderID().String())
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case spec.LoginProfile && !u.status.LoginProfile:
+		if secrets.LoginProfile, err = createLoginProfile(ctx, u.session, u.ID().String()); err != nil {
+			return nil, err
+		}
+	case !spec.LoginProfile && u.status.LoginProfile:
+		if err := deleteLoginProfile(ctx, u.session, arn); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case spec.ProgrammaticAccess && len(u.status.AccessKeyIDs) == 0:
+		if secrets.AccessKey, err = createAccessKey(ctx, u.session, u.ID().String()); err != nil {
+			return nil, err
+		}
+	case !spec.ProgrammaticAccess && len(u.status.AccessKeyIDs) != 0:
+		if err := deleteAccessKeys(ctx, u.session, arn); err != nil {
+			return nil, err
+		}
+	}
+
+	if secrets.LoginProfile == nil && secrets.AccessKey == nil {
+		return nil, nil
+	}
+	return &secrets, nil
+}
+
+// updateGroups adds the User to the Groups of the spec and removes it from all others. It expects an up to date
+// status.
+func (u *User) updateGroups(ctx context.Context, spec *UserSpec) error {
+	wanted := make(map[string]bool)
+	for _, group := range spec.groupNames() {
+		wanted[group] = true
+	}
+	current := make(map[string]bool)
+	for _, group := range u.status.Groups {
+		current[group] = true
+		if !wanted[group] {
+			if err := removeGroupMember(ctx, u.session, group, u.ID().String()); err != nil {
+				return err
+			}
+		}
+	}
+	for _, group := range spec.groupNames() {
+		if !current[group] {
+			if err := addGroupMember(ctx, u.session, group, u.ID().String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//////////////
+/// SECRET ///
+//////////////
+
+// UserSecrets are the credentials generated for a User. AWS only hands them out once, on creation.
+type UserSecrets struct {
+	LoginProfile *LoginProfileCredentials
+	AccessKey    *AccessKey
+}
+
+func (secrets *UserSecrets) Map() map[string]string {
+	out := make(map[string]string)
+	if secrets == nil {
+		return out
+	}
+	if secrets.LoginProfile != nil {
+		out["username"] = secrets.LoginProfile.Username()
+		out["password"] = secrets.LoginProfile.Password()
+	}
+	if secrets.AccessKey != nil {
+		out["accessKeyId"] = secrets.AccessKey.Id()
+		out["secretAccessKey"] = secrets.AccessKey.Secret()
+	}
+	return out
+}
+
+//////////////
+/// STATUS ///
+//////////////
+
+type UserStatus struct {
+	awsiam.User
+
+	LoginProfile bool
+	AccessKeyIDs []string
+	// Groups holds the names of the Groups the User is a member of
+	Groups []string
+}
+
+func (status *UserStatus) String() string {
+	if status == nil {
+		return ""
+	}
+	return awsutil.Prettify(*status)
+}
+
+func (status *UserStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return AvailableState
+}
+
+// UserDetails are the key facts of an IAM User, as given in its cloudobject.Summary
+type UserDetails struct {
+	UserName     string   `json:"userName" yaml:"userName"`
+	UserID       string   `json:"userID" yaml:"userID"`
+	Path         string   `json:"path" yaml:"path"`
+	LoginProfile bool     `json:"loginProfile" yaml:"loginProfile"`
+	AccessKeyIDs []string `json:"accessKeyIDs" yaml:"accessKeyIDs"`
+	Groups       []string `json:"groups" yaml:"groups"`
+}
+
+func (status *UserStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	return UserDetails{
+		UserName:     awssdk.StringValue(status.UserName),
+		UserID:       awssdk.StringValue(status.UserId),
+		Path:         awssdk.StringValue(status.Path),
+		LoginProfile: status.LoginProfile,
+		AccessKeyIDs: nonNil(status.AccessKeyIDs),
+		Groups:       nonNil(status.Groups),
+	}
+}
+
+func (status *UserStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
+	return cloudobject.ProviderID{
+		Type:  cloudobject.AWSProvider,
+		Value: awssdk.StringValue(status.Arn),
+	}
+}
+
+////////////
+/// SPEC ///
+////////////
+
+type UserSpec struct {
+	// LoginProfile gives the User a generated password for the AWS console
+	LoginProfile bool
+	// ProgrammaticAccess gives the User an access key for the AWS APIs
+	ProgrammaticAccess bool
+	// Groups the User is a member of, by cloud object name or by ARN for Groups managed elsewhere. The User is
+	// removed from all other Groups.
+	Groups []string
+}
+
+func (spec *UserSpec) Valid() (bool, error) {
+	for _, group := range spec.Groups {
+		if _, err := nameFromRef(group, "group"); err != nil {
+			return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: err.Error()}}
+		}
+	}
+	return true, nil
+}
+
+// groupNames returns the sorted names of the Groups of the spec, as they are named at AWS
+func (spec *UserSpec) groupNames() []string {
+	var names []string
+	for _, group := range spec.Groups {
+		if awsarn.IsARN(group) {
+			name, _ := nameFromRef(group, "group")
+			names = append(names, name)
+			continue
+		}
+		names = append(names, GroupID(group).String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Diff compares the spec against the given live status
+func (spec *UserSpec) Diff(id cloudobject.ID, status *UserStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+	diff.Compare("LoginProfile", status.LoginProfile, spec.LoginProfile)
+	diff.Compare("ProgrammaticAccess", len(status.AccessKeyIDs) != 0, spec.ProgrammaticAccess)
+	diff.Compare("Groups", status.Groups, spec.groupNames())
+	return diff
+}
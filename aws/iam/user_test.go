@@ -1,6 +1,7 @@
 package iam
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -8,10 +9,12 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awsiam "github.com/aws/aws-sdk-go/service/iam"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
 ///////////////
@@ -26,7 +29,7 @@ const (
 	ReferenceAccessKeySecret  = "XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
 )
 
-func (m *mockIAMClient) CreateUser(input *awsiam.CreateUserInput) (*awsiam.CreateUserOutput, error) {
+func (m *mockIAMClient) CreateUserWithContext(_ awssdk.Context, input *awsiam.CreateUserInput, _ ...request.Option) (*awsiam.CreateUserOutput, error) {
 	if *input.UserName == ReferenceExistingUserName {
 		return nil, fmt.Errorf("User already exists")
 	}
@@ -40,7 +43,7 @@ func (m *mockIAMClient) CreateUser(input *awsiam.CreateUserInput) (*awsiam.Creat
 	return out, nil
 }
 
-func (m *mockIAMClient) UpdateUser(input *awsiam.UpdateUserInput) (*awsiam.UpdateUserOutput, error) {
+func (m *mockIAMClient) UpdateUserWithContext(_ awssdk.Context, input *awsiam.UpdateUserInput, _ ...request.Option) (*awsiam.UpdateUserOutput, error) {
 
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, getReferenceUpdateUserInput(), input)
@@ -48,14 +51,14 @@ func (m *mockIAMClient) UpdateUser(input *awsiam.UpdateUserInput) (*awsiam.Updat
 	return &awsiam.UpdateUserOutput{}, nil
 }
 
-func (m *mockIAMClient) DeleteUser(input *awsiam.DeleteUserInput) (*awsiam.DeleteUserOutput, error) {
+func (m *mockIAMClient) DeleteUserWithContext(_ awssdk.Context, input *awsiam.DeleteUserInput, _ ...request.Option) (*awsiam.DeleteUserOutput, error) {
 	// Check if input values are still as we want them to be
 	assert.Equal(m.t, awssdk.String(ReferenceExistingUserName), input.UserName)
 
 	return &awsiam.DeleteUserOutput{}, nil
 }
 
-func (m *mockIAMClient) GetUser(input *awsiam.GetUserInput) (*awsiam.GetUserOutput, error) {
+func (m *mockIAMClient) GetUserWithContext(_ awssdk.Context, input *awsiam.GetUserInput, _ ...request.Option) (*awsiam.GetUserOutput, error) {
 	var ro *awsiam.GetUserOutput
 	if awssdk.StringValue(input.UserName) == FriendlyNamefromARN(getReferenceUserNonExistingArn()) {
 		return ro, awserr.New(awsiam.ErrCodeNoSuchEntityException, "", fmt.Errorf("entity not found"))
@@ -65,11 +68,27 @@ func (m *mockIAMClient) GetUser(input *awsiam.GetUserInput) (*awsiam.GetUserOutp
 	return createMockGetUserOutput(input), nil
 }
 
-func (m *mockIAMClient) CreateAccessKey(input *awsiam.CreateAccessKeyInput) (*awsiam.CreateAccessKeyOutput, error) {
+func (m *mockIAMClient) GetLoginProfileWithContext(_ awssdk.Context, input *awsiam.GetLoginProfileInput, _ ...request.Option) (*awsiam.GetLoginProfileOutput, error) {
+	return &awsiam.GetLoginProfileOutput{
+		LoginProfile: &awsiam.LoginProfile{
+			CreateDate: awssdk.Time(getReferenceCreateTimestamp()),
+			UserName:   input.UserName,
+		},
+	}, nil
+}
+
+func (m *mockIAMClient) ListGroupsForUserPagesWithContext(_ awssdk.Context, input *awsiam.ListGroupsForUserInput, fn func(*awsiam.ListGroupsForUserOutput, bool) bool, _ ...request.Option) error {
+	fn(&awsiam.ListGroupsForUserOutput{
+		Groups: []*awsiam.Group{{GroupName: awssdk.String(ReferenceExistingGroupName)}},
+	}, true)
+	return nil
+}
+
+func (m *mockIAMClient) CreateAccessKeyWithContext(_ awssdk.Context, input *awsiam.CreateAccessKeyInput, _ ...request.Option) (*awsiam.CreateAccessKeyOutput, error) {
 	return createMockCreateAccessKeyOutput(input), nil
 }
 
-func (m *mockIAMClient) ListAccessKeys(input *awsiam.ListAccessKeysInput) (*awsiam.ListAccessKeysOutput, error) {
+func (m *mockIAMClient) ListAccessKeysWithContext(_ awssdk.Context, input *awsiam.ListAccessKeysInput, _ ...request.Option) (*awsiam.ListAccessKeysOutput, error) {
 	return &awsiam.ListAccessKeysOutput{
 		AccessKeyMetadata: []*awsiam.AccessKeyMetadata{
 			{
@@ -80,18 +99,18 @@ func (m *mockIAMClient) ListAccessKeys(input *awsiam.ListAccessKeysInput) (*awsi
 	}, nil
 }
 
-func (m *mockIAMClient) DeleteAccessKey(input *awsiam.DeleteAccessKeyInput) (*awsiam.DeleteAccessKeyOutput, error) {
+func (m *mockIAMClient) DeleteAccessKeyWithContext(_ awssdk.Context, input *awsiam.DeleteAccessKeyInput, _ ...request.Option) (*awsiam.DeleteAccessKeyOutput, error) {
 	assert.Equal(m.t, ReferenceExistingUserName, *input.UserName)
 	assert.Equal(m.t, ReferenceAccessKeyId, *input.AccessKeyId)
 	return &awsiam.DeleteAccessKeyOutput{}, nil
 }
 
-func (m *mockIAMClient) CreateLoginProfile(input *awsiam.CreateLoginProfileInput) (*awsiam.CreateLoginProfileOutput, error) {
+func (m *mockIAMClient) CreateLoginProfileWithContext(_ awssdk.Context, input *awsiam.CreateLoginProfileInput, _ ...request.Option) (*awsiam.CreateLoginProfileOutput, error) {
 	assert.Equal(m.t, false, *input.PasswordResetRequired)
 	return createMockCreateLoginProfileOutput(input), nil
 }
 
-func (m *mockIAMClient) DeleteLoginProfile(input *awsiam.DeleteLoginProfileInput) (*awsiam.DeleteLoginProfileOutput, error) {
+func (m *mockIAMClient) DeleteLoginProfileWithContext(_ awssdk.Context, input *awsiam.DeleteLoginProfileInput, _ ...request.Option) (*awsiam.DeleteLoginProfileOutput, error) {
 	assert.Equal(m.t, ReferenceExistingUserName, *input.UserName)
 	return &awsiam.DeleteLoginProfileOutput{}, nil
 }
@@ -233,10 +252,93 @@ func TestUserInstance_createUser(t *testing.T) {
 	// Setup Test
 	mockSvc := &mockIAMClient{t: t}
 
-	_, err := createUser(mockSvc, "test/name")
+	_, err := createUser(context.Background(), mockSvc, "test/name")
 	assert.Error(t, err)
 }
 
+func TestNewUser(t *testing.T) {
+	usr, err := NewUser(ReferenceUserName, getReferenceSession(t))
+	assert.NoError(t, err)
+	assert.Equal(t, cloudobject.ID("clobjx-usr-"+ReferenceUserName), usr.ID())
+	var _ cloudobject.CloudObject = usr
+
+	_, err = NewUser("", getReferenceSession(t))
+	assert.Error(t, err)
+	_, err = NewUser(strings.Repeat("a", 54), getReferenceSession(t))
+	assert.Error(t, err)
+}
+
+func TestUserSpec_Diff(t *testing.T) {
+	id := UserID(ReferenceUserName)
+	spec := &UserSpec{LoginProfile: true, ProgrammaticAccess: true}
+	assert.True(t, spec.Diff(id, nil).Create)
+
+	diff := spec.Diff(id, &UserStatus{LoginProfile: true})
+	assert.Equal(t, cloudobject.UpdateDiffAction, diff.Action())
+	assert.Equal(t, []cloudobject.Change{{Field: "ProgrammaticAccess", Old: false, New: true}}, diff.Changes)
+
+	diff = spec.Diff(id, &UserStatus{LoginProfile: true, AccessKeyIDs: []string{ReferenceAccessKeyId}})
+	assert.False(t, diff.HasChanges())
+
+	// Groups are given by cloud object name or ARN, but compared by their name at AWS
+	spec.Groups = []string{"arn:aws:iam::123456789012:group/external", ReferenceGroupName}
+	diff = spec.Diff(id, &UserStatus{LoginProfile: true, AccessKeyIDs: []string{ReferenceAccessKeyId},
+		Groups: []string{"external"}})
+	assert.Equal(t, []cloudobject.Change{{Field: "Groups", Old: []string{"external"},
+		New: []string{"clobjx-grp-" + ReferenceGroupName, "external"}}}, diff.Changes)
+}
+
+func TestUserSpec_Valid(t *testing.T) {
+	ok, err := (&UserSpec{Groups: []string{ReferenceGroupName, "arn:aws:iam::123456789012:group/external"}}).Valid()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+
+	ok, err = (&UserSpec{Groups: []string{"arn:aws:iam::123456789012:role/external"}}).Valid()
+	assert.False(t, ok)
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+}
+
+func TestUser_References(t *testing.T) {
+	usr, err := NewUser(ReferenceUserName, getReferenceSession(t))
+	assert.NoError(t, err)
+	spec := &UserSpec{Groups: []string{ReferenceGroupName, "arn:aws:iam::123456789012:group/external"}}
+	assert.Equal(t, []cloudobject.ID{GroupID(ReferenceGroupName)}, usr.References(spec))
+}
+
+func TestUserSecrets_Map(t *testing.T) {
+	var secrets *UserSecrets
+	assert.Empty(t, secrets.Map())
+
+	secrets = &UserSecrets{
+		LoginProfile: NewLoginProfileCredentials(ReferenceUserName, "pass"),
+		AccessKey:    NewAccessKey(ReferenceAccessKeyId, ReferenceAccessKeySecret),
+	}
+	assert.Equal(t, map[string]string{
+		"username":        ReferenceUserName,
+		"password":        "pass",
+		"accessKeyId":     ReferenceAccessKeyId,
+		"secretAccessKey": ReferenceAccessKeySecret,
+	}, secrets.Map())
+}
+
+func TestUser_ReadExisting(t *testing.T) {
+	usr, err := NewExistingUser(ReferenceUserName, getReferenceSession(t))
+	assert.NoError(t, err)
+	assert.Equal(t, cloudobject.ID(ReferenceUserName), usr.ID())
+
+	usr.session = &mockIAMClient{t: t}
+	assert.NoError(t, usr.Read())
+	status := usr.Status().(*UserStatus)
+	assert.True(t, status.LoginProfile)
+	assert.Equal(t, []string{ReferenceAccessKeyId}, status.AccessKeyIDs)
+	assert.Equal(t, []string{ReferenceExistingGroupName}, status.Groups)
+
+	usr, err = NewExistingUser(FriendlyNamefromARN(getReferenceUserNonExistingArn()), getReferenceSession(t))
+	assert.NoError(t, err)
+	usr.session = &mockIAMClient{t: t}
+	assert.True(t, cloudobject.IsNotExistsError(usr.Read()))
+}
+
 /////////////
 // HELPERS //
 /////////////
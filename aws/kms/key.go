@@ -1,12 +1,14 @@
 package kms
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
 	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
 
 	"github.com/redradrat/cloud-objects/aws"
 	"github.com/redradrat/cloud-objects/cloudobject"
@@ -14,15 +16,24 @@ import (
 
 const (
 	KMSKeyTopic = "enckey"
+
+	KeyKind cloudobject.Kind = "key"
 )
 
 type Key struct {
 	name    string
 	status  *KeyStatus
-	session *awskms.KMS
+	session kmsiface.KMSAPI
+	// logger is where the Key logs its AWS calls to, if set
+	logger cloudobject.Logger
 }
 
 func NewKey(name string, session client.ConfigProvider) (*Key, error) {
+	return NewKeyWithClient(name, awskms.New(session))
+}
+
+// NewKeyWithClient is the same as NewKey, but uses the given KMS client instead of creating its own
+func NewKeyWithClient(name string, svc kmsiface.KMSAPI) (*Key, error) {
 	if len(name) == 0 {
 		return nil, fmt.Errorf("given name is empty")
 	}
@@ -32,22 +43,28 @@ func NewKey(name string, session client.ConfigProvider) (*Key, error) {
 
 	key := Key{
 		name:    name,
-		session: awskms.New(session),
+		session: svc,
 	}
 
 	return &key, nil
 }
 
 func (k *Key) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return k.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (k *Key) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, k.logger, k.ID(), "create")
 	// It's fair to assume, that we get an KMS KeySpec here.
 	assertedSpec, ok := spec.(*KeySpec)
 	if !ok {
 		// If not, we're throwing an error here... ya done messed up.
-		return nil, cloudobject.SpecInvalidError{Message: "got unsupported spec"}
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
 	}
 
 	// If the KMS Key already exists, we're gonna throw an error here... you're trying to play us for a fool!
-	exists, err := k.Exists()
+	exists, err := k.ExistsWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,20 +74,20 @@ func (k *Key) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, err
 
 	// Now let's go for it... create this Key!
 	input := assertedSpec.CreateKeyInput()
-	out, err := k.session.CreateKey(&input)
+	out, err := k.session.CreateKeyWithContext(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
 
 	// We specifically need to assign an alias to this key!
 	aliasInput := assertedSpec.CreateAliasInput(k.ID().String(), *out.KeyMetadata.KeyId)
-	_, err = k.session.CreateAlias(&aliasInput)
+	_, err = k.session.CreateAliasWithContext(ctx, &aliasInput)
 	if err != nil {
 		return nil, err
 	}
 
-	// re-trigger status update
-	if err = k.Read(); err != nil {
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err = k.ReadWithContext(aws.WithRetryCodes(ctx, awskms.ErrCodeNotFoundException)); err != nil {
 		return nil, err
 	}
 
@@ -78,14 +95,24 @@ func (k *Key) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, err
 }
 
 func (k *Key) Read() error {
+	return k.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context
+func (k *Key) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, k.logger, k.ID(), "read")
 	// Call AWS to describe our KMS Key
-	out, err := k.session.DescribeKey(&awskms.DescribeKeyInput{
+	out, err := k.session.DescribeKeyWithContext(ctx, &awskms.DescribeKeyInput{
 		KeyId: k.ID().StringPtr(),
 	})
 	if err != nil {
-		if err.(awserr.Error).Code() == awskms.ErrCodeNotFoundException {
-			return cloudobject.NotExistsError{Message: fmt.Sprintf("KMS Key with id '%s' not found",
-				k.ID().String())}
+		if aws.IsErrorCode(err, awskms.ErrCodeNotFoundException) {
+			return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("KMS Key with id '%s' not found", k.ID().String()),
+				ID:      k.ID(),
+				Op:      "read",
+				Err:     err,
+			}}
 		}
 		return err
 	}
@@ -95,28 +122,64 @@ func (k *Key) Read() error {
 }
 
 func (k *Key) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return k.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (k *Key) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, k.logger, k.ID(), "update")
 	// It's fair to assume, that we get an KMS KeySpec here.
-	_, ok := spec.(*KeySpec)
+	assertedSpec, ok := spec.(*KeySpec)
 	if !ok {
-		return nil, cloudobject.SpecInvalidError{Message: "got unsupported spec"}
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
 	}
 
 	// Let's update our status
-	if err := k.Read(); err != nil {
+	if err := k.ReadWithContext(ctx); err != nil {
 		return nil, err
 	}
-	// Here we could copy the old status before we read again, and compute a delta
-	_ = k.status
 
-	// As the actual Key really has nothing to modify, we're just good here...
+	// As the actual Key really has nothing to modify, all we can do is tell if somebody wants us to
+	if err := assertedSpec.Diff(k.ID(), k.status).ImmutableChangesError(); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
+// Plan compares the given spec against the live KMS Key, without changing anything
+func (k *Key) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return k.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (k *Key) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, k.logger, k.ID(), "plan")
+	assertedSpec, ok := spec.(*KeySpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := k.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(k.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(k.ID(), k.status), nil
+}
+
 // Delete deletes a key.
 // Use cloudobject.PurgeDeleteOpts as input.
 func (k *Key) Delete(purge bool) error {
+	return k.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (k *Key) DeleteWithContext(ctx context.Context, purge bool) error {
+	ctx = cloudobject.WithOperation(ctx, k.logger, k.ID(), "delete")
 	// First, let's check whether our KMS Key actually exists
-	exists, err := k.Exists()
+	exists, err := k.ExistsWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -131,7 +194,7 @@ func (k *Key) Delete(purge bool) error {
 	input := awskms.DisableKeyInput{
 		KeyId: k.status.KeyId,
 	}
-	if _, err := k.session.DisableKey(&input); err != nil {
+	if _, err := k.session.DisableKeyWithContext(ctx, &input); err != nil {
 		return err
 	}
 
@@ -141,7 +204,7 @@ func (k *Key) Delete(purge bool) error {
 			KeyId:               k.status.KeyId,
 			PendingWindowInDays: awssdk.Int64(7),
 		}
-		if _, err := k.session.ScheduleKeyDeletion(&input); err != nil {
+		if _, err := k.session.ScheduleKeyDeletionWithContext(ctx, &input); err != nil {
 			return err
 		}
 	}
@@ -150,7 +213,8 @@ func (k *Key) Delete(purge bool) error {
 	aliasInput := awskms.DeleteAliasInput{
 		AliasName: k.ID().StringPtr(),
 	}
-	if _, err := k.session.DeleteAlias(&aliasInput); cloudobject.IgnoreNotExistsError(err) != nil {
+	if _, err := k.session.DeleteAliasWithContext(ctx, &aliasInput); err != nil &&
+		!aws.IsErrorCode(err, awskms.ErrCodeNotFoundException) {
 		return err
 	}
 
@@ -162,13 +226,27 @@ func (k *Key) Status() cloudobject.Status {
 }
 
 func (k *Key) ID() cloudobject.ID {
-	return cloudobject.ID(fmt.Sprintf("%s/%s", "alias", aws.CloudObjectResource(KMSKeyTopic, k.name)))
+	return KeyID(k.name)
+}
+
+// KeyID returns the ID of the Key with the given name, e.g. to reference it from other cloud objects
+func KeyID(name string) cloudobject.ID {
+	return cloudobject.ID(fmt.Sprintf("%s/%s", "alias", aws.CloudObjectResource(KMSKeyTopic, name)))
 }
 
 func (k *Key) Exists() (bool, error) {
 	return cloudobject.Exists(k)
 }
 
+func (k *Key) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, k)
+}
+
+// SetLogger makes the Key log its AWS calls to the given logger
+func (k *Key) SetLogger(logger cloudobject.Logger) {
+	k.logger = logger
+}
+
 //////////////
 /// SECRET ///
 //////////////
@@ -186,6 +264,9 @@ func (secrets *KeySecrets) Map() map[string]string {
 type KeyStatus awskms.KeyMetadata
 
 func (status *KeyStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
 	out := awskms.KeyMetadata(*status).Arn
 	return cloudobject.ProviderID{
 		Type:  cloudobject.AWSProvider,
@@ -193,7 +274,62 @@ func (status *KeyStatus) ProviderID() cloudobject.ProviderID {
 	}
 }
 
+func (status *KeyStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return awssdk.StringValue(status.KeyState)
+}
+
+// KeyDetails are the key facts of a KMS Key, as given in its cloudobject.Summary
+type KeyDetails struct {
+	KeyID        string     `json:"keyID" yaml:"keyID"`
+	KeyUsage     string     `json:"keyUsage" yaml:"keyUsage"`
+	KeyType      string     `json:"keyType" yaml:"keyType"`
+	Enabled      bool       `json:"enabled" yaml:"enabled"`
+	DeletionDate *time.Time `json:"deletionDate,omitempty" yaml:"deletionDate,omitempty"`
+}
+
+func (status *KeyStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	return KeyDetails{
+		KeyID:        awssdk.StringValue(status.KeyId),
+		KeyUsage:     awssdk.StringValue(status.KeyUsage),
+		KeyType:      awssdk.StringValue(status.CustomerMasterKeySpec),
+		Enabled:      awssdk.BoolValue(status.Enabled),
+		DeletionDate: status.DeletionDate,
+	}
+}
+
+// KeyEnabled is a cloudobject.ReadyFunc for KMS Keys that can be used for cryptographic operations
+func KeyEnabled(status cloudobject.Status) (bool, error) {
+	keyStatus, ok := status.(*KeyStatus)
+	if !ok {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported status"}}
+	}
+	if keyStatus.State() == awskms.KeyStatePendingDeletion {
+		return false, cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "KMS Key is pending deletion and won't become enabled",
+		}}
+	}
+	return keyStatus.State() == awskms.KeyStateEnabled, nil
+}
+
+// KeyPendingDeletion is a cloudobject.ReadyFunc for KMS Keys that are scheduled for deletion
+func KeyPendingDeletion(status cloudobject.Status) (bool, error) {
+	keyStatus, ok := status.(*KeyStatus)
+	if !ok {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported status"}}
+	}
+	return keyStatus.State() == awskms.KeyStatePendingDeletion, nil
+}
+
 func (status *KeyStatus) String() string {
+	if status == nil {
+		return ""
+	}
 	out := awskms.KeyMetadata(*status).String()
 	return out
 }
@@ -213,6 +349,17 @@ func (spec *KeySpec) Valid() (bool, error) {
 	return true, nil
 }
 
+// Diff compares the spec against the given live status. Neither usage nor type of a Key can be changed.
+func (spec *KeySpec) Diff(id cloudobject.ID, status *KeyStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+	diff.CompareImmutable("KeyUsage", awssdk.StringValue(status.KeyUsage), spec.KeyUsage.String())
+	diff.CompareImmutable("KeyType", awssdk.StringValue(status.CustomerMasterKeySpec), spec.KeyType.String())
+	return diff
+}
+
 func (spec *KeySpec) CreateKeyInput() awskms.CreateKeyInput {
 	tags := compileTags(spec.Tags)
 
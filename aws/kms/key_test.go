@@ -0,0 +1,104 @@
+package kms
+
+import (
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/aws/fake"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+func TestKey_Lifecycle(t *testing.T) {
+	backend := fake.NewBackend()
+	key, err := NewKey("mykey", backend.Session())
+	require.NoError(t, err)
+	spec := &KeySpec{KeyUsage: EncryptDecryptKeyUsage, KeyType: SymmetricDefaultKeyType}
+
+	assert.True(t, cloudobject.IsNotExistsError(key.Read()))
+	_, err = key.Create(spec)
+	require.NoError(t, err)
+	ready, err := KeyEnabled(key.Status())
+	assert.NoError(t, err)
+	assert.True(t, ready)
+	diff, err := key.Plan(spec)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges())
+
+	// Neither usage nor type can be changed
+	_, err = key.Update(&KeySpec{KeyUsage: SignVerifyKeyUsage, KeyType: SymmetricDefaultKeyType})
+	assert.Error(t, err)
+
+	// Without purge, the Key is only disabled, but it loses its alias all the same
+	keyID := key.Status().ProviderID()
+	require.NoError(t, key.Delete(false))
+	exists, err := key.Exists()
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// ...so a new Key can take over the name
+	_, err = key.Create(spec)
+	require.NoError(t, err)
+	assert.NotEqual(t, keyID, key.Status().ProviderID())
+
+	require.NoError(t, key.Delete(true))
+	exists, err = key.Exists()
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+type mockKMSClient struct {
+	kmsiface.KMSAPI
+	t *testing.T
+}
+
+func (m *mockKMSClient) DescribeKeyWithContext(_ awssdk.Context, input *awskms.DescribeKeyInput, _ ...request.Option) (*awskms.DescribeKeyOutput, error) {
+	assert.Equal(m.t, "alias/clobjx-enckey-mykey", awssdk.StringValue(input.KeyId))
+	return &awskms.DescribeKeyOutput{KeyMetadata: &awskms.KeyMetadata{
+		Arn:      awssdk.String("arn:aws:kms:us-east-1:123456789012:key/mock"),
+		KeyId:    awssdk.String("mock"),
+		KeyState: awssdk.String(awskms.KeyStateEnabled),
+	}}, nil
+}
+
+func TestNewKeyWithClient(t *testing.T) {
+	_, err := NewKeyWithClient("", &mockKMSClient{t: t})
+	assert.Error(t, err)
+
+	key, err := NewKeyWithClient("mykey", &mockKMSClient{t: t})
+	require.NoError(t, err)
+	require.NoError(t, key.Read())
+	assert.Equal(t, "arn:aws:kms:us-east-1:123456789012:key/mock", key.Status().ProviderID().Value)
+	ready, err := KeyEnabled(key.Status())
+	assert.NoError(t, err)
+	assert.True(t, ready)
+}
+
+func TestKey_Logger(t *testing.T) {
+	backend := fake.NewBackend()
+	key, err := NewKey("mykey", backend.Session())
+	require.NoError(t, err)
+	logger := &fake.Logger{}
+	key.SetLogger(logger)
+
+	_, err = key.Create(&KeySpec{KeyUsage: EncryptDecryptKeyUsage, KeyType: SymmetricDefaultKeyType})
+	require.NoError(t, err)
+
+	var calls []string
+	for _, entry := range logger.Entries() {
+		assert.Equal(t, "debug", entry.Level)
+		assert.Equal(t, "aws call", entry.Message)
+		assert.Equal(t, key.ID().String(), entry.Attrs["id"])
+		// The reads done while creating are part of the creation
+		assert.Equal(t, "create", entry.Attrs["operation"])
+		assert.Contains(t, entry.Attrs, "duration")
+		calls = append(calls, entry.Attrs["call"].(string))
+	}
+	assert.Equal(t, []string{"kms:DescribeKey", "kms:CreateKey", "kms:CreateAlias", "kms:DescribeKey"}, calls)
+	assert.Contains(t, logger.Entries()[0].Attrs, "error")
+}
@@ -0,0 +1,36 @@
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+// LogHandler logs every AWS call a CloudObject makes to the object's logger (see cloudobject.Loggable), along with
+// the object's ID, its operation, the duration of the call (including retries) and its error, if any. NewSession
+// installs it in all sessions it returns. For other sessions or clients, add it to their Complete handlers:
+//
+//	sess.Handlers.Complete.PushBackNamed(aws.LogHandler)
+//
+// Calls are logged at debug level, failed ones included, as many of them are expected (e.g. reads of objects that
+// don't exist yet).
+var LogHandler = request.NamedHandler{Name: "cloudobjects.LogHandler", Fn: logRequest}
+
+func logRequest(r *request.Request) {
+	op, ok := cloudobject.OperationFromContext(r.Context())
+	if !ok || op.Logger == nil {
+		return
+	}
+	args := append(operationArgs(op, r), "duration", time.Since(r.Time), "retries", r.RetryCount)
+	if r.Error != nil {
+		args = append(args, "error", r.Error.Error())
+	}
+	op.Logger.DebugContext(r.Context(), "aws call", args...)
+}
+
+// operationArgs returns the attributes identifying the AWS call of the given operation
+func operationArgs(op cloudobject.Operation, r *request.Request) []interface{} {
+	return []interface{}{"id", op.ID.String(), "operation", op.Name, "call", operationKey(r)}
+}
@@ -0,0 +1,983 @@
+package rds
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/client"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	awsrds "github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/aws/kms"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const (
+	DBClusterTopic = "dbc"
+
+	ClusterKind cloudobject.Kind = "cluster"
+)
+
+// Cluster represents the RDS Aurora Cluster CloudObject: a DB Cluster along with its writer instance and any
+// number of reader instances. Like an Instance, it is encrypted with a KMS Key of its own, leaves a final snapshot
+// on delete and is restored from it on create.
+type Cluster struct {
+	name    string
+	status  *ClusterStatus
+	session rdsiface.RDSAPI
+	// kms is the client for the Key the Cluster is encrypted with
+	kms kmsiface.KMSAPI
+	// logger is where the Cluster logs its AWS calls to, if set
+	logger cloudobject.Logger
+	// password is the master password the Cluster was last created or updated with, as AWS won't hand it out
+	password string
+}
+
+// NewCluster returns a new RDS Aurora cluster object
+func NewCluster(name string, session client.ConfigProvider) (*Cluster, error) {
+	return NewClusterWithClients(name, awsrds.New(session), awskms.New(session))
+}
+
+// NewClusterWithClients is the same as NewCluster, but uses the given RDS and KMS clients instead of creating its
+// own
+func NewClusterWithClients(name string, svc rdsiface.RDSAPI, kmsSvc kmsiface.KMSAPI) (*Cluster, error) {
+	if len(name) == 0 {
+		return nil, fmt.Errorf("given name is empty")
+	}
+	// Identifiers are limited to 63 characters, and the ones of the Cluster's instances add a suffix
+	if len(name) > 48 {
+		return nil, fmt.Errorf("given name is longer than 48 characters")
+	}
+
+	cluster := Cluster{
+		name:    name,
+		session: svc,
+		kms:     kmsSvc,
+	}
+
+	return &cluster, nil
+}
+
+// Get the CloudObjectId for our Cluster. Equals to Cluster Name. This is not the AWS Id.
+func (c *Cluster) ID() cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(DBClusterTopic, c.name))
+}
+
+// Create creates the Cluster and its instances, or restores it from its final snapshot if that and its Key exist
+func (c *Cluster) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return c.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (c *Cluster) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, c.logger, c.ID(), "create")
+	assertedSpec, ok := spec.(*ClusterSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+	// The password of the spec is only handed out if it was applied, which it isn't for an existing Cluster
+	exists, err := c.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return c.Secrets(), nil
+	}
+
+	snapshotFound, err := clusterSnapshotExists(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	key, err := c.key()
+	if err != nil {
+		return nil, err
+	}
+	keyFound, err := key.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyFound && snapshotFound {
+		if assertedSpec.RestorationDisabled {
+			return nil, RestorationDisabledError{Message: fmt.Sprintf("creation without restoration triggered, "+
+				"but key and snapshot exist for RDS Cluster '%s'", c.ID().String())}
+		}
+		// The restored Cluster keeps the master password of the snapshot, which we can't tell
+		c.password = ""
+		input := assertedSpec.RestoreDBClusterFromSnapshotInput(c.ID().String(), finalDBClusterSnapshotName(c))
+		if _, err := c.session.RestoreDBClusterFromSnapshotWithContext(ctx, &input); err != nil {
+			return nil, err
+		}
+	} else {
+		if snapshotFound {
+			return nil, cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("RDS cluster snaphshot with id '%s' already exists, but no key found",
+					finalDBClusterSnapshotName(c)),
+			}}
+		}
+		if !keyFound {
+			_, err := key.CreateWithContext(ctx, &kms.KeySpec{
+				KeyUsage: kms.EncryptDecryptKeyUsage,
+				KeyType:  kms.SymmetricDefaultKeyType,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		// Generated passwords aren't known until we generate them
+		pass := assertedSpec.MasterUserPassword
+		if pass == "" && assertedSpec.GenerateMasterUserPassword {
+			if pass, err = GeneratePassword(); err != nil {
+				return nil, err
+			}
+		}
+
+		input := assertedSpec.CreateDBClusterInput(c.ID().String())
+		input.KmsKeyId = key.ID().StringPtr()
+		input.MasterUserPassword = awssdk.String(pass)
+		if _, err := c.session.CreateDBClusterWithContext(ctx, &input); err != nil {
+			return nil, err
+		}
+		c.password = pass
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := c.ReadWithContext(aws.WithRetryCodes(ctx, awsrds.ErrCodeDBClusterNotFoundFault)); err != nil {
+		return nil, err
+	}
+	if err := c.scaleInstances(ctx, assertedSpec); err != nil {
+		return nil, err
+	}
+	if err := c.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.Secrets(), nil
+}
+
+// key returns the Key the Cluster is encrypted with. Its name sets it apart from the Key of an Instance of the same
+// name.
+func (c *Cluster) key() (*kms.Key, error) {
+	return kms.NewKeyWithClient(clusterKeyName(c.name), c.kms)
+}
+
+func clusterKeyName(name string) string {
+	return DBClusterTopic + "-" + name
+}
+
+func (c *Cluster) Read() error {
+	return c.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context. It reads the instances of
+// the Cluster as well.
+func (c *Cluster) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, c.logger, c.ID(), "read")
+	out, err := c.session.DescribeDBClustersWithContext(ctx, &awsrds.DescribeDBClustersInput{
+		DBClusterIdentifier: c.ID().StringPtr(),
+	})
+	if err != nil {
+		if aws.IsErrorCode(err, awsrds.ErrCodeDBClusterNotFoundFault) {
+			return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("RDS DB Cluster with id '%s' not found", c.ID().String()),
+				ID:      c.ID(),
+				Op:      "read",
+				Err:     err,
+			}}
+		}
+		return err
+	}
+	if len(out.DBClusters) == 0 {
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("RDS DB Cluster with id '%s' not found", c.ID().String()),
+			ID:      c.ID(),
+			Op:      "read",
+		}}
+	}
+	if len(out.DBClusters) > 1 {
+		return cloudobject.AmbiguousIdentifierError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("multiple RDS DB Clusters with id '%s' found", c.ID().String()),
+			ID:      c.ID(),
+			Op:      "read",
+		}}
+	}
+
+	status := &ClusterStatus{Cluster: out.DBClusters[0]}
+	for _, member := range status.Cluster.DBClusterMembers {
+		ins, err := c.session.DescribeDBInstancesWithContext(ctx, &awsrds.DescribeDBInstancesInput{
+			DBInstanceIdentifier: member.DBInstanceIdentifier,
+		})
+		if err != nil {
+			// Instances that are being deleted may be gone already
+			if aws.IsErrorCode(err, awsrds.ErrCodeDBInstanceNotFoundFault) {
+				continue
+			}
+			return err
+		}
+		status.Instances = append(status.Instances, ins.DBInstances...)
+	}
+	status.sortInstances()
+	c.status = status
+
+	return nil
+}
+
+func (c *Cluster) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return c.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (c *Cluster) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, c.logger, c.ID(), "update")
+	assertedSpec, ok := spec.(*ClusterSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+	if err := c.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// The master password can't be read back, so it doesn't show up in the diff; a given one is always set, like for
+	// Instances
+	diff := assertedSpec.Diff(c.ID(), c.status)
+	if err := diff.ImmutableChangesError(); err != nil {
+		return nil, err
+	}
+	if !diff.HasChanges() && assertedSpec.MasterUserPassword == "" {
+		return c.Secrets(), nil
+	}
+
+	if diff.Changed(clusterFields...) || assertedSpec.MasterUserPassword != "" {
+		input := assertedSpec.ModifyDBClusterInput(c.ID().String())
+		if _, err := c.session.ModifyDBClusterWithContext(ctx, &input); err != nil {
+			return nil, err
+		}
+		// Only hand out a password the Cluster was actually given
+		if assertedSpec.MasterUserPassword != "" {
+			c.password = assertedSpec.MasterUserPassword
+		}
+	}
+	for _, ins := range c.status.Instances {
+		if awssdk.StringValue(ins.DBInstanceClass) == assertedSpec.DBInstanceClass &&
+			awssdk.BoolValue(ins.PubliclyAccessible) == assertedSpec.PubliclyAccessible {
+			continue
+		}
+		_, err := c.session.ModifyDBInstanceWithContext(ctx, &awsrds.ModifyDBInstanceInput{
+			ApplyImmediately:     awssdk.Bool(true),
+			DBInstanceClass:      awssdk.String(assertedSpec.DBInstanceClass),
+			DBInstanceIdentifier: ins.DBInstanceIdentifier,
+			PubliclyAccessible:   awssdk.Bool(assertedSpec.PubliclyAccessible),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := c.scaleInstances(ctx, assertedSpec); err != nil {
+		return nil, err
+	}
+	if err := c.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.Secrets(), nil
+}
+
+// scaleInstances creates or deletes instances of the read Cluster until it has a writer and as many readers as the
+// spec asks for. Readers are deleted newest first; the writer is never deleted.
+func (c *Cluster) scaleInstances(ctx context.Context, spec *ClusterSpec) error {
+	instances := c.status.Instances
+	want := int(1 + spec.Readers)
+
+	for n := 1; len(instances) < want; n++ {
+		id := clusterInstanceID(c.ID(), n)
+		if c.status.instance(id) != nil {
+			continue
+		}
+		input := spec.CreateDBInstanceInput(c.ID().String(), id)
+		out, err := c.session.CreateDBInstanceWithContext(ctx, &input)
+		if err != nil {
+			return err
+		}
+		instances = append(instances, out.DBInstance)
+	}
+
+	for i := len(instances) - 1; len(instances) > want && i > 0; i-- {
+		_, err := c.session.DeleteDBInstanceWithContext(ctx, &awsrds.DeleteDBInstanceInput{
+			DBInstanceIdentifier: instances[i].DBInstanceIdentifier,
+			SkipFinalSnapshot:    awssdk.Bool(true),
+		})
+		if err != nil && !aws.IsErrorCode(err, awsrds.ErrCodeDBInstanceNotFoundFault) {
+			return err
+		}
+		instances = append(instances[:i], instances[i+1:]...)
+	}
+	return nil
+}
+
+// clusterInstanceID returns the identifier of the n-th instance of the Cluster with the given ID
+func clusterInstanceID(id cloudobject.ID, n int) string {
+	return fmt.Sprintf("%s-%d", id.String(), n)
+}
+
+// Plan compares the given spec against the live RDS Cluster, without changing anything
+func (c *Cluster) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return c.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (c *Cluster) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, c.logger, c.ID(), "plan")
+	assertedSpec, ok := spec.(*ClusterSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := c.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(c.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(c.ID(), c.status), nil
+}
+
+// Delete deletes the Cluster along with its instances, leaving a final snapshot unless purged
+func (c *Cluster) Delete(purge bool) error {
+	return c.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (c *Cluster) DeleteWithContext(ctx context.Context, purge bool) error {
+	ctx = cloudobject.WithOperation(ctx, c.logger, c.ID(), "delete")
+	exists, err := c.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("cannot delete non-existing RDS cluster '%s'", c.ID().String()),
+			ID:      c.ID(),
+			Op:      "delete",
+		}}
+	}
+
+	if c.status.State() == DeletingInstanceState {
+		return nil
+	}
+	// We should only delete clusters that are ready. Their instances may be on their way out already, from an
+	// earlier attempt.
+	if c.status.State() != AvailableInstanceState {
+		return cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("cannot delete not-available RDS cluster '%s'", c.ID().String()),
+			ID:      c.ID(),
+			Op:      "delete",
+		}}
+	}
+
+	// An existing snapshot would keep us from taking the final one. On purge, it would keep us from creating the
+	// Cluster from scratch later on, as its key is gone.
+	snapExists, err := clusterSnapshotExists(ctx, c)
+	if err != nil {
+		return err
+	}
+	if snapExists {
+		_, err := c.session.DeleteDBClusterSnapshotWithContext(ctx, &awsrds.DeleteDBClusterSnapshotInput{
+			DBClusterSnapshotIdentifier: awssdk.String(finalDBClusterSnapshotName(c)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Instances of a Cluster have no snapshots of their own, the final snapshot of the Cluster covers them
+	for _, ins := range c.status.Instances {
+		if awssdk.StringValue(ins.DBInstanceStatus) == DeletingInstanceState {
+			continue
+		}
+		_, err := c.session.DeleteDBInstanceWithContext(ctx, &awsrds.DeleteDBInstanceInput{
+			DBInstanceIdentifier: ins.DBInstanceIdentifier,
+			SkipFinalSnapshot:    awssdk.Bool(true),
+		})
+		if err != nil && !aws.IsErrorCode(err, awsrds.ErrCodeDBInstanceNotFoundFault) {
+			return err
+		}
+	}
+
+	if _, err := c.session.ModifyDBClusterWithContext(ctx, &awsrds.ModifyDBClusterInput{
+		ApplyImmediately:    awssdk.Bool(true),
+		DBClusterIdentifier: c.ID().StringPtr(),
+		DeletionProtection:  awssdk.Bool(false),
+	}); err != nil {
+		return err
+	}
+	input := awsrds.DeleteDBClusterInput{
+		DBClusterIdentifier: c.ID().StringPtr(),
+		SkipFinalSnapshot:   awssdk.Bool(purge),
+	}
+	if !purge {
+		input.FinalDBSnapshotIdentifier = awssdk.String(finalDBClusterSnapshotName(c))
+	}
+	// The Cluster can't be deleted before its instances are gone, which takes a while
+	_, err = c.session.DeleteDBClusterWithContext(aws.WithRetryCodes(ctx, awsrds.ErrCodeInvalidDBClusterStateFault),
+		&input)
+	if err != nil && !aws.IsErrorCode(err, awsrds.ErrCodeDBClusterNotFoundFault) {
+		if aws.IsErrorCode(err, awsrds.ErrCodeInvalidDBClusterStateFault) && len(c.status.Instances) > 0 {
+			return cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("instances of RDS cluster '%s' are still being deleted, delete it again once "+
+					"they're gone", c.ID().String()),
+				ID:  c.ID(),
+				Op:  "delete",
+				Err: err,
+			}}
+		}
+		return err
+	}
+
+	if purge {
+		key, err := c.key()
+		if err != nil {
+			return err
+		}
+		if err := key.DeleteWithContext(ctx, purge); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Cluster) ARN() *awsarn.ARN {
+	if err := c.Read(); err != nil {
+		return nil
+	}
+	arn := aws.MustParse(*c.status.Cluster.DBClusterArn)
+	return &arn
+}
+
+// References returns the IDs of the KMS Key and the DB SubnetGroup the Cluster uses
+func (c *Cluster) References(spec cloudobject.CloudObjectSpec) []cloudobject.ID {
+	refs := []cloudobject.ID{kms.KeyID(clusterKeyName(c.name))}
+	if assertedSpec, ok := spec.(*ClusterSpec); ok && assertedSpec.DBSubnetGroupName != "" {
+		refs = append(refs, cloudobject.ID(assertedSpec.DBSubnetGroupName))
+	}
+	return refs
+}
+
+func (c *Cluster) Exists() (bool, error) {
+	return cloudobject.Exists(c)
+}
+
+func (c *Cluster) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, c)
+}
+
+// Secrets returns the connection details of the Cluster as of its last read, along with the master password it was
+// last created or updated with. The host is the endpoint of the writer; readers are balanced behind readerHost.
+// Returns nil if the Cluster wasn't read yet.
+func (c *Cluster) Secrets() cloudobject.Secrets {
+	if c.status == nil {
+		return nil
+	}
+	cluster := c.status.Cluster
+	return ClusterSecrets{
+		InstanceSecrets: InstanceSecrets{
+			Host:     awssdk.StringValue(cluster.Endpoint),
+			Port:     awssdk.Int64Value(cluster.Port),
+			DBName:   awssdk.StringValue(cluster.DatabaseName),
+			Username: awssdk.StringValue(cluster.MasterUsername),
+			Password: c.password,
+			Engine:   awssdk.StringValue(cluster.Engine),
+		},
+		ReaderHost: awssdk.StringValue(cluster.ReaderEndpoint),
+	}
+}
+
+// SetLogger makes the Cluster log its AWS calls to the given logger
+func (c *Cluster) SetLogger(logger cloudobject.Logger) {
+	c.logger = logger
+}
+
+func (c *Cluster) Status() cloudobject.Status {
+	return c.status
+}
+
+// ClusterSpec is the spec of an Aurora Cluster. Its instances all share DBInstanceClass and PubliclyAccessible.
+type ClusterSpec struct {
+	// The number of days for which automated backups are retained, from 1 to 35
+	BackupRetentionPeriod int64
+
+	// The name of the database created in the Cluster
+	DBName string
+
+	// The compute and memory capacity of the instances of the Cluster, for example, db.r5.large. Not all DB
+	// instance classes are available for Aurora, see DB Instance Class (https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/Concepts.DBInstanceClass.html)
+	// in the Amazon Aurora User Guide.
+	DBInstanceClass string
+
+	// A DB subnet group to associate with the Cluster. It can't be changed later on.
+	DBSubnetGroupName string
+
+	// The name of the Aurora engine of the Cluster
+	Engine ClusterDBEngine
+
+	// The version number of the database engine to use
+	EngineVersion string
+
+	// The password for the master user. The password can include any printable
+	// ASCII character except "/", """, or "@".
+	MasterUserPassword string
+
+	// GenerateMasterUserPassword has a master password generated on creation, if MasterUserPassword is empty. It
+	// is handed out once, in the secrets returned by Create. Updates leave the master password as it is.
+	GenerateMasterUserPassword bool
+
+	// The name for the master user
+	MasterUsername string
+
+	// The port number on which the Cluster accepts connections. Default: the port of the engine.
+	Port int64
+
+	// The daily time range during which automated backups are created, in the format hh24:mi-hh24:mi (UTC)
+	PreferredBackupWindow string
+
+	// The weekly time range during which system maintenance can occur, in the format ddd:hh24:mi-ddd:hh24:mi (UTC)
+	PreferredMaintenanceWindow string
+
+	// Defines whether the instances of the Cluster will have public endpoints attached
+	PubliclyAccessible bool
+
+	// The number of reader instances next to the writer
+	Readers int64
+
+	// If true: throws an error when restoration procedure is auto-detected. (Key & Snapshot detected)
+	RestorationDisabled bool
+
+	// Whether the Cluster is encrypted with its own KMS Key. It can't be changed later on.
+	StorageEncrypted bool
+
+	Tags map[string]string
+
+	// The VPC security groups to associate with the Cluster
+	VpcSecurityGroupIds []string
+}
+
+func (spec *ClusterSpec) Valid() (bool, error) {
+	if spec.Engine != AuroraPostgreSQLClusterDBEngine && spec.Engine != AuroraMySQLClusterDBEngine {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("Engine must be '%s' or '%s'",
+				AuroraPostgreSQLClusterDBEngine, AuroraMySQLClusterDBEngine),
+		}}
+	}
+
+	if spec.DBName == "" {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "DBName in spec is empty"}}
+	}
+
+	if spec.DBInstanceClass == "" {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "DBInstanceClass in spec is empty",
+		}}
+	}
+
+	if spec.MasterUserPassword == "" {
+		if !spec.GenerateMasterUserPassword {
+			return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: "MasterUserPassword in spec is empty, and GenerateMasterUserPassword is not set",
+			}}
+		}
+	} else if err := validPassword(spec.MasterUserPassword); err != nil {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("MasterUserPassword %s", err),
+		}}
+	}
+
+	if len(spec.MasterUsername) > 16 || len(spec.MasterUsername) < 1 {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "MasterUsername must be > 1 && <= 16 characters",
+		}}
+	}
+
+	// Aurora Clusters have up to 15 readers
+	if spec.Readers < 0 || spec.Readers > 15 {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "Readers must be >= 0 && <= 15",
+		}}
+	}
+
+	return true, nil
+}
+
+// clusterFields are the fields of the ClusterSpec diff that ModifyDBCluster takes care of
+var clusterFields = []string{"BackupRetentionPeriod", "EngineVersion", "Port", "PreferredBackupWindow",
+	"PreferredMaintenanceWindow", "VpcSecurityGroupIds"}
+
+// Diff compares the spec field by field against the given live status. Fields that AWS picks itself if left empty
+// (windows, engine version, port, security groups) are only compared if set in the spec.
+func (spec *ClusterSpec) Diff(id cloudobject.ID, status *ClusterStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+	cluster := status.Cluster
+
+	diff.CompareImmutable("Engine", awssdk.StringValue(cluster.Engine), spec.Engine.String())
+	diff.CompareImmutable("DBName", awssdk.StringValue(cluster.DatabaseName), spec.DBName)
+	diff.CompareImmutable("MasterUsername", awssdk.StringValue(cluster.MasterUsername), spec.MasterUsername)
+	diff.CompareImmutable("StorageEncrypted", awssdk.BoolValue(cluster.StorageEncrypted), spec.StorageEncrypted)
+	if spec.DBSubnetGroupName != "" {
+		diff.CompareImmutable("DBSubnetGroupName", awssdk.StringValue(cluster.DBSubnetGroup),
+			spec.DBSubnetGroupName)
+	}
+
+	diff.Compare("BackupRetentionPeriod", awssdk.Int64Value(cluster.BackupRetentionPeriod),
+		spec.BackupRetentionPeriod)
+	if spec.EngineVersion != "" {
+		diff.Compare("EngineVersion", awssdk.StringValue(cluster.EngineVersion), spec.EngineVersion)
+	}
+	if spec.Port != 0 {
+		diff.Compare("Port", awssdk.Int64Value(cluster.Port), spec.Port)
+	}
+	if spec.PreferredBackupWindow != "" {
+		diff.Compare("PreferredBackupWindow", awssdk.StringValue(cluster.PreferredBackupWindow),
+			spec.PreferredBackupWindow)
+	}
+	if spec.PreferredMaintenanceWindow != "" {
+		diff.Compare("PreferredMaintenanceWindow", awssdk.StringValue(cluster.PreferredMaintenanceWindow),
+			spec.PreferredMaintenanceWindow)
+	}
+	if len(spec.VpcSecurityGroupIds) != 0 {
+		var current []string
+		for _, sg := range cluster.VpcSecurityGroups {
+			current = append(current, awssdk.StringValue(sg.VpcSecurityGroupId))
+		}
+		diff.Compare("VpcSecurityGroupIds", sortedStrings(current), sortedStrings(spec.VpcSecurityGroupIds))
+	}
+
+	diff.Compare("Readers", int64(len(status.Instances))-1, spec.Readers)
+	for _, ins := range status.Instances {
+		field := "Instances." + awssdk.StringValue(ins.DBInstanceIdentifier)
+		diff.Compare(field+".DBInstanceClass", awssdk.StringValue(ins.DBInstanceClass), spec.DBInstanceClass)
+		diff.Compare(field+".PubliclyAccessible", awssdk.BoolValue(ins.PubliclyAccessible), spec.PubliclyAccessible)
+	}
+
+	return diff
+}
+
+const (
+	AuroraPostgreSQLClusterDBEngine ClusterDBEngine = "aurora-postgresql"
+	AuroraMySQLClusterDBEngine      ClusterDBEngine = "aurora-mysql"
+)
+
+type ClusterDBEngine string
+
+func (engine ClusterDBEngine) String() string {
+	return string(engine)
+}
+
+///////////////
+/// HELPERS ///
+///////////////
+
+// ClusterStatus is the state of an Aurora Cluster along with the state of its instances, the writer first
+type ClusterStatus struct {
+	Cluster   *awsrds.DBCluster
+	Instances []*awsrds.DBInstance
+}
+
+func (status *ClusterStatus) String() string {
+	if status == nil {
+		return ""
+	}
+	out := status.Cluster.String()
+	for _, ins := range status.Instances {
+		out += "\n" + ins.String()
+	}
+	return out
+}
+
+func (status *ClusterStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return awssdk.StringValue(status.Cluster.Status)
+}
+
+func (status *ClusterStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
+	return cloudobject.ProviderID{
+		Type:  cloudobject.AWSProvider,
+		Value: awssdk.StringValue(status.Cluster.DBClusterArn),
+	}
+}
+
+// Writer returns the writer instance of the Cluster, or nil if it has none (yet)
+func (status *ClusterStatus) Writer() *awsrds.DBInstance {
+	for _, member := range status.Cluster.DBClusterMembers {
+		if awssdk.BoolValue(member.IsClusterWriter) {
+			return status.instance(awssdk.StringValue(member.DBInstanceIdentifier))
+		}
+	}
+	return nil
+}
+
+// instance returns the instance of the Cluster with the given identifier, or nil if there is none
+func (status *ClusterStatus) instance(id string) *awsrds.DBInstance {
+	for _, ins := range status.Instances {
+		if awssdk.StringValue(ins.DBInstanceIdentifier) == id {
+			return ins
+		}
+	}
+	return nil
+}
+
+// sortInstances puts the writer first and the readers in order of their identifiers
+func (status *ClusterStatus) sortInstances() {
+	writer := status.Writer()
+	sort.SliceStable(status.Instances, func(i, j int) bool {
+		a, b := status.Instances[i], status.Instances[j]
+		if (a == writer) != (b == writer) {
+			return a == writer
+		}
+		return clusterInstanceLess(awssdk.StringValue(a.DBInstanceIdentifier),
+			awssdk.StringValue(b.DBInstanceIdentifier))
+	})
+}
+
+// clusterInstanceLess orders instance identifiers like "...-2" before "...-10"
+func clusterInstanceLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// ClusterDetails are the key facts of an RDS Cluster, as given in its cloudobject.Summary
+type ClusterDetails struct {
+	Engine           string   `json:"engine" yaml:"engine"`
+	EngineVersion    string   `json:"engineVersion" yaml:"engineVersion"`
+	Endpoint         string   `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	ReaderEndpoint   string   `json:"readerEndpoint,omitempty" yaml:"readerEndpoint,omitempty"`
+	Port             int64    `json:"port,omitempty" yaml:"port,omitempty"`
+	DBName           string   `json:"dbName,omitempty" yaml:"dbName,omitempty"`
+	MasterUsername   string   `json:"masterUsername" yaml:"masterUsername"`
+	StorageEncrypted bool     `json:"storageEncrypted" yaml:"storageEncrypted"`
+	KMSKeyID         string   `json:"kmsKeyID,omitempty" yaml:"kmsKeyID,omitempty"`
+	SubnetGroup      string   `json:"subnetGroup,omitempty" yaml:"subnetGroup,omitempty"`
+	Writer           string   `json:"writer,omitempty" yaml:"writer,omitempty"`
+	Readers          []string `json:"readers,omitempty" yaml:"readers,omitempty"`
+	InstanceClass    string   `json:"instanceClass,omitempty" yaml:"instanceClass,omitempty"`
+}
+
+func (status *ClusterStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	cluster := status.Cluster
+	details := ClusterDetails{
+		Engine:           awssdk.StringValue(cluster.Engine),
+		EngineVersion:    awssdk.StringValue(cluster.EngineVersion),
+		Endpoint:         awssdk.StringValue(cluster.Endpoint),
+		ReaderEndpoint:   awssdk.StringValue(cluster.ReaderEndpoint),
+		Port:             awssdk.Int64Value(cluster.Port),
+		DBName:           awssdk.StringValue(cluster.DatabaseName),
+		MasterUsername:   awssdk.StringValue(cluster.MasterUsername),
+		StorageEncrypted: awssdk.BoolValue(cluster.StorageEncrypted),
+		KMSKeyID:         awssdk.StringValue(cluster.KmsKeyId),
+		SubnetGroup:      awssdk.StringValue(cluster.DBSubnetGroup),
+	}
+	writer := status.Writer()
+	for _, ins := range status.Instances {
+		if ins == writer {
+			details.Writer = awssdk.StringValue(ins.DBInstanceIdentifier)
+		} else {
+			details.Readers = append(details.Readers, awssdk.StringValue(ins.DBInstanceIdentifier))
+		}
+		details.InstanceClass = awssdk.StringValue(ins.DBInstanceClass)
+	}
+	return details
+}
+
+// failedClusterStates are the states an RDS Cluster won't leave again without manual intervention
+var failedClusterStates = map[string]bool{
+	"failed":                              true,
+	"inaccessible-encryption-credentials": true,
+	"migration-failed":                    true,
+}
+
+// ClusterAvailable is a cloudobject.ReadyFunc for RDS Clusters that are ready to be used: the Cluster and all its
+// instances are available, and one of them is the writer
+func ClusterAvailable(status cloudobject.Status) (bool, error) {
+	clusterStatus, ok := status.(*ClusterStatus)
+	if !ok {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported status"}}
+	}
+	state := clusterStatus.State()
+	if failedClusterStates[state] {
+		return false, cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("RDS DB Cluster is in failed state '%s'", state),
+		}}
+	}
+	for _, ins := range clusterStatus.Instances {
+		insState := awssdk.StringValue(ins.DBInstanceStatus)
+		if failedInstanceStates[insState] {
+			return false, cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("RDS DB Instance '%s' of the cluster is in failed state '%s'",
+					awssdk.StringValue(ins.DBInstanceIdentifier), insState),
+			}}
+		}
+		if insState != AvailableInstanceState {
+			return false, nil
+		}
+	}
+	return state == AvailableInstanceState && clusterStatus.Writer() != nil, nil
+}
+
+// ClusterSecrets are what it takes to connect to an RDS Cluster: the InstanceSecrets of its writer endpoint, along
+// with its reader endpoint
+type ClusterSecrets struct {
+	InstanceSecrets
+	ReaderHost string
+}
+
+// Map returns the InstanceSecrets of the writer endpoint, with "readerHost" and "readerDsn" added
+func (secrets ClusterSecrets) Map() map[string]string {
+	out := secrets.InstanceSecrets.Map()
+	if secrets.ReaderHost != "" {
+		reader := secrets.InstanceSecrets
+		reader.Host = secrets.ReaderHost
+		out["readerHost"] = reader.Host
+		out["readerDsn"] = reader.DSN()
+	}
+	return out
+}
+
+func finalDBClusterSnapshotName(c *Cluster) string {
+	// Cluster snapshots don't share their identifiers with those of instances
+	return aws.CloudObjectResource(PreDeleteDBSnapshotTopic, c.name)
+}
+
+func clusterSnapshotExists(ctx context.Context, c *Cluster) (bool, error) {
+	out, err := c.session.DescribeDBClusterSnapshotsWithContext(ctx, &awsrds.DescribeDBClusterSnapshotsInput{
+		DBClusterSnapshotIdentifier: awssdk.String(finalDBClusterSnapshotName(c)),
+		IncludePublic:               awssdk.Bool(false),
+		IncludeShared:               awssdk.Bool(false),
+	})
+	if err != nil {
+		if aws.IsErrorCode(err, awsrds.ErrCodeDBClusterSnapshotNotFoundFault) {
+			return false, nil
+		}
+		return false, err
+	}
+	if len(out.DBClusterSnapshots) > 1 {
+		return false, cloudobject.AmbiguousIdentifierError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("multiple RDS DB Cluster Snapshots with id '%s' found", finalDBClusterSnapshotName(c)),
+			ID:      c.ID(),
+			Op:      "read",
+		}}
+	}
+	return len(out.DBClusterSnapshots) == 1, nil
+}
+
+///////////////
+/// AWS API ///
+///////////////
+
+// CreateDBClusterInput returns the marshaled AWS Interface object of same name
+func (spec *ClusterSpec) CreateDBClusterInput(id string) awsrds.CreateDBClusterInput {
+	out := awsrds.CreateDBClusterInput{
+		BackupRetentionPeriod: awssdk.Int64(spec.BackupRetentionPeriod),
+		CopyTagsToSnapshot:    awssdk.Bool(true),
+		DBClusterIdentifier:   awssdk.String(id),
+		DatabaseName:          awssdk.String(spec.DBName),
+		DeletionProtection:    awssdk.Bool(true),
+		Engine:                awssdk.String(spec.Engine.String()),
+		// KmsKeyId we'll set on creation... there we have the key creation/discovery logic
+		MasterUserPassword: awssdk.String(spec.MasterUserPassword),
+		MasterUsername:     awssdk.String(spec.MasterUsername),
+		StorageEncrypted:   awssdk.Bool(spec.StorageEncrypted),
+		Tags:               compileTags(spec.Tags),
+	}
+	spec.optionalClusterSettings(&out.DBSubnetGroupName, &out.EngineVersion, &out.Port, &out.PreferredBackupWindow,
+		&out.PreferredMaintenanceWindow, &out.VpcSecurityGroupIds)
+	return out
+}
+
+// RestoreDBClusterFromSnapshotInput returns the marshaled AWS Interface object of same name
+func (spec *ClusterSpec) RestoreDBClusterFromSnapshotInput(id string, snapshotId string) awsrds.
+	RestoreDBClusterFromSnapshotInput {
+	out := awsrds.RestoreDBClusterFromSnapshotInput{
+		CopyTagsToSnapshot:  awssdk.Bool(true),
+		DBClusterIdentifier: awssdk.String(id),
+		DeletionProtection:  awssdk.Bool(true),
+		Engine:              awssdk.String(spec.Engine.String()),
+		SnapshotIdentifier:  awssdk.String(snapshotId),
+		Tags:                compileTags(spec.Tags),
+	}
+	var windows [2]*string
+	spec.optionalClusterSettings(&out.DBSubnetGroupName, &out.EngineVersion, &out.Port, &windows[0], &windows[1],
+		&out.VpcSecurityGroupIds)
+	return out
+}
+
+// ModifyDBClusterInput returns the marshaled AWS Interface object of same name
+func (spec *ClusterSpec) ModifyDBClusterInput(id string) awsrds.ModifyDBClusterInput {
+	out := awsrds.ModifyDBClusterInput{
+		ApplyImmediately:      awssdk.Bool(true),
+		BackupRetentionPeriod: awssdk.Int64(spec.BackupRetentionPeriod),
+		DBClusterIdentifier:   awssdk.String(id),
+	}
+	var subnetGroup *string
+	spec.optionalClusterSettings(&subnetGroup, &out.EngineVersion, &out.Port, &out.PreferredBackupWindow,
+		&out.PreferredMaintenanceWindow, &out.VpcSecurityGroupIds)
+	// Generated passwords are left as they are
+	if spec.MasterUserPassword != "" {
+		out.MasterUserPassword = awssdk.String(spec.MasterUserPassword)
+	}
+	return out
+}
+
+// optionalClusterSettings sets the settings AWS picks itself if they're left empty in the spec
+func (spec *ClusterSpec) optionalClusterSettings(subnetGroup, engineVersion **string, port **int64, backupWindow,
+	maintenanceWindow **string, securityGroups *[]*string) {
+	if spec.DBSubnetGroupName != "" {
+		*subnetGroup = awssdk.String(spec.DBSubnetGroupName)
+	}
+	if spec.EngineVersion != "" {
+		*engineVersion = awssdk.String(spec.EngineVersion)
+	}
+	if spec.Port != 0 {
+		*port = awssdk.Int64(spec.Port)
+	}
+	if spec.PreferredBackupWindow != "" {
+		*backupWindow = awssdk.String(spec.PreferredBackupWindow)
+	}
+	if spec.PreferredMaintenanceWindow != "" {
+		*maintenanceWindow = awssdk.String(spec.PreferredMaintenanceWindow)
+	}
+	if len(spec.VpcSecurityGroupIds) != 0 {
+		*securityGroups = awssdk.StringSlice(spec.VpcSecurityGroupIds)
+	}
+}
+
+// CreateDBInstanceInput returns the marshaled AWS Interface object of same name, for an instance of the Cluster with
+// the given ID. Storage, credentials and networking are the Cluster's.
+func (spec *ClusterSpec) CreateDBInstanceInput(clusterId, id string) awsrds.CreateDBInstanceInput {
+	return awsrds.CreateDBInstanceInput{
+		AutoMinorVersionUpgrade: awssdk.Bool(true),
+		DBClusterIdentifier:     awssdk.String(clusterId),
+		DBInstanceClass:         awssdk.String(spec.DBInstanceClass),
+		DBInstanceIdentifier:    awssdk.String(id),
+		Engine:                  awssdk.String(spec.Engine.String()),
+		PubliclyAccessible:      awssdk.Bool(spec.PubliclyAccessible),
+		Tags:                    compileTags(spec.Tags),
+	}
+}
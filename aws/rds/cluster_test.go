@@ -0,0 +1,257 @@
+package rds
+
+import (
+	"context"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/aws/fake"
+	"github.com/redradrat/cloud-objects/aws/kms"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+func newTestCluster(t *testing.T, backend *fake.Backend) (*Cluster, *ClusterSpec) {
+	sg, err := NewSubnetGroup("mydb", backend.Session())
+	require.NoError(t, err)
+	_, err = sg.Create(&SubnetGroupSpec{Description: "mydb", SubnetIDs: []string{"subnet-1", "subnet-2"}})
+	require.NoError(t, err)
+
+	cluster, err := NewCluster("mydb", backend.Session())
+	require.NoError(t, err)
+	spec := SaneAuroraPostgres("mydb", "mydb", "db.r5.large", "master", "secretpassword", 1, nil,
+		[]string{"sg-1"})
+	return cluster, &spec
+}
+
+func TestCluster_Create(t *testing.T) {
+	backend := fake.NewBackend()
+	cluster, spec := newTestCluster(t, backend)
+
+	_, err := cluster.Create(spec)
+	require.NoError(t, err)
+	ready, err := ClusterAvailable(cluster.Status())
+	assert.NoError(t, err)
+	assert.True(t, ready)
+
+	// The Cluster is encrypted with its own Key, apart from that of an Instance of the same name
+	key, err := kms.NewKey("dbc-mydb", backend.Session())
+	require.NoError(t, err)
+	require.NoError(t, key.Read())
+	assert.Equal(t, key.Status().ProviderID().Value, awssdk.StringValue(cluster.status.Cluster.KmsKeyId))
+	exists, err := kms.NewKey("mydb", backend.Session())
+	require.NoError(t, err)
+	found, err := exists.Exists()
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// ...and has a writer and a reader
+	details := cloudobject.Summarize(ClusterKind, cluster).Details.(ClusterDetails)
+	assert.Equal(t, "clobjx-dbc-mydb-1", details.Writer)
+	assert.Equal(t, []string{"clobjx-dbc-mydb-2"}, details.Readers)
+	assert.Equal(t, "db.r5.large", details.InstanceClass)
+	assert.True(t, details.StorageEncrypted)
+	assert.Equal(t, "clobjx-sg-mydb", details.SubnetGroup)
+	assert.NotEmpty(t, details.ReaderEndpoint)
+
+	diff, err := cluster.Plan(spec)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges(), diff.String())
+
+	// Creating it again changes nothing
+	_, err = cluster.Create(spec)
+	assert.NoError(t, err)
+	assert.Len(t, cluster.status.Instances, 2)
+
+	// ...so a fresh object doesn't hand out the password of the spec either
+	again, err := NewCluster("mydb", backend.Session())
+	require.NoError(t, err)
+	spec.MasterUserPassword = "othersecretpassword"
+	secrets, err := again.Create(spec)
+	require.NoError(t, err)
+	assert.Empty(t, secrets.Map()["password"])
+	password, err := backend.MasterUserPassword(cluster.ID().String())
+	require.NoError(t, err)
+	assert.Equal(t, "secretpassword", password)
+}
+
+func TestCluster_Update(t *testing.T) {
+	backend := fake.NewBackend()
+	cluster, spec := newTestCluster(t, backend)
+	_, err := cluster.Create(spec)
+	require.NoError(t, err)
+
+	// Scaling out adds readers next to the writer
+	spec.Readers = 3
+	spec.DBInstanceClass = "db.r5.xlarge"
+	spec.BackupRetentionPeriod = 7
+	_, err = cluster.Update(spec)
+	require.NoError(t, err)
+	diff, err := cluster.Plan(spec)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges(), diff.String())
+	details := cluster.Status().(*ClusterStatus).Details().(ClusterDetails)
+	assert.Equal(t, "clobjx-dbc-mydb-1", details.Writer)
+	assert.Equal(t, []string{"clobjx-dbc-mydb-2", "clobjx-dbc-mydb-3", "clobjx-dbc-mydb-4"}, details.Readers)
+	assert.Equal(t, "db.r5.xlarge", details.InstanceClass)
+
+	// Scaling in removes the newest readers, but never the writer
+	spec.Readers = 0
+	_, err = cluster.Update(spec)
+	require.NoError(t, err)
+	details = cluster.Status().(*ClusterStatus).Details().(ClusterDetails)
+	assert.Equal(t, "clobjx-dbc-mydb-1", details.Writer)
+	assert.Empty(t, details.Readers)
+
+	spec.MasterUserPassword = "newsecretpassword"
+	spec.Readers = 1
+	_, err = cluster.Update(spec)
+	require.NoError(t, err)
+	password, err := backend.MasterUserPassword(cluster.ID().String())
+	require.NoError(t, err)
+	assert.Equal(t, "newsecretpassword", password)
+
+	// A new password is set even if nothing else changed
+	spec.MasterUserPassword = "othersecretpassword"
+	secrets, err := cluster.Update(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "othersecretpassword", secrets.Map()["password"])
+	password, err = backend.MasterUserPassword(cluster.ID().String())
+	require.NoError(t, err)
+	assert.Equal(t, "othersecretpassword", password)
+
+	spec.Engine = AuroraMySQLClusterDBEngine
+	_, err = cluster.Update(spec)
+	assert.Error(t, err)
+}
+
+func TestCluster_Secrets(t *testing.T) {
+	backend := fake.NewBackend()
+	cluster, spec := newTestCluster(t, backend)
+	assert.Nil(t, cluster.Secrets())
+
+	spec.MasterUserPassword = ""
+	spec.GenerateMasterUserPassword = true
+	secrets, err := cluster.Create(spec)
+	require.NoError(t, err)
+	password, err := backend.MasterUserPassword(cluster.ID().String())
+	require.NoError(t, err)
+	assert.Len(t, password, GeneratedPasswordLength)
+
+	m := secrets.Map()
+	assert.Equal(t, awssdk.StringValue(cluster.status.Cluster.Endpoint), m["host"])
+	assert.Equal(t, awssdk.StringValue(cluster.status.Cluster.ReaderEndpoint), m["readerHost"])
+	assert.NotEqual(t, m["host"], m["readerHost"])
+	assert.Equal(t, "5432", m["port"])
+	assert.Equal(t, password, m["password"])
+	assert.Equal(t, "aurora-postgresql", m["engine"])
+	assert.Contains(t, m["dsn"], "postgres://master:")
+	assert.Contains(t, m["readerDsn"], "@"+m["readerHost"]+":5432/mydb?sslmode=require")
+}
+
+func TestCluster_DeleteAndRestore(t *testing.T) {
+	backend := fake.NewBackend()
+	cluster, spec := newTestCluster(t, backend)
+	_, err := cluster.Create(spec)
+	require.NoError(t, err)
+
+	// Deleting takes the instances along, and keeps a final snapshot and the Key around
+	require.NoError(t, cluster.Delete(false))
+	exists, err := cluster.Exists()
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = clusterSnapshotExists(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// ...so creating it again restores it
+	_, err = cluster.Create(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "mydb", awssdk.StringValue(cluster.status.Cluster.DatabaseName))
+	assert.Len(t, cluster.status.Instances, 2)
+	password, err := backend.MasterUserPassword(cluster.ID().String())
+	require.NoError(t, err)
+	assert.Equal(t, "secretpassword", password)
+	assert.NotContains(t, cluster.Secrets().Map(), "password")
+
+	// Deleting it again replaces the old snapshot
+	require.NoError(t, cluster.Delete(false))
+
+	// ...unless restoration is disabled
+	spec.RestorationDisabled = true
+	_, err = cluster.Create(spec)
+	assert.IsType(t, RestorationDisabledError{}, err)
+}
+
+func TestCluster_DeletePurge(t *testing.T) {
+	backend := fake.NewBackend()
+	cluster, spec := newTestCluster(t, backend)
+	_, err := cluster.Create(spec)
+	require.NoError(t, err)
+	require.NoError(t, cluster.Delete(false))
+	_, err = cluster.Create(spec)
+	require.NoError(t, err)
+
+	// Purging leaves neither snapshot nor Key behind
+	require.NoError(t, cluster.Delete(true))
+	exists, err := clusterSnapshotExists(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	key, err := kms.NewKey("dbc-mydb", backend.Session())
+	require.NoError(t, err)
+	exists, err = key.Exists()
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// ...so creating it again starts from scratch
+	spec.RestorationDisabled = true
+	_, err = cluster.Create(spec)
+	assert.NoError(t, err)
+
+	// Once the Cluster is gone, so can be its SubnetGroup
+	sg, err := NewSubnetGroup("mydb", backend.Session())
+	require.NoError(t, err)
+	assert.Error(t, sg.Delete(true))
+	require.NoError(t, cluster.Delete(true))
+	assert.NoError(t, sg.Delete(true))
+}
+
+func TestCluster_DeleteNotReady(t *testing.T) {
+	backend := fake.NewBackend()
+	cluster, spec := newTestCluster(t, backend)
+
+	err := cluster.Delete(false)
+	assert.True(t, cloudobject.IsNotExistsError(err))
+
+	_, err = cluster.Create(spec)
+	require.NoError(t, err)
+	require.NoError(t, backend.SetDBClusterStatus(cluster.ID().String(), "modifying"))
+	err = cluster.Delete(false)
+	assert.IsType(t, cloudobject.NotReadyError{}, err)
+
+	require.NoError(t, backend.SetDBClusterStatus(cluster.ID().String(), DeletingInstanceState))
+	assert.NoError(t, cluster.Delete(false))
+}
+
+func TestClusterSpec_Valid(t *testing.T) {
+	spec := SaneAuroraPostgres("mydb", "mydb", "db.r5.large", "master", "", 1, nil, nil)
+	valid, err := spec.Valid()
+	assert.True(t, valid)
+	assert.NoError(t, err)
+
+	spec.Readers = 16
+	_, err = spec.Valid()
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+
+	spec.Readers = 1
+	spec.Engine = "postgres"
+	_, err = spec.Valid()
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+
+	spec.Engine = AuroraMySQLClusterDBEngine
+	spec.DBInstanceClass = ""
+	_, err = spec.Valid()
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+}
@@ -2,7 +2,7 @@ package rds
 
 import "github.com/redradrat/cloud-objects/aws"
 
-// Returns a "sane" defaulted InstanceSpec
+// Returns a "sane" defaulted InstanceSpec. An empty pass has a master password generated on creation.
 func SanePostgres(name, subnetGroupName, instanceClass, user, pass string, tags map[string]string,
 	securityGroupIds []string) InstanceSpec {
 	return InstanceSpec{
@@ -14,6 +14,7 @@ func SanePostgres(name, subnetGroupName, instanceClass, user, pass string, tags
 		DBSubnetGroupName:          aws.CloudObjectResource(DBSubnetGroupTopic, subnetGroupName),
 		Engine:                     PostgreSQLInstanceDBEngine,
 		EngineVersion:              "12.2",
+		GenerateMasterUserPassword: pass == "",
 		MasterUserPassword:         pass,
 		MasterUsername:             user,
 		Monitoring:                 nil,
@@ -33,3 +34,29 @@ func SanePostgres(name, subnetGroupName, instanceClass, user, pass string, tags
 		VpcSecurityGroupIds: securityGroupIds,
 	}
 }
+
+// Returns a "sane" defaulted ClusterSpec for Aurora PostgreSQL. An empty pass has a master password generated on
+// creation.
+func SaneAuroraPostgres(name, subnetGroupName, instanceClass, user, pass string, readers int64,
+	tags map[string]string, securityGroupIds []string) ClusterSpec {
+	return ClusterSpec{
+		BackupRetentionPeriod:      14,
+		DBInstanceClass:            instanceClass,
+		DBName:                     name,
+		DBSubnetGroupName:          aws.CloudObjectResource(DBSubnetGroupTopic, subnetGroupName),
+		Engine:                     AuroraPostgreSQLClusterDBEngine,
+		EngineVersion:              "11.6",
+		GenerateMasterUserPassword: pass == "",
+		MasterUserPassword:         pass,
+		MasterUsername:             user,
+		Port:                       5432,
+		PreferredBackupWindow:      "01:00-02:00",
+		PreferredMaintenanceWindow: "Sun:02:00-Sun:03:00",
+		PubliclyAccessible:         false,
+		Readers:                    readers,
+		RestorationDisabled:        false,
+		StorageEncrypted:           true,
+		Tags:                       tags,
+		VpcSecurityGroupIds:        securityGroupIds,
+	}
+}
@@ -1,8 +1,13 @@
 package rds
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsrds "github.com/aws/aws-sdk-go/service/rds"
+	"github.com/sethvargo/go-password/password"
 )
 
 func compileTags(tagMap map[string]string) []*awsrds.Tag {
@@ -15,3 +20,43 @@ func compileTags(tagMap map[string]string) []*awsrds.Tag {
 	}
 	return tags
 }
+
+// sortedStrings returns a sorted copy, so lists can be compared regardless of the order AWS returns them in
+func sortedStrings(in []string) []string {
+	out := append([]string{}, in...)
+	sort.Strings(out)
+	return out
+}
+
+const (
+	// GeneratedPasswordLength is the length of generated master passwords, which RDS limits to 41 characters
+	GeneratedPasswordLength = 32
+	// passwordSymbols are the symbols of generated master passwords. RDS doesn't allow "/", `"` and "@", and we leave
+	// out quotes, backslashes and anything else shells treat specially (like "$", "&", ";" or "*"), so passwords can
+	// be pasted into shells and config files as they are.
+	passwordSymbols = "%+,-.:=_"
+	// forbiddenPasswordChars are the characters RDS doesn't allow in master passwords
+	forbiddenPasswordChars = `/"@`
+)
+
+// GeneratePassword returns a random master password that honours the RDS rules for master passwords
+func GeneratePassword() (string, error) {
+	gen, err := password.NewGenerator(&password.GeneratorInput{Symbols: passwordSymbols})
+	if err != nil {
+		return "", err
+	}
+	return gen.Generate(GeneratedPasswordLength, 8, 4, false, false)
+}
+
+// validPassword tells why the given master password is not allowed by RDS, if it isn't
+func validPassword(pass string) error {
+	if len(pass) < 8 || len(pass) > 41 {
+		return fmt.Errorf("must be 8 to 41 characters")
+	}
+	for _, r := range pass {
+		if r <= ' ' || r > '~' || strings.ContainsRune(forbiddenPasswordChars, r) {
+			return fmt.Errorf(`must only contain printable ASCII characters other than space, "/", """ and "@"`)
+		}
+	}
+	return nil
+}
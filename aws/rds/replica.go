@@ -0,0 +1,561 @@
+package rds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awsarn "github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/client"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	awsrds "github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/aws/kms"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const (
+	DBReadReplicaTopic = "dbr"
+
+	ReadReplicaKind cloudobject.Kind = "readreplica"
+)
+
+// ReadReplica represents the RDS Read Replica CloudObject: a read-only copy of an Instance, in the same or another
+// region. Replicas of encrypted Instances are encrypted with a KMS Key of their own. Replicas are kept in sync with
+// their source, so unlike Instances, they leave no final snapshot on delete.
+type ReadReplica struct {
+	name    string
+	status  *InstanceStatus
+	session rdsiface.RDSAPI
+	// source returns the client for the region of the source Instance, "" being the region of the ReadReplica
+	source func(region string) rdsiface.RDSAPI
+	// kms is the client for the Key the ReadReplica is encrypted with
+	kms kmsiface.KMSAPI
+	// logger is where the ReadReplica logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+// NewReadReplica returns a new RDS read replica object, in the region of the given session. For replicas of
+// Instances in other regions, see ReadReplicaSpec.SourceRegion.
+func NewReadReplica(name string, session client.ConfigProvider) (*ReadReplica, error) {
+	svc := awsrds.New(session)
+	return newReadReplica(name, svc, func(region string) rdsiface.RDSAPI {
+		if region == "" {
+			return svc
+		}
+		return awsrds.New(session, awssdk.NewConfig().WithRegion(region))
+	}, awskms.New(session))
+}
+
+// NewReadReplicaWithClients is the same as NewReadReplica, but uses the given RDS and KMS clients instead of
+// creating its own. sourceSvc is the RDS client for the region of the source Instance, which is svc itself unless
+// ReadReplicaSpec.SourceRegion is set.
+func NewReadReplicaWithClients(name string, svc, sourceSvc rdsiface.RDSAPI, kmsSvc kmsiface.KMSAPI) (*ReadReplica,
+	error) {
+	return newReadReplica(name, svc, func(string) rdsiface.RDSAPI { return sourceSvc }, kmsSvc)
+}
+
+func newReadReplica(name string, svc rdsiface.RDSAPI, source func(string) rdsiface.RDSAPI,
+	kmsSvc kmsiface.KMSAPI) (*ReadReplica, error) {
+	if len(name) == 0 {
+		return nil, fmt.Errorf("given name is empty")
+	}
+	// Identifiers are limited to 63 characters, including our "clobjx-dbr-" prefix
+	if len(name) > 52 {
+		return nil, fmt.Errorf("given name is longer than 52 characters")
+	}
+
+	replica := ReadReplica{
+		name:    name,
+		session: svc,
+		source:  source,
+		kms:     kmsSvc,
+	}
+
+	return &replica, nil
+}
+
+// Get the CloudObjectId for our ReadReplica. Equals to ReadReplica Name. This is not the AWS Id.
+func (r *ReadReplica) ID() cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(DBReadReplicaTopic, r.name))
+}
+
+// Create creates the ReadReplica of the Instance given in the spec, which has to be available and have automated
+// backups enabled
+func (r *ReadReplica) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return r.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (r *ReadReplica) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets,
+	error) {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "create")
+	assertedSpec, ok := spec.(*ReadReplicaSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	exists, err := r.ExistsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return r.Secrets(), nil
+	}
+
+	source, err := r.readSource(ctx, assertedSpec)
+	if err != nil {
+		return nil, err
+	}
+	if awssdk.StringValue(source.DBInstanceStatus) != AvailableInstanceState {
+		return nil, cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("cannot replicate not-available RDS instance '%s'", assertedSpec.sourceID()),
+			ID:      r.ID(),
+			Op:      "create",
+		}}
+	}
+	// RDS replicates from the backups of the source
+	if awssdk.Int64Value(source.BackupRetentionPeriod) == 0 {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("RDS instance '%s' has automated backups disabled, which read replicas need",
+				assertedSpec.sourceID()),
+		}}
+	}
+
+	sourceIdentifier := assertedSpec.sourceID().String()
+	if assertedSpec.SourceRegion != "" {
+		sourceIdentifier = awssdk.StringValue(source.DBInstanceArn)
+	}
+	input := assertedSpec.CreateDBInstanceReadReplicaInput(r.ID().String(), sourceIdentifier)
+	if awssdk.BoolValue(source.StorageEncrypted) {
+		key, err := r.key()
+		if err != nil {
+			return nil, err
+		}
+		keyFound, err := key.ExistsWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !keyFound {
+			_, err := key.CreateWithContext(ctx, &kms.KeySpec{
+				KeyUsage: kms.EncryptDecryptKeyUsage,
+				KeyType:  kms.SymmetricDefaultKeyType,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		input.KmsKeyId = key.ID().StringPtr()
+	}
+	if _, err := r.session.CreateDBInstanceReadReplicaWithContext(ctx, &input); err != nil {
+		return nil, err
+	}
+
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err := r.ReadWithContext(aws.WithRetryCodes(ctx, awsrds.ErrCodeDBInstanceNotFoundFault)); err != nil {
+		return nil, err
+	}
+
+	return r.Secrets(), nil
+}
+
+// readSource reads the source Instance given in the spec, in its region
+func (r *ReadReplica) readSource(ctx context.Context, spec *ReadReplicaSpec) (*awsrds.DBInstance, error) {
+	out, err := r.source(spec.SourceRegion).DescribeDBInstancesWithContext(ctx, &awsrds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: spec.sourceID().StringPtr(),
+	})
+	if err != nil {
+		if aws.IsErrorCode(err, awsrds.ErrCodeDBInstanceNotFoundFault) {
+			return nil, cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("source RDS DB Instance with id '%s' not found", spec.sourceID()),
+				ID:      spec.sourceID(),
+				Op:      "read",
+				Err:     err,
+			}}
+		}
+		return nil, err
+	}
+	if len(out.DBInstances) != 1 {
+		return nil, cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("source RDS DB Instance with id '%s' not found", spec.sourceID()),
+			ID:      spec.sourceID(),
+			Op:      "read",
+		}}
+	}
+	return out.DBInstances[0], nil
+}
+
+// key returns the Key the ReadReplica is encrypted with, if its source is encrypted. Its name sets it apart from
+// the Key of an Instance of the same name.
+func (r *ReadReplica) key() (*kms.Key, error) {
+	return kms.NewKeyWithClient(replicaKeyName(r.name), r.kms)
+}
+
+func replicaKeyName(name string) string {
+	return DBReadReplicaTopic + "-" + name
+}
+
+func (r *ReadReplica) Read() error {
+	return r.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context
+func (r *ReadReplica) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "read")
+	out, err := r.session.DescribeDBInstancesWithContext(ctx, &awsrds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: r.ID().StringPtr(),
+	})
+	if err != nil {
+		if aws.IsErrorCode(err, awsrds.ErrCodeDBInstanceNotFoundFault) {
+			return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("RDS DB Read Replica with id '%s' not found", r.ID().String()),
+				ID:      r.ID(),
+				Op:      "read",
+				Err:     err,
+			}}
+		}
+		return err
+	}
+	if len(out.DBInstances) == 0 {
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("RDS DB Read Replica with id '%s' not found", r.ID().String()),
+			ID:      r.ID(),
+			Op:      "read",
+		}}
+	}
+	if len(out.DBInstances) > 1 {
+		return cloudobject.AmbiguousIdentifierError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("multiple RDS DB Read Replicas with id '%s' found", r.ID().String()),
+			ID:      r.ID(),
+			Op:      "read",
+		}}
+	}
+	r.status = (*InstanceStatus)(out.DBInstances[0])
+
+	return nil
+}
+
+func (r *ReadReplica) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return r.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context. The source of a
+// ReadReplica can't be changed.
+func (r *ReadReplica) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets,
+	error) {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "update")
+	assertedSpec, ok := spec.(*ReadReplicaSpec)
+	if !ok {
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+	if err := r.ReadWithContext(ctx); err != nil {
+		return nil, err
+	}
+
+	diff := assertedSpec.Diff(r.ID(), r.status)
+	if err := diff.ImmutableChangesError(); err != nil {
+		return nil, err
+	}
+	if !diff.HasChanges() {
+		return r.Secrets(), nil
+	}
+
+	input := assertedSpec.ModifyDBInstanceInput(r.ID().String())
+	out, err := r.session.ModifyDBInstanceWithContext(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+	if out.DBInstance != nil {
+		r.status = (*InstanceStatus)(out.DBInstance)
+	}
+
+	return r.Secrets(), nil
+}
+
+// Plan compares the given spec against the live RDS Read Replica, without changing anything
+func (r *ReadReplica) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return r.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (r *ReadReplica) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff,
+	error) {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "plan")
+	assertedSpec, ok := spec.(*ReadReplicaSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := r.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(r.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(r.ID(), r.status), nil
+}
+
+// Delete deletes the ReadReplica. Read replicas have no final snapshot, purging only deletes their Key as well.
+func (r *ReadReplica) Delete(purge bool) error {
+	return r.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (r *ReadReplica) DeleteWithContext(ctx context.Context, purge bool) error {
+	ctx = cloudobject.WithOperation(ctx, r.logger, r.ID(), "delete")
+	exists, err := r.ExistsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("cannot delete non-existing RDS read replica '%s'", r.ID().String()),
+			ID:      r.ID(),
+			Op:      "delete",
+		}}
+	}
+
+	if r.status.State() == DeletingInstanceState {
+		return nil
+	}
+	if r.status.State() != AvailableInstanceState {
+		return cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("cannot delete not-available RDS read replica '%s'", r.ID().String()),
+			ID:      r.ID(),
+			Op:      "delete",
+		}}
+	}
+
+	if _, err := r.session.ModifyDBInstanceWithContext(ctx, &awsrds.ModifyDBInstanceInput{
+		DeletionProtection:   awssdk.Bool(false),
+		DBInstanceIdentifier: r.ID().StringPtr(),
+	}); err != nil {
+		return err
+	}
+	if _, err := r.session.DeleteDBInstanceWithContext(ctx, &awsrds.DeleteDBInstanceInput{
+		DBInstanceIdentifier:   r.ID().StringPtr(),
+		DeleteAutomatedBackups: awssdk.Bool(true),
+		SkipFinalSnapshot:      awssdk.Bool(true),
+	}); err != nil && !aws.IsErrorCode(err, awsrds.ErrCodeDBInstanceNotFoundFault) {
+		return err
+	}
+
+	// Replicas of unencrypted Instances have no Key to delete
+	if purge && awssdk.BoolValue(r.status.StorageEncrypted) {
+		key, err := r.key()
+		if err != nil {
+			return err
+		}
+		if err := key.DeleteWithContext(ctx, purge); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ReadReplica) ARN() *awsarn.ARN {
+	if err := r.Read(); err != nil {
+		return nil
+	}
+	arn := aws.MustParse(*r.status.DBInstanceArn)
+	return &arn
+}
+
+// References returns the IDs of the source Instance and the DB SubnetGroup the ReadReplica uses. Its Key is only
+// created along with it, if the source is encrypted.
+func (r *ReadReplica) References(spec cloudobject.CloudObjectSpec) []cloudobject.ID {
+	assertedSpec, ok := spec.(*ReadReplicaSpec)
+	if !ok {
+		return nil
+	}
+	refs := []cloudobject.ID{assertedSpec.sourceID()}
+	if assertedSpec.DBSubnetGroupName != "" {
+		refs = append(refs, cloudobject.ID(assertedSpec.DBSubnetGroupName))
+	}
+	return refs
+}
+
+func (r *ReadReplica) Exists() (bool, error) {
+	return cloudobject.Exists(r)
+}
+
+func (r *ReadReplica) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, r)
+}
+
+// Secrets returns the connection details of the ReadReplica as of its last read. Replicas share the master user
+// of their source, but its password is only known to the source Instance, so it's left out. Returns nil if the
+// ReadReplica wasn't read yet.
+func (r *ReadReplica) Secrets() cloudobject.Secrets {
+	if r.status == nil {
+		return nil
+	}
+	secrets := InstanceSecrets{
+		DBName:   awssdk.StringValue(r.status.DBName),
+		Username: awssdk.StringValue(r.status.MasterUsername),
+		Engine:   awssdk.StringValue(r.status.Engine),
+	}
+	if r.status.Endpoint != nil {
+		secrets.Host = awssdk.StringValue(r.status.Endpoint.Address)
+		secrets.Port = awssdk.Int64Value(r.status.Endpoint.Port)
+	}
+	return secrets
+}
+
+// SetLogger makes the ReadReplica log its AWS calls to the given logger
+func (r *ReadReplica) SetLogger(logger cloudobject.Logger) {
+	r.logger = logger
+}
+
+func (r *ReadReplica) Status() cloudobject.Status {
+	return r.status
+}
+
+// ReadReplicaSpec is the spec of a read replica of an Instance. Engine, storage and credentials are those of the
+// source.
+type ReadReplicaSpec struct {
+	// The name of the source Instance, as given to NewInstance
+	SourceInstance string
+
+	// The region of the source Instance, if it's not the region of the ReadReplica. Encrypted cross-region
+	// replicas are encrypted with a Key in the region of the ReadReplica.
+	SourceRegion string
+
+	// The Availability Zone the ReadReplica is created in. Default: a random, system-chosen Availability Zone.
+	AvailabilityZone string
+
+	// A value that indicates whether minor engine upgrades are applied automatically during the maintenance window
+	AutoMinorVersionUpgrade bool
+
+	// The compute and memory capacity of the ReadReplica. Default: the class of the source Instance.
+	DBInstanceClass string
+
+	// A DB subnet group for a cross-region ReadReplica. Replicas in the region of their source are created in its
+	// subnet group.
+	DBSubnetGroupName string
+
+	// Defines whether the ReadReplica will have a public endpoint attached
+	PubliclyAccessible bool
+
+	Tags map[string]string
+
+	// The VPC security groups to associate with the ReadReplica. Default: those of the source Instance.
+	VpcSecurityGroupIds []string
+}
+
+// sourceID returns the ID of the source Instance
+func (spec *ReadReplicaSpec) sourceID() cloudobject.ID {
+	return cloudobject.ID(aws.CloudObjectResource(DBInstanceTopic, spec.SourceInstance))
+}
+
+func (spec *ReadReplicaSpec) Valid() (bool, error) {
+	if spec.SourceInstance == "" {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "SourceInstance in spec is empty",
+		}}
+	}
+
+	if spec.DBSubnetGroupName != "" && spec.SourceRegion == "" {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "DBSubnetGroupName can only be given for replicas of instances in other regions",
+		}}
+	}
+
+	return true, nil
+}
+
+// Diff compares the spec field by field against the given live status. Fields that default to those of the source
+// (instance class, security groups) are only compared if set in the spec.
+func (spec *ReadReplicaSpec) Diff(id cloudobject.ID, status *InstanceStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+
+	// Cross-region replicas know their source by its ARN
+	source := awssdk.StringValue(status.ReadReplicaSourceDBInstanceIdentifier)
+	if i := strings.LastIndex(source, ":db:"); i >= 0 {
+		source = source[i+len(":db:"):]
+	}
+	diff.CompareImmutable("SourceInstance", source, spec.sourceID().String())
+	if spec.AvailabilityZone != "" {
+		diff.CompareImmutable("AvailabilityZone", awssdk.StringValue(status.AvailabilityZone),
+			spec.AvailabilityZone)
+	}
+
+	if spec.DBInstanceClass != "" {
+		diff.Compare("DBInstanceClass", awssdk.StringValue(status.DBInstanceClass), spec.DBInstanceClass)
+	}
+	diff.Compare("AutoMinorVersionUpgrade", awssdk.BoolValue(status.AutoMinorVersionUpgrade),
+		spec.AutoMinorVersionUpgrade)
+	diff.Compare("PubliclyAccessible", awssdk.BoolValue(status.PubliclyAccessible), spec.PubliclyAccessible)
+	if len(spec.VpcSecurityGroupIds) != 0 {
+		var current []string
+		for _, sg := range status.VpcSecurityGroups {
+			current = append(current, awssdk.StringValue(sg.VpcSecurityGroupId))
+		}
+		diff.Compare("VpcSecurityGroupIds", sortedStrings(current), sortedStrings(spec.VpcSecurityGroupIds))
+	}
+
+	return diff
+}
+
+///////////////
+/// AWS API ///
+///////////////
+
+// CreateDBInstanceReadReplicaInput returns the marshaled AWS Interface object of same name, for a replica of the
+// given source: its identifier, or its ARN if it's in another region
+func (spec *ReadReplicaSpec) CreateDBInstanceReadReplicaInput(id, source string) awsrds.
+	CreateDBInstanceReadReplicaInput {
+	out := awsrds.CreateDBInstanceReadReplicaInput{
+		AutoMinorVersionUpgrade:    awssdk.Bool(spec.AutoMinorVersionUpgrade),
+		CopyTagsToSnapshot:         awssdk.Bool(true),
+		DBInstanceIdentifier:       awssdk.String(id),
+		DeletionProtection:         awssdk.Bool(true),
+		PubliclyAccessible:         awssdk.Bool(spec.PubliclyAccessible),
+		SourceDBInstanceIdentifier: awssdk.String(source),
+		Tags:                       compileTags(spec.Tags),
+		// KmsKeyId we'll set on creation... there we have the key creation/discovery logic
+	}
+	if spec.AvailabilityZone != "" {
+		out.AvailabilityZone = awssdk.String(spec.AvailabilityZone)
+	}
+	if spec.DBInstanceClass != "" {
+		out.DBInstanceClass = awssdk.String(spec.DBInstanceClass)
+	}
+	if spec.DBSubnetGroupName != "" {
+		out.DBSubnetGroupName = awssdk.String(spec.DBSubnetGroupName)
+	}
+	// The SDK presigns the request for the source region, if it's another one
+	if spec.SourceRegion != "" {
+		out.SourceRegion = awssdk.String(spec.SourceRegion)
+	}
+	if len(spec.VpcSecurityGroupIds) != 0 {
+		out.VpcSecurityGroupIds = awssdk.StringSlice(spec.VpcSecurityGroupIds)
+	}
+	return out
+}
+
+// ModifyDBInstanceInput returns the marshaled AWS Interface object of same name
+func (spec *ReadReplicaSpec) ModifyDBInstanceInput(id string) awsrds.ModifyDBInstanceInput {
+	out := awsrds.ModifyDBInstanceInput{
+		ApplyImmediately:        awssdk.Bool(true),
+		AutoMinorVersionUpgrade: awssdk.Bool(spec.AutoMinorVersionUpgrade),
+		DBInstanceIdentifier:    awssdk.String(id),
+		PubliclyAccessible:      awssdk.Bool(spec.PubliclyAccessible),
+	}
+	if spec.DBInstanceClass != "" {
+		out.DBInstanceClass = awssdk.String(spec.DBInstanceClass)
+	}
+	if len(spec.VpcSecurityGroupIds) != 0 {
+		out.VpcSecurityGroupIds = awssdk.StringSlice(spec.VpcSecurityGroupIds)
+	}
+	return out
+}
@@ -0,0 +1,200 @@
+package rds
+
+import (
+	"strings"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	awsrds "github.com/aws/aws-sdk-go/service/rds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/aws/fake"
+	"github.com/redradrat/cloud-objects/aws/kms"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+func newTestReadReplica(t *testing.T, backend *fake.Backend) (*ReadReplica, *ReadReplicaSpec, *Instance,
+	*InstanceSpec) {
+	ins, insSpec := newTestInstance(t, backend)
+	_, err := ins.Create(insSpec)
+	require.NoError(t, err)
+
+	replica, err := NewReadReplica("mydb", backend.Session())
+	require.NoError(t, err)
+	return replica, &ReadReplicaSpec{SourceInstance: "mydb", AutoMinorVersionUpgrade: true}, ins, insSpec
+}
+
+func TestNewReadReplica(t *testing.T) {
+	backend := fake.NewBackend()
+	_, err := NewReadReplica("", backend.Session())
+	assert.Error(t, err)
+	_, err = NewReadReplica(strings.Repeat("a", 53), backend.Session())
+	assert.Error(t, err)
+	replica, err := NewReadReplica(strings.Repeat("a", 52), backend.Session())
+	require.NoError(t, err)
+	assert.Len(t, replica.ID().String(), 63)
+}
+
+func TestReadReplica_Create(t *testing.T) {
+	backend := fake.NewBackend()
+	replica, spec, ins, _ := newTestReadReplica(t, backend)
+
+	secrets, err := replica.Create(spec)
+	require.NoError(t, err)
+	ready, err := InstanceAvailable(replica.Status())
+	assert.NoError(t, err)
+	assert.True(t, ready)
+
+	// The replica has an endpoint of its own, and the credentials of its source but the password
+	host := "clobjx-dbr-mydb.fake.us-east-1.rds.amazonaws.com"
+	assert.Equal(t, host, secrets.Map()["host"])
+	assert.Equal(t, "master", secrets.Map()["username"])
+	assert.NotContains(t, secrets.Map(), "password")
+	assert.Contains(t, secrets.Map()["dsn"], "postgres://master@"+host+":5432/")
+
+	// The source is encrypted, so the replica is as well, with a Key of its own
+	key, err := kms.NewKey("dbr-mydb", backend.Session())
+	require.NoError(t, err)
+	require.NoError(t, key.Read())
+	assert.Equal(t, key.Status().ProviderID().Value, awssdk.StringValue(replica.status.KmsKeyId))
+	assert.NotEqual(t, awssdk.StringValue(ins.status.KmsKeyId), awssdk.StringValue(replica.status.KmsKeyId))
+
+	// Source and replica know each other
+	details := cloudobject.Summarize(ReadReplicaKind, replica).Details.(InstanceDetails)
+	assert.Equal(t, "clobjx-db-mydb", details.ReadReplicaSource)
+	assert.Equal(t, "db.t3.micro", details.InstanceClass)
+	require.NoError(t, ins.Read())
+	details = cloudobject.Summarize(InstanceKind, ins).Details.(InstanceDetails)
+	assert.Equal(t, []string{"clobjx-dbr-mydb"}, details.ReadReplicas)
+
+	diff, err := replica.Plan(spec)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges(), diff.String())
+
+	// Creating it again changes nothing
+	_, err = replica.Create(spec)
+	assert.NoError(t, err)
+}
+
+func TestReadReplica_CreateNoBackups(t *testing.T) {
+	backend := fake.NewBackend()
+	replica, spec, ins, insSpec := newTestReadReplica(t, backend)
+
+	insSpec.BackupRetentionPeriod = 0
+	_, err := ins.Update(insSpec)
+	require.NoError(t, err)
+	_, err = replica.Create(spec)
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+
+	spec.SourceInstance = "otherdb"
+	_, err = replica.Create(spec)
+	assert.True(t, cloudobject.IsNotExistsError(err))
+}
+
+func TestReadReplica_Update(t *testing.T) {
+	backend := fake.NewBackend()
+	replica, spec, ins, insSpec := newTestReadReplica(t, backend)
+	_, err := replica.Create(spec)
+	require.NoError(t, err)
+
+	spec.DBInstanceClass = "db.t3.small"
+	spec.VpcSecurityGroupIds = []string{"sg-2"}
+	_, err = replica.Update(spec)
+	require.NoError(t, err)
+	diff, err := replica.Plan(spec)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges(), diff.String())
+
+	// Replicas stick to their source
+	spec.SourceInstance = "otherdb"
+	_, err = replica.Update(spec)
+	assert.Error(t, err)
+
+	// ...which can't drop its backups while it has replicas
+	insSpec.BackupRetentionPeriod = 0
+	_, err = ins.Update(insSpec)
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+}
+
+func TestReadReplica_Delete(t *testing.T) {
+	backend := fake.NewBackend()
+	replica, spec, ins, insSpec := newTestReadReplica(t, backend)
+
+	err := replica.Delete(false)
+	assert.True(t, cloudobject.IsNotExistsError(err))
+
+	_, err = replica.Create(spec)
+	require.NoError(t, err)
+	require.NoError(t, backend.SetDBInstanceStatus(replica.ID().String(), "modifying"))
+	err = replica.Delete(false)
+	assert.IsType(t, cloudobject.NotReadyError{}, err)
+	require.NoError(t, backend.SetDBInstanceStatus(replica.ID().String(), AvailableInstanceState))
+
+	// Deleting keeps the Key around, purging doesn't
+	require.NoError(t, replica.Delete(false))
+	exists, err := replica.Exists()
+	require.NoError(t, err)
+	assert.False(t, exists)
+	key, err := kms.NewKey("dbr-mydb", backend.Session())
+	require.NoError(t, err)
+	exists, err = key.Exists()
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = replica.Create(spec)
+	require.NoError(t, err)
+	require.NoError(t, replica.Delete(true))
+	exists, err = key.Exists()
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Once the replica is gone, the source may drop its backups
+	insSpec.BackupRetentionPeriod = 0
+	_, err = ins.Update(insSpec)
+	assert.NoError(t, err)
+}
+
+func TestReadReplica_CrossRegion(t *testing.T) {
+	backend := fake.NewBackend()
+	_, spec, ins, _ := newTestReadReplica(t, backend)
+
+	cfg := awssdk.NewConfig().WithRegion("eu-west-1")
+	replica, err := NewReadReplicaWithClients("mydb", awsrds.New(backend.Session(), cfg),
+		awsrds.New(backend.Session()), awskms.New(backend.Session(), cfg))
+	require.NoError(t, err)
+	spec.SourceRegion = fake.Region
+	spec.DBSubnetGroupName = "clobjx-sg-mydb"
+	_, err = replica.Create(spec)
+	require.NoError(t, err)
+
+	// The source is given by its ARN, and the SDK presigns the request for its region
+	assert.Equal(t, awssdk.StringValue(ins.status.DBInstanceArn),
+		awssdk.StringValue(replica.status.ReadReplicaSourceDBInstanceIdentifier))
+	diff, err := replica.Plan(spec)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges(), diff.String())
+
+	input := spec.CreateDBInstanceReadReplicaInput(replica.ID().String(), "arn")
+	assert.Equal(t, fake.Region, awssdk.StringValue(input.SourceRegion))
+	assert.Equal(t, "clobjx-sg-mydb", awssdk.StringValue(input.DBSubnetGroupName))
+}
+
+func TestReadReplicaSpec_Valid(t *testing.T) {
+	spec := ReadReplicaSpec{SourceInstance: "mydb"}
+	valid, err := spec.Valid()
+	assert.True(t, valid)
+	assert.NoError(t, err)
+
+	spec.DBSubnetGroupName = "clobjx-sg-mydb"
+	_, err = spec.Valid()
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+	spec.SourceRegion = "eu-west-1"
+	_, err = spec.Valid()
+	assert.NoError(t, err)
+
+	spec.SourceInstance = ""
+	_, err = spec.Valid()
+	assert.True(t, cloudobject.IsCloudSpecInvalidError(err))
+}
@@ -1,12 +1,13 @@
 package rds
 
 import (
+	"context"
 	"fmt"
 
 	awssdk "github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
 	awsrds "github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
 
 	"github.com/redradrat/cloud-objects/aws"
 	"github.com/redradrat/cloud-objects/cloudobject"
@@ -14,15 +15,26 @@ import (
 
 const (
 	DBSubnetGroupTopic = "sg"
+
+	CompleteSubnetGroupState = "Complete"
+
+	SubnetGroupKind cloudobject.Kind = "subnetgroup"
 )
 
 type SubnetGroup struct {
 	name    string
 	status  *SubnetGroupStatus
-	session *awsrds.RDS
+	session rdsiface.RDSAPI
+	// logger is where the SubnetGroup logs its AWS calls to, if set
+	logger cloudobject.Logger
 }
 
 func NewSubnetGroup(name string, session client.ConfigProvider) (*SubnetGroup, error) {
+	return NewSubnetGroupWithClient(name, awsrds.New(session))
+}
+
+// NewSubnetGroupWithClient is the same as NewSubnetGroup, but uses the given RDS client instead of creating its own
+func NewSubnetGroupWithClient(name string, svc rdsiface.RDSAPI) (*SubnetGroup, error) {
 	if len(name) == 0 {
 		return nil, fmt.Errorf("given name is empty")
 	}
@@ -32,22 +44,28 @@ func NewSubnetGroup(name string, session client.ConfigProvider) (*SubnetGroup, e
 
 	sg := SubnetGroup{
 		name:    name,
-		session: awsrds.New(session),
+		session: svc,
 	}
 
 	return &sg, nil
 }
 
 func (s *SubnetGroup) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return s.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (s *SubnetGroup) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, s.logger, s.ID(), "create")
 	// It's fair to assume, that we get an RDS SubnetGroupSpec here.
 	assertedSpec, ok := spec.(*SubnetGroupSpec)
 	if !ok {
 		// If not, we're throwing an error here... ya done messed up.
-		return nil, cloudobject.SpecInvalidError{Message: "got unsupported spec"}
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
 	}
 
 	// If the SubnetGroup already exists, we're done here... you're trying to play us for a fool!
-	exists, err := s.Exists()
+	exists, err := s.ExistsWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,13 +75,13 @@ func (s *SubnetGroup) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secr
 
 	// Now let's go for it... create this SubnetGroup!
 	input := assertedSpec.CreateDBSubnetGroupInput(s.ID().String())
-	_, err = s.session.CreateDBSubnetGroup(&input)
+	_, err = s.session.CreateDBSubnetGroupWithContext(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
 
-	// re-trigger status update
-	if err = s.Read(); err != nil {
+	// re-trigger status update, giving AWS a moment to let us see what we just created
+	if err = s.ReadWithContext(aws.WithRetryCodes(ctx, awsrds.ErrCodeDBSubnetGroupNotFoundFault)); err != nil {
 		return nil, err
 	}
 
@@ -71,25 +89,41 @@ func (s *SubnetGroup) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secr
 }
 
 func (s *SubnetGroup) Read() error {
+	return s.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context
+func (s *SubnetGroup) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, s.logger, s.ID(), "read")
 	// Call AWS to describe our DB SubnetGroup
-	out, err := s.session.DescribeDBSubnetGroups(&awsrds.DescribeDBSubnetGroupsInput{
+	out, err := s.session.DescribeDBSubnetGroupsWithContext(ctx, &awsrds.DescribeDBSubnetGroupsInput{
 		DBSubnetGroupName: s.ID().StringPtr(),
 	})
 	if err != nil {
-		if err.(awserr.Error).Code() == awsrds.ErrCodeDBSubnetGroupNotFoundFault {
-			return cloudobject.NotExistsError{Message: fmt.Sprintf("RDS DB SubnetGroup with id '%s' not found",
-				s.ID().String())}
+		if aws.IsErrorCode(err, awsrds.ErrCodeDBSubnetGroupNotFoundFault) {
+			return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("RDS DB SubnetGroup with id '%s' not found", s.ID().String()),
+				ID:      s.ID(),
+				Op:      "read",
+				Err:     err,
+			}}
 		}
 		return err
 	}
 	// If our output DB list is 0, we didn't find any matches -> not exists
 	if len(out.DBSubnetGroups) == 0 {
-		return cloudobject.NotExistsError{Message: fmt.Sprintf("RDS DB SubnetGroup with id '%s' not found",
-			s.ID().String())}
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("RDS DB SubnetGroup with id '%s' not found", s.ID().String()),
+			ID:      s.ID(),
+			Op:      "read",
+		}}
 	}
-	if len(out.DBSubnetGroups) < 1 {
-		return cloudobject.AmbiguousIdentifierError{Message: fmt.Sprintf(
-			"multiple RDS DB SubnetGroups with id '%s' found", s.ID().String())}
+	if len(out.DBSubnetGroups) > 1 {
+		return cloudobject.AmbiguousIdentifierError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("multiple RDS DB SubnetGroups with id '%s' found", s.ID().String()),
+			ID:      s.ID(),
+			Op:      "read",
+		}}
 	}
 	s.status = (*SubnetGroupStatus)(out.DBSubnetGroups[0])
 
@@ -97,22 +131,29 @@ func (s *SubnetGroup) Read() error {
 }
 
 func (s *SubnetGroup) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return s.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (s *SubnetGroup) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, s.logger, s.ID(), "update")
 	// It's fair to assume, that we get an RDS SubnetGroupSpec here.
 	assertedSpec, ok := spec.(*SubnetGroupSpec)
 	if !ok {
-		return nil, cloudobject.SpecInvalidError{Message: "got unsupported spec"}
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
 	}
 
 	// Let's update our status
-	if err := s.Read(); err != nil {
+	if err := s.ReadWithContext(ctx); err != nil {
 		return nil, err
 	}
-	// Here we could copy the old status before we read again, and compute a delta
-	_ = s.status
+	if !assertedSpec.Diff(s.ID(), s.status).HasChanges() {
+		return nil, nil
+	}
 
 	// Now let's go for it... Modify the actual DB SubnetGroup
 	input := assertedSpec.ModifyDBSubnetGroupInput(s.ID().String())
-	_, err := s.session.ModifyDBSubnetGroup(&input)
+	_, err := s.session.ModifyDBSubnetGroupWithContext(ctx, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -120,10 +161,39 @@ func (s *SubnetGroup) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secr
 	return nil, nil
 }
 
+// Plan compares the given spec against the live SubnetGroup, without changing anything
+func (s *SubnetGroup) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return s.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (s *SubnetGroup) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, s.logger, s.ID(), "plan")
+	assertedSpec, ok := spec.(*SubnetGroupSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	if err := s.ReadWithContext(ctx); err != nil {
+		if cloudobject.IsNotExistsError(err) {
+			return cloudobject.NewCreateDiff(s.ID()), nil
+		}
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(s.ID(), s.status), nil
+}
+
 // Delete deletes the SubnetGroup. Purge has no effect, subnet group will always be purged.
-func (s *SubnetGroup) Delete(_ bool) error {
+func (s *SubnetGroup) Delete(purge bool) error {
+	return s.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (s *SubnetGroup) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, s.logger, s.ID(), "delete")
 	// First, let's check whether our SubnetGroup actually exists
-	exists, err := s.Exists()
+	exists, err := s.ExistsWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -137,7 +207,8 @@ func (s *SubnetGroup) Delete(_ bool) error {
 		DBSubnetGroupName: s.ID().StringPtr(),
 	}
 	// Now let's go for it... delete that naughty SubnetGroup!! (kill it with fire, pwetty please)
-	if _, err := s.session.DeleteDBSubnetGroup(&input); cloudobject.IgnoreNotExistsError(err) != nil {
+	if _, err := s.session.DeleteDBSubnetGroupWithContext(ctx, &input); err != nil &&
+		!aws.IsErrorCode(err, awsrds.ErrCodeDBSubnetGroupNotFoundFault) {
 		return err
 	}
 
@@ -149,6 +220,15 @@ func (s *SubnetGroup) Exists() (bool, error) {
 	return cloudobject.Exists(s)
 }
 
+func (s *SubnetGroup) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, s)
+}
+
+// SetLogger makes the SubnetGroup log its AWS calls to the given logger
+func (s *SubnetGroup) SetLogger(logger cloudobject.Logger) {
+	s.logger = logger
+}
+
 func (s *SubnetGroup) ID() cloudobject.ID {
 	return cloudobject.ID(aws.CloudObjectResource(DBSubnetGroupTopic, s.name))
 }
@@ -173,6 +253,23 @@ func (spec *SubnetGroupSpec) Valid() (bool, error) {
 	return true, nil
 }
 
+// Diff compares the spec against the given live status. Tags are not part of the status, so they aren't compared.
+func (spec *SubnetGroupSpec) Diff(id cloudobject.ID, status *SubnetGroupStatus) cloudobject.Diff {
+	if status == nil {
+		return cloudobject.NewCreateDiff(id)
+	}
+	diff := cloudobject.Diff{ID: id}
+
+	var subnetIDs []string
+	for _, subnet := range status.Subnets {
+		subnetIDs = append(subnetIDs, awssdk.StringValue(subnet.SubnetIdentifier))
+	}
+	diff.Compare("Description", awssdk.StringValue(status.DBSubnetGroupDescription), spec.Description)
+	diff.Compare("SubnetIDs", sortedStrings(subnetIDs), sortedStrings(spec.SubnetIDs))
+
+	return diff
+}
+
 func (spec *SubnetGroupSpec) CreateDBSubnetGroupInput(id string) awsrds.CreateDBSubnetGroupInput {
 
 	tags := awsTags(spec)
@@ -204,6 +301,9 @@ func (spec *SubnetGroupSpec) ModifyDBSubnetGroupInput(id string) awsrds.ModifyDB
 type SubnetGroupStatus awsrds.DBSubnetGroup
 
 func (status *SubnetGroupStatus) ProviderID() cloudobject.ProviderID {
+	if status == nil {
+		return cloudobject.ProviderID{Type: cloudobject.AWSProvider}
+	}
 	out := awsrds.DBSubnetGroup(*status)
 	return cloudobject.ProviderID{
 		Type:  cloudobject.AWSProvider,
@@ -211,7 +311,48 @@ func (status *SubnetGroupStatus) ProviderID() cloudobject.ProviderID {
 	}
 }
 
+func (status *SubnetGroupStatus) State() string {
+	if status == nil {
+		return ""
+	}
+	return awssdk.StringValue(status.SubnetGroupStatus)
+}
+
+// SubnetGroupDetails are the key facts of an RDS DB SubnetGroup, as given in its cloudobject.Summary
+type SubnetGroupDetails struct {
+	Description string   `json:"description" yaml:"description"`
+	VpcID       string   `json:"vpcID" yaml:"vpcID"`
+	SubnetIDs   []string `json:"subnetIDs" yaml:"subnetIDs"`
+}
+
+func (status *SubnetGroupStatus) Details() interface{} {
+	if status == nil {
+		return nil
+	}
+	details := SubnetGroupDetails{
+		Description: awssdk.StringValue(status.DBSubnetGroupDescription),
+		VpcID:       awssdk.StringValue(status.VpcId),
+		SubnetIDs:   []string{},
+	}
+	for _, subnet := range status.Subnets {
+		details.SubnetIDs = append(details.SubnetIDs, awssdk.StringValue(subnet.SubnetIdentifier))
+	}
+	return details
+}
+
+// SubnetGroupComplete is a cloudobject.ReadyFunc for RDS DB SubnetGroups that are ready to be used
+func SubnetGroupComplete(status cloudobject.Status) (bool, error) {
+	sgStatus, ok := status.(*SubnetGroupStatus)
+	if !ok {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported status"}}
+	}
+	return sgStatus.State() == CompleteSubnetGroupState, nil
+}
+
 func (status *SubnetGroupStatus) String() string {
+	if status == nil {
+		return ""
+	}
 	return awsrds.DBSubnetGroup(*status).String()
 }
 
@@ -0,0 +1,229 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/rds"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const (
+	DefaultRetryMaxRetries     = 8
+	DefaultRetryMinDelay       = 200 * time.Millisecond
+	DefaultRetryMaxDelay       = 20 * time.Second
+	DefaultRetryMaxElapsedTime = 2 * time.Minute
+
+	// EventualConsistencyMaxElapsedTime bounds the retries of requests waiting for a just created object to become
+	// visible. Objects that really don't exist should not keep us waiting for long.
+	EventualConsistencyMaxElapsedTime = 30 * time.Second
+)
+
+// RetryPolicy tells when and how often to retry a failed AWS request. Throttling and transient errors (timeouts,
+// connection resets, 5xx responses, ...) are always retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+
+	// MinDelay is the delay before the first retry. It doubles with every retry, up to MaxDelay. Delays are
+	// jittered, the actual delay is anywhere between half and all of it.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+
+	// MaxElapsedTime bounds the time from the first attempt to the last retry. If 0, only MaxRetries does.
+	MaxElapsedTime time.Duration
+
+	// Codes are AWS error codes to retry on top of throttling and transient errors, e.g. to wait out eventual
+	// consistency
+	Codes []string
+}
+
+// DefaultRetryPolicy returns the RetryPolicy for all operations without one of their own
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     DefaultRetryMaxRetries,
+		MinDelay:       DefaultRetryMinDelay,
+		MaxDelay:       DefaultRetryMaxDelay,
+		MaxElapsedTime: DefaultRetryMaxElapsedTime,
+	}
+}
+
+// EventualConsistencyRetryPolicies returns RetryPolicies for the operations we call right after creating the objects
+// they refer to, which AWS may not let them see yet: IAM entities that were just created, and KMS keys that were
+// just created for buckets, instances, clusters and read replicas.
+func EventualConsistencyRetryPolicies() map[string]RetryPolicy {
+	policy := func(codes ...string) RetryPolicy {
+		p := DefaultRetryPolicy()
+		p.MaxElapsedTime = EventualConsistencyMaxElapsedTime
+		p.Codes = codes
+		return p
+	}
+	return map[string]RetryPolicy{
+		"iam:AttachRolePolicy":                policy(iam.ErrCodeNoSuchEntityException),
+		"iam:AttachUserPolicy":                policy(iam.ErrCodeNoSuchEntityException),
+		"iam:AttachGroupPolicy":               policy(iam.ErrCodeNoSuchEntityException),
+		"iam:AddUserToGroup":                  policy(iam.ErrCodeNoSuchEntityException),
+		"iam:CreateLoginProfile":              policy(iam.ErrCodeNoSuchEntityException),
+		"iam:CreateAccessKey":                 policy(iam.ErrCodeNoSuchEntityException),
+		"s3:PutBucketEncryption":              policy("KMS.NotFoundException"),
+		"rds:CreateDBInstance":                policy(rds.ErrCodeKMSKeyNotAccessibleFault),
+		"rds:RestoreDBInstanceFromDBSnapshot": policy(rds.ErrCodeKMSKeyNotAccessibleFault),
+		"rds:CreateDBCluster":                 policy(rds.ErrCodeKMSKeyNotAccessibleFault),
+		"rds:CreateDBInstanceReadReplica":     policy(rds.ErrCodeKMSKeyNotAccessibleFault),
+		"rds:RestoreDBClusterFromSnapshot":    policy(rds.ErrCodeKMSKeyNotAccessibleFault),
+	}
+}
+
+// Retryer decides on the retries of failed AWS requests, applying a RetryPolicy per operation. It implements the
+// SDK's request.Retryer, see WithRetryer for installing it.
+type Retryer struct {
+	// Default applies to all operations without a policy in Operations
+	Default RetryPolicy
+
+	// Operations holds the policies of single operations, keyed by "<service>:<operation>" like IAM actions, e.g.
+	// "iam:AttachRolePolicy"
+	Operations map[string]RetryPolicy
+
+	// Logger gets a warning for every retry of requests that aren't made on behalf of a CloudObject with a logger
+	// of its own, if set
+	Logger cloudobject.Logger
+}
+
+// NewRetryer returns a Retryer with the DefaultRetryPolicy and the EventualConsistencyRetryPolicies
+func NewRetryer() *Retryer {
+	return &Retryer{
+		Default:    DefaultRetryPolicy(),
+		Operations: EventualConsistencyRetryPolicies(),
+	}
+}
+
+// WithRetryer installs the Retryer in the given config, so all clients created with it retry accordingly. NewSession
+// does so for all sessions it returns.
+func WithRetryer(cfg *awssdk.Config, retryer *Retryer) *awssdk.Config {
+	// Have the Retryer decide on all errors, not just the ones the SDK doesn't know about
+	cfg.EnforceShouldRetryCheck = awssdk.Bool(true)
+	return request.WithRetryer(cfg, retryer)
+}
+
+// MaxRetries returns the highest number of retries of any of the policies. The policy of the operation itself is
+// enforced by ShouldRetry.
+func (r *Retryer) MaxRetries() int {
+	max := r.Default.MaxRetries
+	for _, policy := range r.Operations {
+		if policy.MaxRetries > max {
+			max = policy.MaxRetries
+		}
+	}
+	return max
+}
+
+// ShouldRetry tells whether the failed request is to be retried, according to the policy of its operation
+func (r *Retryer) ShouldRetry(req *request.Request) bool {
+	policy := r.policy(req)
+	if req.RetryCount >= policy.MaxRetries {
+		return false
+	}
+	if policy.MaxElapsedTime > 0 && time.Since(req.Time) >= policy.MaxElapsedTime {
+		return false
+	}
+	if req.Retryable != nil && *req.Retryable {
+		return true
+	}
+	return req.IsErrorRetryable() || req.IsErrorThrottle() || IsErrorCode(req.Error, policy.Codes...) ||
+		IsErrorCode(req.Error, retryCodes(req.Context())...)
+}
+
+// RetryRules returns the jittered delay before the next retry of the request
+func (r *Retryer) RetryRules(req *request.Request) time.Duration {
+	policy := r.policy(req)
+	delay := policy.MinDelay
+	for i := 0; i < req.RetryCount && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+	if policy.MaxElapsedTime > 0 {
+		if remaining := policy.MaxElapsedTime - time.Since(req.Time); delay > remaining {
+			delay = remaining
+		}
+	}
+	if delay < 0 {
+		delay = 0
+	}
+
+	r.logRetry(req, policy, delay)
+	return delay
+}
+
+// logRetry logs the upcoming retry of the request to the logger of the CloudObject that made it, or to the
+// Retryer's own
+func (r *Retryer) logRetry(req *request.Request, policy RetryPolicy, delay time.Duration) {
+	logger := r.Logger
+	args := []interface{}{"call", operationKey(req)}
+	if op, ok := cloudobject.OperationFromContext(req.Context()); ok && op.Logger != nil {
+		logger = op.Logger
+		args = operationArgs(op, req)
+	}
+	if logger == nil {
+		return
+	}
+	args = append(args, "retry", req.RetryCount+1, "maxRetries", policy.MaxRetries,
+		"delay", delay.Round(time.Millisecond), "error", errorCode(req.Error))
+	logger.WarnContext(req.Context(), "retrying aws call", args...)
+}
+
+func (r *Retryer) policy(req *request.Request) RetryPolicy {
+	if policy, ok := r.Operations[operationKey(req)]; ok {
+		return policy
+	}
+	return r.Default
+}
+
+func operationKey(req *request.Request) string {
+	op := ""
+	if req.Operation != nil {
+		op = req.Operation.Name
+	}
+	return req.ClientInfo.ServiceName + ":" + op
+}
+
+func errorCode(err error) string {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		return aerr.Code()
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+type retryCodesKey struct{}
+
+// WithRetryCodes returns a context that makes the Retryer retry the requests made with it on the given AWS error
+// codes as well, whatever their operation. Use it for reads that are expected to succeed but may not see a just
+// created object yet.
+func WithRetryCodes(ctx context.Context, codes ...string) context.Context {
+	inherited := retryCodes(ctx)
+	all := make([]string, 0, len(inherited)+len(codes))
+	return context.WithValue(ctx, retryCodesKey{}, append(append(all, inherited...), codes...))
+}
+
+func retryCodes(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	codes, _ := ctx.Value(retryCodesKey{}).([]string)
+	return codes
+}
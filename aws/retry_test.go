@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+// failingKMS serves KMS DescribeKey, failing the first failures calls with the given error code
+func failingKMS(t *testing.T, failures int32, code string) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"__type": "%s", "message": "try again"}`, code)
+			return
+		}
+		fmt.Fprint(w, `{"KeyMetadata": {"KeyId": "1234"}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// testLogger keeps the messages logged to it along with their attributes, formatted as "key=value"
+type testLogger struct {
+	logged []string
+}
+
+func (l *testLogger) log(msg string, args ...interface{}) {
+	for i := 0; i+1 < len(args); i += 2 {
+		msg += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	l.logged = append(l.logged, msg)
+}
+
+func (l *testLogger) DebugContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("DEBUG "+msg, args...)
+}
+
+func (l *testLogger) InfoContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("INFO "+msg, args...)
+}
+
+func (l *testLogger) WarnContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("WARN "+msg, args...)
+}
+
+func (l *testLogger) ErrorContext(_ context.Context, msg string, args ...interface{}) {
+	l.log("ERROR "+msg, args...)
+}
+
+func testRetryer(codes ...string) (*Retryer, *[]string) {
+	logger := &testLogger{}
+	policy := RetryPolicy{MaxRetries: 3, MinDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	retryer := &Retryer{
+		Default:    policy,
+		Operations: map[string]RetryPolicy{},
+		Logger:     logger,
+	}
+	if len(codes) > 0 {
+		policy.Codes = codes
+		retryer.Operations["kms:DescribeKey"] = policy
+	}
+	return retryer, &logger.logged
+}
+
+func describeKey(t *testing.T, ctx context.Context, endpoint string, retryer *Retryer) error {
+	sess, err := NewSession(SessionOptions{
+		Region:          "eu-central-1",
+		Endpoint:        endpoint,
+		AccessKeyID:     "test",
+		SecretAccessKey: "testsecret",
+		Retryer:         retryer,
+	})
+	require.NoError(t, err)
+	_, err = kms.New(sess).DescribeKeyWithContext(ctx, &kms.DescribeKeyInput{KeyId: awssdk.String("alias/test")})
+	return err
+}
+
+func TestRetryer_Throttling(t *testing.T) {
+	srv, calls := failingKMS(t, 2, "ThrottlingException")
+	retryer, logged := testRetryer()
+
+	require.NoError(t, describeKey(t, context.Background(), srv.URL, retryer))
+	assert.EqualValues(t, 3, *calls)
+	require.Len(t, *logged, 2)
+	assert.Contains(t, (*logged)[0], "WARN retrying aws call call=kms:DescribeKey retry=1 maxRetries=3")
+	assert.Contains(t, (*logged)[1], "WARN retrying aws call call=kms:DescribeKey retry=2 maxRetries=3")
+	assert.Contains(t, (*logged)[1], "error=ThrottlingException")
+}
+
+func TestRetryer_OperationLogger(t *testing.T) {
+	srv, _ := failingKMS(t, 1, "ThrottlingException")
+	retryer, retryerLogged := testRetryer()
+	logger := &testLogger{}
+
+	ctx := cloudobject.WithOperation(context.Background(), logger, "alias/test", "read")
+	require.NoError(t, describeKey(t, ctx, srv.URL, retryer))
+	assert.Empty(t, *retryerLogged)
+	require.Len(t, logger.logged, 2)
+	assert.Contains(t, logger.logged[0],
+		"WARN retrying aws call id=alias/test operation=read call=kms:DescribeKey retry=1")
+	assert.Contains(t, logger.logged[1], "DEBUG aws call id=alias/test operation=read call=kms:DescribeKey")
+	assert.Contains(t, logger.logged[1], "retries=1")
+}
+
+func TestRetryer_MaxRetries(t *testing.T) {
+	srv, calls := failingKMS(t, 10, "ThrottlingException")
+	retryer, _ := testRetryer()
+
+	assert.True(t, IsErrorCode(describeKey(t, context.Background(), srv.URL, retryer), "ThrottlingException"))
+	assert.EqualValues(t, 4, *calls)
+}
+
+func TestRetryer_MaxElapsedTime(t *testing.T) {
+	srv, calls := failingKMS(t, 100, "ThrottlingException")
+	retryer, _ := testRetryer()
+	retryer.Default.MaxRetries = 100
+	retryer.Default.MaxElapsedTime = 30 * time.Millisecond
+
+	start := time.Now()
+	assert.Error(t, describeKey(t, context.Background(), srv.URL, retryer))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Less(t, *calls, int32(100))
+}
+
+func TestRetryer_Codes(t *testing.T) {
+	// Errors that are neither transient nor throttling are not retried by default...
+	srv, calls := failingKMS(t, 1, kms.ErrCodeNotFoundException)
+	retryer, _ := testRetryer()
+	assert.True(t, IsErrorCode(describeKey(t, context.Background(), srv.URL, retryer), kms.ErrCodeNotFoundException))
+	assert.EqualValues(t, 1, *calls)
+
+	// ...but on the codes of the operation's policy...
+	srv, calls = failingKMS(t, 1, kms.ErrCodeNotFoundException)
+	retryer, _ = testRetryer(kms.ErrCodeNotFoundException)
+	assert.NoError(t, describeKey(t, context.Background(), srv.URL, retryer))
+	assert.EqualValues(t, 2, *calls)
+
+	// ...and on the ones the context asks for
+	srv, calls = failingKMS(t, 1, kms.ErrCodeNotFoundException)
+	retryer, _ = testRetryer()
+	ctx := WithRetryCodes(context.Background(), kms.ErrCodeNotFoundException)
+	assert.NoError(t, describeKey(t, ctx, srv.URL, retryer))
+	assert.EqualValues(t, 2, *calls)
+}
+
+func TestNewRetryer(t *testing.T) {
+	retryer := NewRetryer()
+	assert.Equal(t, DefaultRetryPolicy(), retryer.Default)
+	assert.Contains(t, retryer.Operations["iam:AttachRolePolicy"].Codes, "NoSuchEntity")
+	assert.Equal(t, EventualConsistencyMaxElapsedTime, retryer.Operations["iam:AttachRolePolicy"].MaxElapsedTime)
+	assert.Equal(t, DefaultRetryMaxRetries, retryer.MaxRetries())
+}
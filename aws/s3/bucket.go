@@ -1,12 +1,18 @@
 package s3
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	awsarn "github.com/aws/aws-sdk-go/aws/arn"
 	"github.com/aws/aws-sdk-go/aws/client"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
 	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/redradrat/cloud-objects/aws"
 	"github.com/redradrat/cloud-objects/aws/kms"
 	"github.com/redradrat/cloud-objects/cloudobject"
@@ -15,24 +21,72 @@ import (
 const (
 	BucketTopic     = "bkt"
 	ZeroResultsList = "list-buckets returned 0 results"
+
+	ObjectLockConfigurationNotFoundErrCode      = "ObjectLockConfigurationNotFoundError"
+	NoSuchPublicAccessBlockConfigurationErrCode = "NoSuchPublicAccessBlockConfiguration"
+
+	allUsersGroupURI           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroupURI = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+
+	BucketKind cloudobject.Kind = "bucket"
+
+	AvailableBucketState = "available"
 )
 
 // Bucket represents the S3 Bucket CloudObject
 type Bucket struct {
 	name    string
 	status  BucketStatus
-	session *awss3.S3
+	session s3iface.S3API
+	// kms is the client for the Key the Bucket is encrypted with
+	kms kmsiface.KMSAPI
+	// logger is where the Bucket logs its AWS calls to, if set
+	logger cloudobject.Logger
 }
 
 type BucketStatus struct {
 	awss3.Bucket
 	Encrypted bool
-	ARN       string
+	// SSEAlgorithm and KMSKeyID are the default encryption of the Bucket's objects
+	SSEAlgorithm string
+	KMSKeyID     string
+	ARN          string
 }
 
 func (status BucketStatus) String() string {
 	return status.Bucket.String()
 }
+
+// State of an S3 Bucket is either "available" or empty, as buckets have no lifecycle states
+func (status BucketStatus) State() string {
+	if status.Name == nil {
+		return ""
+	}
+	return AvailableBucketState
+}
+
+// BucketDetails are the key facts of an S3 Bucket, as given in its cloudobject.Summary
+type BucketDetails struct {
+	Name         string     `json:"name" yaml:"name"`
+	CreationDate *time.Time `json:"creationDate,omitempty" yaml:"creationDate,omitempty"`
+	Encrypted    bool       `json:"encrypted" yaml:"encrypted"`
+	SSEAlgorithm string     `json:"sseAlgorithm,omitempty" yaml:"sseAlgorithm,omitempty"`
+	KMSKeyID     string     `json:"kmsKeyID,omitempty" yaml:"kmsKeyID,omitempty"`
+}
+
+func (status BucketStatus) Details() interface{} {
+	if status.Name == nil {
+		return nil
+	}
+	return BucketDetails{
+		Name:         awssdk.StringValue(status.Name),
+		CreationDate: status.CreationDate,
+		Encrypted:    status.Encrypted,
+		SSEAlgorithm: status.SSEAlgorithm,
+		KMSKeyID:     status.KMSKeyID,
+	}
+}
+
 func (status BucketStatus) ProviderID() cloudobject.ProviderID {
 	return cloudobject.ProviderID{
 		Type:  cloudobject.AWSProvider,
@@ -40,6 +94,15 @@ func (status BucketStatus) ProviderID() cloudobject.ProviderID {
 	}
 }
 
+// BucketAvailable is a cloudobject.ReadyFunc for S3 Buckets that are listed and encrypted
+func BucketAvailable(status cloudobject.Status) (bool, error) {
+	bktStatus, ok := status.(BucketStatus)
+	if !ok {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported status"}}
+	}
+	return bktStatus.State() == AvailableBucketState && bktStatus.Encrypted, nil
+}
+
 type BucketSecrets struct{}
 
 func (secrets BucketSecrets) Map() map[string]string {
@@ -53,30 +116,36 @@ func (secrets BucketSecrets) Map() map[string]string {
 /////////////
 
 func (b *Bucket) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return b.CreateWithContext(context.Background(), spec)
+}
+
+// CreateWithContext is the same as Create with the addition of the ability to pass a context
+func (b *Bucket) CreateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, b.logger, b.ID(), "create")
 	var err error
 	var keyFound bool
 
 	// Check whether encryption key already exists
 	var key *kms.Key
-	key, err = kmsKeySession(b)
+	key, err = b.key()
 	if err != nil {
 		return nil, err
 	}
-	keyFound, _ = key.Exists()
+	keyFound, _ = key.ExistsWithContext(ctx)
 
 	// It's fair to assume, that we get an S3 BucketSpec here.
 	assertedSpec, ok := spec.(*BucketSpec)
 	if !ok {
 		// If not, we're throwing an error here... ya done messed up.
-		return nil, cloudobject.SpecInvalidError{Message: "got unsupported spec"}
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
 	}
 
 	// If the S3 Bucket already exists, we're done here... you're trying to play us for a fool!
-	exists, _ := b.Exists()
+	exists, _ := b.ExistsWithContext(ctx)
 	if !exists {
 		// So now we should be good to go ahead with Bucket creation
 		input := assertedSpec.CreateBucketInput(b.ID().String())
-		_, err = b.session.CreateBucket(&input)
+		_, err = b.session.CreateBucketWithContext(ctx, &input)
 		if err != nil {
 			return nil, err
 		}
@@ -84,14 +153,14 @@ func (b *Bucket) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets,
 
 	// As there are a few post-creation settings we call our bucket config helper. This is externalized to serve for
 	// Update() as well.
-	err = ensureBucketConfig(assertedSpec, b)
+	err = ensureBucketConfig(ctx, assertedSpec, b)
 	if err != nil {
 		return nil, err
 	}
 
 	// Ensure Bucket Encryption
 	if !keyFound {
-		_, err := key.Create(&kms.KeySpec{
+		_, err := key.CreateWithContext(ctx, &kms.KeySpec{
 			KeyUsage: kms.EncryptDecryptKeyUsage,
 			KeyType:  kms.SymmetricDefaultKeyType,
 		})
@@ -100,7 +169,7 @@ func (b *Bucket) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets,
 		}
 	}
 	encinput := assertedSpec.PutBucketEncryptionInput(b.ID().String(), key)
-	_, err = b.session.PutBucketEncryption(&encinput)
+	_, err = b.session.PutBucketEncryptionWithContext(ctx, &encinput)
 	if err != nil {
 		return nil, err
 	}
@@ -109,45 +178,38 @@ func (b *Bucket) Create(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets,
 	return nil, nil
 }
 
-func kmsKeySession(b *Bucket) (*kms.Key, error) {
-	kmsSession, err := session.NewSession(&b.session.Config)
-	if err != nil {
-		return nil, err
-	}
-	key, err := kms.NewKey(b.name, kmsSession)
-	if err != nil {
-		return nil, err
-	}
-	return key, nil
+// key returns the Key the Bucket is encrypted with
+func (b *Bucket) key() (*kms.Key, error) {
+	return kms.NewKeyWithClient(b.name, b.kms)
 }
 
-func ensureBucketConfig(assertedSpec *BucketSpec, b *Bucket) error {
+func ensureBucketConfig(ctx context.Context, assertedSpec *BucketSpec, b *Bucket) error {
 	var err error
 
 	// Ensure Bucket ACL
 	aclinput := assertedSpec.PutBucketAclInput(b.ID().String())
-	_, err = b.session.PutBucketAcl(&aclinput)
+	_, err = b.session.PutBucketAclWithContext(ctx, &aclinput)
 	if err != nil {
 		return err
 	}
 
 	// Ensure Bucket Versioning
 	versinput := assertedSpec.PutBucketVersioningInput(b.ID().String())
-	_, err = b.session.PutBucketVersioning(&versinput)
+	_, err = b.session.PutBucketVersioningWithContext(ctx, &versinput)
 	if err != nil {
 		return err
 	}
 
 	// Ensure Bucket Transfer Acceleration
 	accelinput := assertedSpec.PutBucketAccelerationInput(b.ID().String())
-	_, err = b.session.PutBucketAccelerateConfiguration(&accelinput)
+	_, err = b.session.PutBucketAccelerateConfigurationWithContext(ctx, &accelinput)
 	if err != nil {
 		return err
 	}
 
 	// Ensure Bucket Public Block
 	blockinput := assertedSpec.PutPublicAccessBlockInput(b.ID().String())
-	_, err = b.session.PutPublicAccessBlock(&blockinput)
+	_, err = b.session.PutPublicAccessBlockWithContext(ctx, &blockinput)
 	if err != nil {
 		return err
 	}
@@ -156,14 +218,24 @@ func ensureBucketConfig(assertedSpec *BucketSpec, b *Bucket) error {
 }
 
 func (b *Bucket) Read() error {
+	return b.ReadWithContext(context.Background())
+}
+
+// ReadWithContext is the same as Read with the addition of the ability to pass a context
+func (b *Bucket) ReadWithContext(ctx context.Context) error {
+	ctx = cloudobject.WithOperation(ctx, b.logger, b.ID(), "read")
 	// Call AWS to describe our S3 Bucket
-	out, err := b.session.ListBuckets(&awss3.ListBucketsInput{})
+	out, err := b.session.ListBucketsWithContext(ctx, &awss3.ListBucketsInput{})
 	if err != nil {
 		return err
 	}
 	// If our output bucket list is 0, there is literally no buckets and thus also not what we're looking for
 	if len(out.Buckets) == 0 {
-		return cloudobject.NotExistsError{Message: fmt.Sprintf(ZeroResultsList)}
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: ZeroResultsList,
+			ID:      b.ID(),
+			Op:      "read",
+		}}
 	}
 
 	// Range over all returned bucket objects to find ours. If not found, we error here.
@@ -174,7 +246,11 @@ func (b *Bucket) Read() error {
 		}
 	}
 	if foundBucket == nil {
-		return cloudobject.NotExistsError{Message: fmt.Sprintf("Bucket with id '%s' not found", b.ID().String())}
+		return cloudobject.NotExistsError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("Bucket with id '%s' not found", b.ID().String()),
+			ID:      b.ID(),
+			Op:      "read",
+		}}
 	}
 
 	// Set the status to what we get from the object. In bucket case this is just the name basically. So not really
@@ -183,33 +259,57 @@ func (b *Bucket) Read() error {
 
 	// Construct the ARN for status
 	b.status.ARN = awsarn.ARN{
-		Partition: b.session.PartitionID,
-		Service:   b.session.ServiceID,
+		Partition: partition(b.session),
+		Service:   awss3.ServiceID,
 		Resource:  b.ID().String(),
 	}.String()
 
-	enc, err := b.session.GetBucketEncryption(&awss3.GetBucketEncryptionInput{
+	enc, err := b.session.GetBucketEncryptionWithContext(ctx, &awss3.GetBucketEncryptionInput{
 		Bucket: b.ID().StringPtr(),
 	})
 	if err != nil {
 		return err
 	} else {
-		b.status.Encrypted = len(enc.ServerSideEncryptionConfiguration.Rules) != 0
+		rules := enc.ServerSideEncryptionConfiguration.Rules
+		b.status.Encrypted = len(rules) != 0
+		b.status.SSEAlgorithm, b.status.KMSKeyID = "", ""
+		if b.status.Encrypted && rules[0].ApplyServerSideEncryptionByDefault != nil {
+			b.status.SSEAlgorithm = awssdk.StringValue(rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+			b.status.KMSKeyID = awssdk.StringValue(rules[0].ApplyServerSideEncryptionByDefault.KMSMasterKeyID)
+		}
 	}
 
 	return nil
 }
 
 func (b *Bucket) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	return b.UpdateWithContext(context.Background(), spec)
+}
+
+// UpdateWithContext is the same as Update with the addition of the ability to pass a context
+func (b *Bucket) UpdateWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Secrets, error) {
+	ctx = cloudobject.WithOperation(ctx, b.logger, b.ID(), "update")
 	// It's fair to assume, that we get an S3 BucketSpec here.
 	assertedSpec, ok := spec.(*BucketSpec)
 	if !ok {
 		// If not, we're throwing an error here... ya done messed up.
-		return nil, cloudobject.SpecInvalidError{Message: "got unsupported spec"}
+		return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "got unsupported spec"}}
+	}
+
+	// Only touch the config if it actually drifted
+	diff, err := b.PlanWithContext(ctx, assertedSpec)
+	if err != nil {
+		return nil, err
+	}
+	if err := diff.ImmutableChangesError(); err != nil {
+		return nil, err
+	}
+	if !diff.HasChanges() {
+		return nil, nil
 	}
 
 	// Ensure updatable config is set
-	err := ensureBucketConfig(assertedSpec, b)
+	err = ensureBucketConfig(ctx, assertedSpec, b)
 	if err != nil {
 		return nil, err
 	}
@@ -217,10 +317,101 @@ func (b *Bucket) Update(spec cloudobject.CloudObjectSpec) (cloudobject.Secrets,
 	return nil, nil
 }
 
+// Plan compares the given spec against the live S3 Bucket config, without changing anything
+func (b *Bucket) Plan(spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	return b.PlanWithContext(context.Background(), spec)
+}
+
+// PlanWithContext is the same as Plan with the addition of the ability to pass a context
+func (b *Bucket) PlanWithContext(ctx context.Context, spec cloudobject.CloudObjectSpec) (cloudobject.Diff, error) {
+	ctx = cloudobject.WithOperation(ctx, b.logger, b.ID(), "plan")
+	assertedSpec, ok := spec.(*BucketSpec)
+	if !ok {
+		return cloudobject.Diff{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "got unsupported spec",
+		}}
+	}
+	exists, err := b.ExistsWithContext(ctx)
+	if err != nil {
+		return cloudobject.Diff{}, err
+	}
+	if !exists {
+		return cloudobject.NewCreateDiff(b.ID()), nil
+	}
+
+	live, err := readBucketSpec(ctx, b)
+	if err != nil {
+		return cloudobject.Diff{}, err
+	}
+	return assertedSpec.Diff(b.ID(), live), nil
+}
+
+// readBucketSpec reads the live config of the bucket back into a BucketSpec, as far as S3 lets us
+func readBucketSpec(ctx context.Context, b *Bucket) (BucketSpec, error) {
+	var live BucketSpec
+	id := b.ID().StringPtr()
+
+	loc, err := b.session.GetBucketLocationWithContext(ctx, &awss3.GetBucketLocationInput{Bucket: id})
+	if err != nil {
+		return live, err
+	}
+	live.Location = awss3.NormalizeBucketLocation(awssdk.StringValue(loc.LocationConstraint))
+
+	acl, err := b.session.GetBucketAclWithContext(ctx, &awss3.GetBucketAclInput{Bucket: id})
+	if err != nil {
+		return live, err
+	}
+	live.ACL = cannedACL(acl.Grants)
+
+	vers, err := b.session.GetBucketVersioningWithContext(ctx, &awss3.GetBucketVersioningInput{Bucket: id})
+	if err != nil {
+		return live, err
+	}
+	live.Versioning = awssdk.StringValue(vers.Status) == awss3.BucketVersioningStatusEnabled
+
+	accel, err := b.session.GetBucketAccelerateConfigurationWithContext(ctx,
+		&awss3.GetBucketAccelerateConfigurationInput{Bucket: id})
+	if err != nil {
+		return live, err
+	}
+	live.TransferAcceleration = awssdk.StringValue(accel.Status) == awss3.BucketAccelerateStatusEnabled
+
+	// Buckets without object lock or public access block config return an error instead of an empty config
+	lock, err := b.session.GetObjectLockConfigurationWithContext(ctx,
+		&awss3.GetObjectLockConfigurationInput{Bucket: id})
+	if err != nil && !aws.IsErrorCode(err, ObjectLockConfigurationNotFoundErrCode) {
+		return live, err
+	}
+	if err == nil && lock.ObjectLockConfiguration != nil {
+		live.ObjectLock = awssdk.StringValue(lock.ObjectLockConfiguration.ObjectLockEnabled) ==
+			awss3.ObjectLockEnabledEnabled
+	}
+
+	block, err := b.session.GetPublicAccessBlockWithContext(ctx, &awss3.GetPublicAccessBlockInput{Bucket: id})
+	if err != nil && !aws.IsErrorCode(err, NoSuchPublicAccessBlockConfigurationErrCode) {
+		return live, err
+	}
+	if err == nil && block.PublicAccessBlockConfiguration != nil {
+		conf := block.PublicAccessBlockConfiguration
+		live.BlockPublicAcls = awssdk.BoolValue(conf.BlockPublicAcls)
+		live.IgnorePublicAcls = awssdk.BoolValue(conf.IgnorePublicAcls)
+		live.BlockPublicPolicy = awssdk.BoolValue(conf.BlockPublicPolicy)
+		live.RestrictPublicBuckets = awssdk.BoolValue(conf.RestrictPublicBuckets)
+	}
+
+	return live, nil
+}
+
 // Delete deletes the bucket. Purge has no effect, bucket will always be purged.
-func (b *Bucket) Delete(_ bool) error {
+func (b *Bucket) Delete(purge bool) error {
+	return b.DeleteWithContext(context.Background(), purge)
+}
+
+// DeleteWithContext is the same as Delete with the addition of the ability to pass a context
+func (b *Bucket) DeleteWithContext(ctx context.Context, _ bool) error {
+	ctx = cloudobject.WithOperation(ctx, b.logger, b.ID(), "delete")
 	// First, let's check whether our bucket actually exists
-	exists, err := b.Exists()
+	exists, err := b.ExistsWithContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -236,7 +427,8 @@ func (b *Bucket) Delete(_ bool) error {
 	}
 
 	// Now let's go for it... delete that naughty Bucket!! (kill it with fire, pwetty please)
-	if _, err := b.session.DeleteBucket(&input); cloudobject.IgnoreNotExistsError(err) != nil {
+	if _, err := b.session.DeleteBucketWithContext(ctx, &input); err != nil &&
+		!aws.IsErrorCode(err, awss3.ErrCodeNoSuchBucket) {
 		return err
 	}
 
@@ -252,10 +444,24 @@ func (b *Bucket) ID() cloudobject.ID {
 	return cloudobject.ID(aws.CloudObjectResource(BucketTopic, b.name))
 }
 
+// References returns the ID of the KMS Key the Bucket is encrypted with
+func (b *Bucket) References(_ cloudobject.CloudObjectSpec) []cloudobject.ID {
+	return []cloudobject.ID{kms.KeyID(b.name)}
+}
+
 func (b *Bucket) Exists() (bool, error) {
 	return cloudobject.Exists(b)
 }
 
+func (b *Bucket) ExistsWithContext(ctx context.Context) (bool, error) {
+	return cloudobject.ExistsWithContext(ctx, b)
+}
+
+// SetLogger makes the Bucket log its AWS calls to the given logger
+func (b *Bucket) SetLogger(logger cloudobject.Logger) {
+	b.logger = logger
+}
+
 ////////////
 /// SPEC ///
 ////////////
@@ -311,12 +517,33 @@ func (b *BucketSpec) Valid() (bool, error) {
 	return true, nil
 }
 
+// Diff compares the spec field by field against the live config of a bucket. Location and ObjectLock can only be
+// set on creation.
+func (b BucketSpec) Diff(id cloudobject.ID, live BucketSpec) cloudobject.Diff {
+	diff := cloudobject.Diff{ID: id}
+	diff.CompareImmutable("Location", live.Location, awss3.NormalizeBucketLocation(b.Location))
+	diff.CompareImmutable("ObjectLock", live.ObjectLock, b.ObjectLock)
+	diff.Compare("ACL", live.ACL, b.ACL)
+	diff.Compare("Versioning", live.Versioning, b.Versioning)
+	diff.Compare("TransferAcceleration", live.TransferAcceleration, b.TransferAcceleration)
+	diff.Compare("BlockPublicAcls", live.BlockPublicAcls, b.BlockPublicAcls)
+	diff.Compare("IgnorePublicAcls", live.IgnorePublicAcls, b.IgnorePublicAcls)
+	diff.Compare("BlockPublicPolicy", live.BlockPublicPolicy, b.BlockPublicPolicy)
+	diff.Compare("RestrictPublicBuckets", live.RestrictPublicBuckets, b.RestrictPublicBuckets)
+	return diff
+}
+
 ///////////////
 /// HELPERS ///
 ///////////////
 
 // NewInstance returns a new S3 Bucket object
 func NewBucket(name string, session client.ConfigProvider) (*Bucket, error) {
+	return NewBucketWithClients(name, awss3.New(session), awskms.New(session))
+}
+
+// NewBucketWithClients is the same as NewBucket, but uses the given S3 and KMS clients instead of creating its own
+func NewBucketWithClients(name string, svc s3iface.S3API, kmsSvc kmsiface.KMSAPI) (*Bucket, error) {
 	if len(name) == 0 {
 		return nil, fmt.Errorf("given name is empty")
 	}
@@ -326,12 +553,22 @@ func NewBucket(name string, session client.ConfigProvider) (*Bucket, error) {
 
 	bucket := Bucket{
 		name:    name,
-		session: awss3.New(session),
+		session: svc,
+		kms:     kmsSvc,
 	}
 
 	return &bucket, nil
 }
 
+// partition returns the AWS partition the given client talks to. Clients other than the SDK's own, like mocks, are
+// taken to talk to the standard partition.
+func partition(svc s3iface.S3API) string {
+	if c, ok := svc.(*awss3.S3); ok {
+		return c.PartitionID
+	}
+	return endpoints.AwsPartitionID
+}
+
 func (b BucketSpec) CreateBucketInput(id string) awss3.CreateBucketInput {
 	in := awss3.CreateBucketInput{
 		Bucket:                     awssdk.String(id),
@@ -410,3 +647,44 @@ func (b BucketSpec) PutPublicAccessBlockInput(id string) awss3.PutPublicAccessBl
 	}
 	return in
 }
+
+// cannedACL maps the grants of a bucket back to the canned ACL that produces them. Grants that don't match any
+// canned ACL return an empty string.
+func cannedACL(grants []*awss3.Grant) string {
+	var allUsersRead, allUsersWrite, authUsersRead, others bool
+	for _, grant := range grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		uri := awssdk.StringValue(grant.Grantee.URI)
+		permission := awssdk.StringValue(grant.Permission)
+		switch {
+		case uri == allUsersGroupURI && permission == awss3.PermissionRead:
+			allUsersRead = true
+		case uri == allUsersGroupURI && permission == awss3.PermissionWrite:
+			allUsersWrite = true
+		case uri == authenticatedUsersGroupURI && permission == awss3.PermissionRead:
+			authUsersRead = true
+		case awssdk.StringValue(grant.Grantee.Type) == awss3.TypeCanonicalUser &&
+			permission == awss3.PermissionFullControl:
+			// That's the owner
+		default:
+			others = true
+		}
+	}
+
+	switch {
+	case others:
+		return ""
+	case allUsersRead && allUsersWrite && !authUsersRead:
+		return awss3.BucketCannedACLPublicReadWrite
+	case allUsersRead && !allUsersWrite && !authUsersRead:
+		return awss3.BucketCannedACLPublicRead
+	case authUsersRead && !allUsersRead && !allUsersWrite:
+		return awss3.BucketCannedACLAuthenticatedRead
+	case !allUsersRead && !allUsersWrite && !authUsersRead:
+		return awss3.BucketCannedACLPrivate
+	default:
+		return ""
+	}
+}
@@ -1,693 +1,283 @@
 package s3
 
 import (
-	"github.com/aws/aws-sdk-go/aws/client"
+	"strings"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/aws/fake"
 	"github.com/redradrat/cloud-objects/aws/kms"
 	"github.com/redradrat/cloud-objects/cloudobject"
-	"reflect"
-	"testing"
 )
 
-func TestBucketSecrets_Map(t *testing.T) {
-	tests := []struct {
-		name string
-		want map[string]string
-	}{
-		// TODO: Add test cases.
+func TestNewBucket(t *testing.T) {
+	backend := fake.NewBackend()
+	if _, err := NewBucket("", backend.Session()); err == nil {
+		t.Errorf("NewBucket() with empty name, want error")
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			secrets := BucketSecrets{}
-			if got := secrets.Map(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Map() = %v, want %v", got, tt.want)
-			}
-		})
+	if _, err := NewBucket(strings.Repeat("a", 201), backend.Session()); err == nil {
+		t.Errorf("NewBucket() with too long name, want error")
 	}
-}
-
-func TestBucketSpec_CreateBucketInput(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
-	}
-	type args struct {
-		id string
+	b, err := NewBucket("mybucket", backend.Session())
+	if err != nil {
+		t.Fatalf("NewBucket() error = %v", err)
 	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   awss3.CreateBucketInput
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			if got := b.CreateBucketInput(tt.args.id); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("CreateBucketInput() = %v, want %v", got, tt.want)
-			}
-		})
+	if got := b.ID(); got != "clobjx-bkt-mybucket" {
+		t.Errorf("ID() = %v, want clobjx-bkt-mybucket", got)
 	}
 }
 
-func TestBucketSpec_PutBucketAccelerationInput(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
-	}
-	type args struct {
-		id string
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   awss3.PutBucketAccelerateConfigurationInput
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			if got := b.PutBucketAccelerationInput(tt.args.id); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("PutBucketAccelerationInput() = %v, want %v", got, tt.want)
-			}
-		})
-	}
+// countingS3Client wraps an S3 client, counting the buckets created through it
+type countingS3Client struct {
+	s3iface.S3API
+	created int
 }
 
-func TestBucketSpec_PutBucketAclInput(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
-	}
-	type args struct {
-		id string
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   awss3.PutBucketAclInput
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			if got := b.PutBucketAclInput(tt.args.id); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("PutBucketAclInput() = %v, want %v", got, tt.want)
-			}
-		})
-	}
+func (c *countingS3Client) CreateBucketWithContext(ctx awssdk.Context, input *awss3.CreateBucketInput, opts ...request.Option) (*awss3.CreateBucketOutput, error) {
+	c.created++
+	return c.S3API.CreateBucketWithContext(ctx, input, opts...)
 }
 
-func TestBucketSpec_PutBucketEncryptionInput(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
-	}
-	type args struct {
-		id  string
-		key *kms.Key
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   awss3.PutBucketEncryptionInput
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			if got := b.PutBucketEncryptionInput(tt.args.id, tt.args.key); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("PutBucketEncryptionInput() = %v, want %v", got, tt.want)
-			}
-		})
+func TestNewBucketWithClients(t *testing.T) {
+	clients := aws.NewClients(fake.NewBackend().Session())
+	svc := &countingS3Client{S3API: clients.S3}
+	spec := SaneS3Bucket()
+
+	for _, name := range []string{"first", "second"} {
+		b, err := NewBucketWithClients(name, svc, clients.KMS)
+		if err != nil {
+			t.Fatalf("NewBucketWithClients() error = %v", err)
+		}
+		if _, err := b.Create(&spec); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if err := b.Read(); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if got, want := b.Status().ProviderID().Value, "arn:aws:S3:::"+b.ID().String(); got != want {
+			t.Errorf("ProviderID() = %v, want %v", got, want)
+		}
+
+		// The Key is created with the shared KMS client
+		key, err := kms.NewKeyWithClient(name, clients.KMS)
+		if err != nil {
+			t.Fatalf("NewKeyWithClient() error = %v", err)
+		}
+		if exists, err := key.Exists(); err != nil || !exists {
+			t.Errorf("Key Exists() = %v, %v, want true", exists, err)
+		}
+	}
+	if svc.created != 2 {
+		t.Errorf("created = %v, want 2", svc.created)
 	}
 }
 
-func TestBucketSpec_PutBucketVersioningInput(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
-	}
-	type args struct {
-		id string
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   awss3.PutBucketVersioningInput
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			if got := b.PutBucketVersioningInput(tt.args.id); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("PutBucketVersioningInput() = %v, want %v", got, tt.want)
-			}
-		})
+func TestBucket_Create(t *testing.T) {
+	backend := fake.NewBackend()
+	b, err := NewBucket("mybucket", backend.Session())
+	if err != nil {
+		t.Fatalf("NewBucket() error = %v", err)
 	}
-}
+	spec := SaneS3Bucket()
 
-func TestBucketSpec_PutPublicAccessBlockInput(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
-	}
-	type args struct {
-		id string
+	if _, err := b.Create(&spec); err != nil {
+		t.Fatalf("Create() error = %v", err)
 	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   awss3.PutPublicAccessBlockInput
-	}{
-		// TODO: Add test cases.
+	if err := b.Read(); err != nil {
+		t.Fatalf("Read() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			if got := b.PutPublicAccessBlockInput(tt.args.id); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("PutPublicAccessBlockInput() = %v, want %v", got, tt.want)
-			}
-		})
+	if ready, err := BucketAvailable(b.Status()); err != nil || !ready {
+		t.Errorf("BucketAvailable() = %v, %v, want true", ready, err)
+	}
+	if got := b.Status().ProviderID().Value; got != "arn:aws:S3:::clobjx-bkt-mybucket" {
+		t.Errorf("ProviderID() = %v, want arn:aws:S3:::clobjx-bkt-mybucket", got)
 	}
-}
 
-func TestBucketSpec_Valid(t *testing.T) {
-	type fields struct {
-		Location              string
-		ACL                   string
-		ObjectLock            bool
-		Versioning            bool
-		TransferAcceleration  bool
-		BlockPublicAcls       bool
-		IgnorePublicAcls      bool
-		BlockPublicPolicy     bool
-		RestrictPublicBuckets bool
+	// The Bucket is encrypted with its own Key
+	key, err := kms.NewKey("mybucket", backend.Session())
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
 	}
-	tests := []struct {
-		name    string
-		fields  fields
-		want    bool
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+	if exists, err := key.Exists(); err != nil || !exists {
+		t.Errorf("Key Exists() = %v, %v, want true", exists, err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := BucketSpec{
-				Location:              tt.fields.Location,
-				ACL:                   tt.fields.ACL,
-				ObjectLock:            tt.fields.ObjectLock,
-				Versioning:            tt.fields.Versioning,
-				TransferAcceleration:  tt.fields.TransferAcceleration,
-				BlockPublicAcls:       tt.fields.BlockPublicAcls,
-				IgnorePublicAcls:      tt.fields.IgnorePublicAcls,
-				BlockPublicPolicy:     tt.fields.BlockPublicPolicy,
-				RestrictPublicBuckets: tt.fields.RestrictPublicBuckets,
-			}
-			got, err := b.Valid()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Valid() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("Valid() got = %v, want %v", got, tt.want)
-			}
-		})
+	details, ok := cloudobject.Summarize(BucketKind, b).Details.(BucketDetails)
+	if !ok || !details.Encrypted || details.SSEAlgorithm != awss3.ServerSideEncryptionAwsKms ||
+		details.KMSKeyID != key.ID().String() {
+		t.Errorf("Summarize() details = %+v, want encryption with %v", details, key.ID())
 	}
-}
 
-func TestBucketStatus_ProviderID(t *testing.T) {
-	type fields struct {
-		Bucket    awss3.Bucket
-		Encrypted bool
-		ARN       string
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		want   cloudobject.ProviderID
-	}{
-		// TODO: Add test cases.
+	diff, err := b.Plan(&spec)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			status := BucketStatus{
-				Bucket:    tt.fields.Bucket,
-				Encrypted: tt.fields.Encrypted,
-				ARN:       tt.fields.ARN,
-			}
-			if got := status.ProviderID(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("ProviderID() = %v, want %v", got, tt.want)
-			}
-		})
+	if diff.HasChanges() {
+		t.Errorf("Plan() after Create() = %v, want no changes", diff)
 	}
-}
 
-func TestBucketStatus_String(t *testing.T) {
-	type fields struct {
-		Bucket    awss3.Bucket
-		Encrypted bool
-		ARN       string
+	// Creating an existing Bucket just makes sure its config is right
+	spec.ACL = awss3.BucketCannedACLPublicRead
+	if _, err := b.Create(&spec); err != nil {
+		t.Fatalf("Create() of existing Bucket error = %v", err)
 	}
-	tests := []struct {
-		name   string
-		fields fields
-		want   string
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			status := BucketStatus{
-				Bucket:    tt.fields.Bucket,
-				Encrypted: tt.fields.Encrypted,
-				ARN:       tt.fields.ARN,
-			}
-			if got := status.String(); got != tt.want {
-				t.Errorf("String() = %v, want %v", got, tt.want)
-			}
-		})
+	if diff, _ := b.Plan(&spec); diff.HasChanges() {
+		t.Errorf("Plan() after second Create() = %v, want no changes", diff)
 	}
 }
 
-func TestBucket_Create(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
-	}
-	type args struct {
-		spec cloudobject.CloudObjectSpec
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    cloudobject.Secrets
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+func TestBucket_Update(t *testing.T) {
+	backend := fake.NewBackend()
+	b, err := NewBucket("mybucket", backend.Session())
+	if err != nil {
+		t.Fatalf("NewBucket() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			got, err := b.Create(tt.args.spec)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Create() got = %v, want %v", got, tt.want)
-			}
-		})
+	spec := SaneS3Bucket()
+	if _, err := b.Create(&spec); err != nil {
+		t.Fatalf("Create() error = %v", err)
 	}
-}
 
-func TestBucket_Delete(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
+	spec.ACL = awss3.BucketCannedACLAuthenticatedRead
+	spec.TransferAcceleration = true
+	spec.BlockPublicAcls = true
+	diff, err := b.Plan(&spec)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
 	}
-	type args struct {
-		in0 bool
+	if got := len(diff.Changes); got != 3 {
+		t.Errorf("len(Changes) = %v, want 3", got)
 	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+	if _, err := b.Update(&spec); err != nil {
+		t.Fatalf("Update() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			if err := b.Delete(tt.args.in0); (err != nil) != tt.wantErr {
-				t.Errorf("Delete() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
+	if diff, _ := b.Plan(&spec); diff.HasChanges() {
+		t.Errorf("Plan() after Update() = %v, want no changes", diff)
 	}
-}
 
-func TestBucket_Exists(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		want    bool
-		wantErr bool
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			got, err := b.Exists()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Exists() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("Exists() got = %v, want %v", got, tt.want)
-			}
-		})
+	// Object lock can only be set on creation
+	spec.ObjectLock = false
+	if _, err := b.Update(&spec); err == nil {
+		t.Errorf("Update() of ObjectLock, want error")
 	}
 }
 
-func TestBucket_ID(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		want   cloudobject.ID
-	}{
-		// TODO: Add test cases.
+func TestBucket_Delete(t *testing.T) {
+	backend := fake.NewBackend()
+	b, err := NewBucket("mybucket", backend.Session())
+	if err != nil {
+		t.Fatalf("NewBucket() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			if got := b.ID(); got != tt.want {
-				t.Errorf("ID() = %v, want %v", got, tt.want)
-			}
-		})
+	spec := SaneS3Bucket()
+	if _, err := b.Create(&spec); err != nil {
+		t.Fatalf("Create() error = %v", err)
 	}
-}
 
-func TestBucket_Read(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
+	if err := b.Delete(true); err != nil {
+		t.Fatalf("Delete() error = %v", err)
 	}
-	tests := []struct {
-		name    string
-		fields  fields
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+	if exists, err := b.Exists(); err != nil || exists {
+		t.Errorf("Exists() after Delete() = %v, %v, want false", exists, err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			if err := b.Read(); (err != nil) != tt.wantErr {
-				t.Errorf("Read() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
+	if err := b.Read(); !cloudobject.IsNotExistsError(err) {
+		t.Errorf("Read() after Delete() error = %v, want NotExistsError", err)
+	}
+	// Deleting a deleted Bucket is fine
+	if err := b.Delete(true); err != nil {
+		t.Errorf("second Delete() error = %v", err)
 	}
-}
 
-func TestBucket_Status(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
+	// Recreating the Bucket reuses its Key
+	if _, err := b.Create(&spec); err != nil {
+		t.Fatalf("Create() after Delete() error = %v", err)
 	}
-	tests := []struct {
-		name   string
-		fields fields
-		want   cloudobject.Status
-	}{
-		// TODO: Add test cases.
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			if got := b.Status(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Status() = %v, want %v", got, tt.want)
-			}
-		})
+	if err := b.Read(); err != nil || !b.Status().(BucketStatus).Encrypted {
+		t.Errorf("Read() after recreation = %v, Encrypted %v, want encrypted", err, b.Status().(BucketStatus).Encrypted)
 	}
 }
 
-func TestBucket_Update(t *testing.T) {
-	type fields struct {
-		name    string
-		status  BucketStatus
-		session *awss3.S3
-	}
-	type args struct {
-		spec cloudobject.CloudObjectSpec
+func TestBucket_Read(t *testing.T) {
+	backend := fake.NewBackend()
+	b, err := NewBucket("mybucket", backend.Session())
+	if err != nil {
+		t.Fatalf("NewBucket() error = %v", err)
 	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    cloudobject.Secrets
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+	if err := b.Read(); !cloudobject.IsNotExistsError(err) {
+		t.Errorf("Read() of missing Bucket error = %v, want NotExistsError", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Bucket{
-				name:    tt.fields.name,
-				status:  tt.fields.status,
-				session: tt.fields.session,
-			}
-			got, err := b.Update(tt.args.spec)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("Update() got = %v, want %v", got, tt.want)
-			}
-		})
+	if got := b.Status().State(); got != "" {
+		t.Errorf("State() of missing Bucket = %v, want empty", got)
 	}
-}
 
-func TestNewBucket(t *testing.T) {
-	type args struct {
-		name    string
-		session client.ConfigProvider
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    *Bucket
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+	other, _ := NewBucket("other", backend.Session())
+	spec := SaneS3Bucket()
+	if _, err := other.Create(&spec); err != nil {
+		t.Fatalf("Create() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewBucket(tt.args.name, tt.args.session)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("NewBucket() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewBucket() got = %v, want %v", got, tt.want)
-			}
-		})
+	if err := b.Read(); !cloudobject.IsNotExistsError(err) {
+		t.Errorf("Read() of missing Bucket next to another error = %v, want NotExistsError", err)
 	}
-}
-
-func TestSaneS3Bucket(t *testing.T) {
-	tests := []struct {
-		name string
-		want BucketSpec
-	}{
-		// TODO: Add test cases.
+	if err := other.Read(); err != nil {
+		t.Errorf("Read() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := SaneS3Bucket(); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("SaneS3Bucket() = %v, want %v", got, tt.want)
-			}
-		})
+	if got := awssdk.StringValue(other.Status().(BucketStatus).Name); got != "clobjx-bkt-other" {
+		t.Errorf("Status().Name = %v, want clobjx-bkt-other", got)
 	}
 }
 
-func Test_ensureBucketConfig(t *testing.T) {
-	type args struct {
-		assertedSpec *BucketSpec
-		b            *Bucket
+func TestBucketSpec_Diff(t *testing.T) {
+	spec := SaneS3Bucket()
+	live := SaneS3Bucket()
+	live.Location = "us-east-1"
+
+	diff := spec.Diff("bkt", live)
+	if diff.HasChanges() {
+		t.Errorf("Diff() = %v, want no changes", diff)
 	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+
+	live.Versioning = false
+	live.ObjectLock = false
+	diff = spec.Diff("bkt", live)
+	if got := diff.Action(); got != cloudobject.ReplaceDiffAction {
+		t.Errorf("Action() = %v, want %v", got, cloudobject.ReplaceDiffAction)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := ensureBucketConfig(tt.args.assertedSpec, tt.args.b); (err != nil) != tt.wantErr {
-				t.Errorf("ensureBucketConfig() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
+	if got := len(diff.Changes); got != 2 {
+		t.Errorf("len(Changes) = %v, want 2", got)
 	}
 }
 
-func Test_kmsKeySession(t *testing.T) {
-	type args struct {
-		b *Bucket
+func Test_cannedACL(t *testing.T) {
+	owner := &awss3.Grant{
+		Grantee:    &awss3.Grantee{Type: awssdk.String(awss3.TypeCanonicalUser), ID: awssdk.String("owner")},
+		Permission: awssdk.String(awss3.PermissionFullControl),
+	}
+	group := func(uri, permission string) *awss3.Grant {
+		return &awss3.Grant{
+			Grantee:    &awss3.Grantee{Type: awssdk.String(awss3.TypeGroup), URI: awssdk.String(uri)},
+			Permission: awssdk.String(permission),
+		}
 	}
 	tests := []struct {
-		name    string
-		args    args
-		want    *kms.Key
-		wantErr bool
+		name   string
+		grants []*awss3.Grant
+		want   string
 	}{
-		// TODO: Add test cases.
+		{"private", []*awss3.Grant{owner}, awss3.BucketCannedACLPrivate},
+		{"public-read", []*awss3.Grant{owner, group(allUsersGroupURI, awss3.PermissionRead)},
+			awss3.BucketCannedACLPublicRead},
+		{"public-read-write", []*awss3.Grant{owner, group(allUsersGroupURI, awss3.PermissionRead),
+			group(allUsersGroupURI, awss3.PermissionWrite)}, awss3.BucketCannedACLPublicReadWrite},
+		{"authenticated-read", []*awss3.Grant{owner, group(authenticatedUsersGroupURI, awss3.PermissionRead)},
+			awss3.BucketCannedACLAuthenticatedRead},
+		{"custom", []*awss3.Grant{owner, group(allUsersGroupURI, awss3.PermissionReadAcp)}, ""},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := kmsKeySession(tt.args.b)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("kmsKeySession() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("kmsKeySession() got = %v, want %v", got, tt.want)
+			if got := cannedACL(tt.grants); got != tt.want {
+				t.Errorf("cannedACL() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,129 @@
+package aws
+
+import (
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// SessionOptions configures the sessions returned by NewSession. Empty fields leave the SDK defaults in place, so
+// region and credentials are still taken from the environment and shared config if not given.
+type SessionOptions struct {
+	Region string
+
+	// Endpoint is used for all services instead of their AWS endpoint, e.g. "http://localhost:4566" for LocalStack
+	Endpoint string
+	// Endpoints overrides the endpoint of single services, keyed by their endpoint ID ("s3", "rds", "kms", "iam").
+	// They take precedence over Endpoint.
+	Endpoints map[string]string
+	// S3ForcePathStyle addresses buckets as "<endpoint>/<bucket>" instead of "<bucket>.<endpoint>", which most
+	// local stand-ins for S3 require
+	S3ForcePathStyle bool
+	// DisableSSL makes the SDK use http for endpoints given without a scheme
+	DisableSSL bool
+
+	// Profile is the shared config profile to use, along with everything it configures (region, credentials,
+	// roles to assume, ...)
+	Profile string
+	// AccessKeyID, SecretAccessKey and SessionToken are static credentials to use instead of the credential chain
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// AssumeRoleARN is a role to assume with the credentials otherwise configured, so the session acts as that role
+	AssumeRoleARN string
+	// ExternalID is passed on when assuming AssumeRoleARN, for roles that require one
+	ExternalID string
+	// RoleSessionName names the session of the assumed role; the SDK generates one if empty
+	RoleSessionName string
+	// MFASerial is the serial number or ARN of the MFA device AssumeRoleARN requires
+	MFASerial string
+	// TokenProvider returns MFA token codes, for MFASerial as well as for profiles with an "mfa_serial"
+	TokenProvider func() (string, error)
+
+	// Retryer decides on the retries of failed requests. Defaults to NewRetryer().
+	Retryer *Retryer
+}
+
+// NewSession returns a session configured by the given options
+func NewSession(opts SessionOptions) (*session.Session, error) {
+	conf := &awssdk.Config{}
+	if opts.Region != "" {
+		conf.Region = awssdk.String(opts.Region)
+	}
+	if opts.Endpoint != "" || len(opts.Endpoints) > 0 {
+		conf.EndpointResolver = opts.endpointResolver()
+	}
+	if opts.S3ForcePathStyle {
+		conf.S3ForcePathStyle = awssdk.Bool(true)
+	}
+	if opts.DisableSSL {
+		conf.DisableSSL = awssdk.Bool(true)
+	}
+	retryer := opts.Retryer
+	if retryer == nil {
+		retryer = NewRetryer()
+	}
+	WithRetryer(conf, retryer)
+	if opts.AccessKeyID != "" || opts.SecretAccessKey != "" {
+		if opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+			return nil, fmt.Errorf("static credentials need both an access key id and a secret access key")
+		}
+		conf.Credentials = credentials.NewStaticCredentials(opts.AccessKeyID, opts.SecretAccessKey, opts.SessionToken)
+	}
+
+	if opts.MFASerial != "" && opts.TokenProvider == nil {
+		return nil, fmt.Errorf("an MFA serial needs a token provider")
+	}
+
+	sharedConfig := session.SharedConfigStateFromEnv
+	if opts.Profile != "" {
+		sharedConfig = session.SharedConfigEnable
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:                  *conf,
+		Profile:                 opts.Profile,
+		SharedConfigState:       sharedConfig,
+		AssumeRoleTokenProvider: opts.TokenProvider,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sess.Handlers.Complete.PushBackNamed(LogHandler)
+	if opts.AssumeRoleARN == "" {
+		return sess, nil
+	}
+
+	creds := stscreds.NewCredentials(sess, opts.AssumeRoleARN, func(p *stscreds.AssumeRoleProvider) {
+		if opts.ExternalID != "" {
+			p.ExternalID = awssdk.String(opts.ExternalID)
+		}
+		if opts.MFASerial != "" {
+			p.SerialNumber = awssdk.String(opts.MFASerial)
+			p.TokenProvider = opts.TokenProvider
+		}
+		p.RoleSessionName = opts.RoleSessionName
+	})
+	return sess.Copy(&awssdk.Config{Credentials: creds}), nil
+}
+
+// endpointResolver returns a resolver handing out the configured endpoints, and the AWS ones for all other services
+func (opts SessionOptions) endpointResolver() endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string, optFns ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		url, ok := opts.Endpoints[service]
+		if !ok {
+			url = opts.Endpoint
+		}
+		if url == "" {
+			return endpoints.DefaultResolver().EndpointFor(service, region, optFns...)
+		}
+		return endpoints.ResolvedEndpoint{
+			URL:           endpoints.AddScheme(url, opts.DisableSSL),
+			SigningRegion: region,
+		}, nil
+	})
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSession(t *testing.T) {
+	sess, err := NewSession(SessionOptions{
+		Region:           "eu-central-1",
+		Endpoint:         "http://localhost:4566",
+		Endpoints:        map[string]string{"s3": "localhost:9000"},
+		S3ForcePathStyle: true,
+		DisableSSL:       true,
+		AccessKeyID:      "test",
+		SecretAccessKey:  "testsecret",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:4566", sess.ClientConfig("rds").Endpoint)
+	assert.Equal(t, "http://localhost:4566", sess.ClientConfig("kms").Endpoint)
+	assert.Equal(t, "http://localhost:9000", sess.ClientConfig("s3").Endpoint)
+	assert.Equal(t, "eu-central-1", sess.ClientConfig("s3").SigningRegion)
+	assert.True(t, *sess.Config.S3ForcePathStyle)
+
+	creds, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "test", creds.AccessKeyID)
+	assert.Equal(t, "testsecret", creds.SecretAccessKey)
+
+	// Without endpoints, AWS is used
+	sess, err = NewSession(SessionOptions{Region: "eu-central-1", AccessKeyID: "test", SecretAccessKey: "testsecret"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://rds.eu-central-1.amazonaws.com", sess.ClientConfig("rds").Endpoint)
+
+	// Static credentials need both parts
+	_, err = NewSession(SessionOptions{AccessKeyID: "test"})
+	assert.Error(t, err)
+}
+
+func TestNewSession_Profile(t *testing.T) {
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credsFile, []byte("[dev]\naws_access_key_id = devkey\naws_secret_access_key = devsecret\n"), 0600))
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile dev]\nregion = eu-west-1\n"), 0600))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	sess, err := NewSession(SessionOptions{Profile: "dev"})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", *sess.Config.Region)
+	creds, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "devkey", creds.AccessKeyID)
+
+	// Unknown profiles have no credentials
+	sess, err = NewSession(SessionOptions{Profile: "missing"})
+	if err == nil {
+		_, err = sess.Config.Credentials.Get()
+	}
+	assert.Error(t, err)
+}
+
+func TestNewSession_AssumeRole(t *testing.T) {
+	var form url.Values
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		form = r.PostForm
+		fmt.Fprint(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>
+<AccessKeyId>rolekey</AccessKeyId><SecretAccessKey>rolesecret</SecretAccessKey><SessionToken>roletoken</SessionToken>
+<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`)
+	}))
+	defer sts.Close()
+
+	sess, err := NewSession(SessionOptions{
+		Region:          "us-east-1",
+		Endpoints:       map[string]string{"sts": sts.URL},
+		AccessKeyID:     "basekey",
+		SecretAccessKey: "basesecret",
+		AssumeRoleARN:   "arn:aws:iam::123456789012:role/deploy",
+		ExternalID:      "ext",
+		MFASerial:       "arn:aws:iam::123456789012:mfa/me",
+		TokenProvider:   func() (string, error) { return "123456", nil },
+	})
+	require.NoError(t, err)
+	creds, err := sess.Config.Credentials.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "rolekey", creds.AccessKeyID)
+	assert.Equal(t, "roletoken", creds.SessionToken)
+	assert.Equal(t, "AssumeRole", form.Get("Action"))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/deploy", form.Get("RoleArn"))
+	assert.Equal(t, "ext", form.Get("ExternalId"))
+	assert.Equal(t, "arn:aws:iam::123456789012:mfa/me", form.Get("SerialNumber"))
+	assert.Equal(t, "123456", form.Get("TokenCode"))
+
+	// MFA needs someone to ask for the code
+	_, err = NewSession(SessionOptions{AssumeRoleARN: "arn:aws:iam::123456789012:role/deploy", MFASerial: "me"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// Package sink holds cloudobject.SecretSinks that keep secrets in AWS: in Secrets Manager or in the SSM Parameter
+// Store. Both encrypt the secrets with the account's default key of the service.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	awssecretsmanager "github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	awsssm "github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+// SecretsManager is a cloudobject.SecretSink storing all secrets of a CloudObject as a JSON object in a single
+// Secrets Manager secret. The secret is created if it doesn't exist yet; otherwise, its value is replaced.
+type SecretsManager struct {
+	name    string
+	session secretsmanageriface.SecretsManagerAPI
+	// logger is where the SecretsManager logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+// NewSecretsManager returns a SecretsManager writing to the secret of the given name, or to one named after the
+// ID of the CloudObject if the name is empty
+func NewSecretsManager(name string, session client.ConfigProvider) (*SecretsManager, error) {
+	return NewSecretsManagerWithClient(name, awssecretsmanager.New(session))
+}
+
+// NewSecretsManagerWithClient is the same as NewSecretsManager, but uses the given client instead of creating its
+// own
+func NewSecretsManagerWithClient(name string, svc secretsmanageriface.SecretsManagerAPI) (*SecretsManager, error) {
+	if len(name) > 512 {
+		return nil, fmt.Errorf("given name is longer than 512 characters")
+	}
+	return &SecretsManager{name: name, session: svc}, nil
+}
+
+// WriteSecrets stores the secrets of the CloudObject with the given ID
+func (sm *SecretsManager) WriteSecrets(ctx context.Context, id cloudobject.ID, secrets cloudobject.Secrets) error {
+	ctx = cloudobject.WithOperation(ctx, sm.logger, id, "write-secrets")
+	value, err := json.Marshal(secrets.Map())
+	if err != nil {
+		return err
+	}
+	name := sm.secretName(id)
+
+	_, err = sm.session.PutSecretValueWithContext(ctx, &awssecretsmanager.PutSecretValueInput{
+		SecretId:     awssdk.String(name),
+		SecretString: awssdk.String(string(value)),
+	})
+	if !aws.IsErrorCode(err, awssecretsmanager.ErrCodeResourceNotFoundException) {
+		return err
+	}
+
+	_, err = sm.session.CreateSecretWithContext(ctx, &awssecretsmanager.CreateSecretInput{
+		Name:         awssdk.String(name),
+		Description:  awssdk.String(fmt.Sprintf("Secrets of %s", id.String())),
+		SecretString: awssdk.String(string(value)),
+	})
+	return err
+}
+
+// secretName returns the name of the secret the secrets of the CloudObject with the given ID go to
+func (sm *SecretsManager) secretName(id cloudobject.ID) string {
+	if sm.name == "" {
+		return id.String()
+	}
+	return sm.name
+}
+
+// SetLogger makes the SecretsManager log its AWS calls to the given logger
+func (sm *SecretsManager) SetLogger(logger cloudobject.Logger) {
+	sm.logger = logger
+}
+
+// ParameterStore is a cloudobject.SecretSink storing each secret of a CloudObject as a SecureString parameter of the
+// SSM Parameter Store, named "<prefix>/<key>" (e.g. "/myapp/db/password"). Existing parameters are overwritten.
+type ParameterStore struct {
+	prefix  string
+	session ssmiface.SSMAPI
+	// logger is where the ParameterStore logs its AWS calls to, if set
+	logger cloudobject.Logger
+}
+
+// NewParameterStore returns a ParameterStore writing parameters below the given prefix, or below "/<ID>" of the
+// CloudObject if the prefix is empty
+func NewParameterStore(prefix string, session client.ConfigProvider) (*ParameterStore, error) {
+	return NewParameterStoreWithClient(prefix, awsssm.New(session))
+}
+
+// NewParameterStoreWithClient is the same as NewParameterStore, but uses the given client instead of creating its
+// own
+func NewParameterStoreWithClient(prefix string, svc ssmiface.SSMAPI) (*ParameterStore, error) {
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		return nil, fmt.Errorf("given prefix '%s' doesn't start with '/'", prefix)
+	}
+	return &ParameterStore{prefix: prefix, session: svc}, nil
+}
+
+// WriteSecrets stores the secrets of the CloudObject with the given ID
+func (ps *ParameterStore) WriteSecrets(ctx context.Context, id cloudobject.ID, secrets cloudobject.Secrets) error {
+	ctx = cloudobject.WithOperation(ctx, ps.logger, id, "write-secrets")
+	for key, value := range secrets.Map() {
+		_, err := ps.session.PutParameterWithContext(ctx, &awsssm.PutParameterInput{
+			Name:      awssdk.String(ps.ParameterName(id, key)),
+			Value:     awssdk.String(value),
+			Type:      awssdk.String(awsssm.ParameterTypeSecureString),
+			Overwrite: awssdk.Bool(true),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParameterName returns the name of the parameter the secret with the given key of the CloudObject with the given
+// ID goes to
+func (ps *ParameterStore) ParameterName(id cloudobject.ID, key string) string {
+	prefix := ps.prefix
+	if prefix == "" {
+		prefix = "/" + id.String()
+	}
+	return path.Join(prefix, key)
+}
+
+// SetLogger makes the ParameterStore log its AWS calls to the given logger
+func (ps *ParameterStore) SetLogger(logger cloudobject.Logger) {
+	ps.logger = logger
+}
@@ -0,0 +1,164 @@
+package cloudobject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	NoopDiffAction    DiffAction = "noop"
+	CreateDiffAction  DiffAction = "create"
+	UpdateDiffAction  DiffAction = "update"
+	ReplaceDiffAction DiffAction = "replace"
+)
+
+// DiffAction is what applying a spec would do to a CloudObject
+type DiffAction string
+
+func (action DiffAction) String() string {
+	return string(action)
+}
+
+// symbol is the prefix we print in front of a Diff with this action
+func (action DiffAction) symbol() string {
+	switch action {
+	case CreateDiffAction:
+		return "+"
+	case UpdateDiffAction:
+		return "~"
+	case ReplaceDiffAction:
+		return "-/+"
+	default:
+		return "="
+	}
+}
+
+// Change is a single field whose live value differs from the desired one
+type Change struct {
+	Field string      `json:"field" yaml:"field"`
+	Old   interface{} `json:"old" yaml:"old"`
+	New   interface{} `json:"new" yaml:"new"`
+
+	// Immutable changes can't be applied in place. The object would need to be replaced.
+	Immutable bool `json:"immutable,omitempty" yaml:"immutable,omitempty"`
+}
+
+func (c Change) String() string {
+	out := fmt.Sprintf("%s: %s => %s", c.Field, formatDiffValue(c.Old), formatDiffValue(c.New))
+	if c.Immutable {
+		out += " (forces replacement)"
+	}
+	return out
+}
+
+// Diff is the result of planning a spec against the live state of a CloudObject
+type Diff struct {
+	ID ID `json:"id" yaml:"id"`
+
+	// Create is set if the object does not exist yet. Changes are empty in that case.
+	Create bool `json:"create,omitempty" yaml:"create,omitempty"`
+
+	Changes []Change `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+// NewCreateDiff returns the Diff for an object that does not exist yet
+func NewCreateDiff(id ID) Diff {
+	return Diff{ID: id, Create: true}
+}
+
+// Compare records a Change for field, if old and new are not deeply equal
+func (d *Diff) Compare(field string, old, new interface{}) {
+	if !reflect.DeepEqual(old, new) {
+		d.Changes = append(d.Changes, Change{Field: field, Old: old, New: new})
+	}
+}
+
+// CompareImmutable records an immutable Change for field, if old and new are not deeply equal
+func (d *Diff) CompareImmutable(field string, old, new interface{}) {
+	if !reflect.DeepEqual(old, new) {
+		d.Changes = append(d.Changes, Change{Field: field, Old: old, New: new, Immutable: true})
+	}
+}
+
+// HasChanges tells whether applying the spec would do anything at all
+func (d Diff) HasChanges() bool {
+	return d.Create || len(d.Changes) != 0
+}
+
+// Changed tells whether any of the given fields changes
+func (d Diff) Changed(fields ...string) bool {
+	for _, c := range d.Changes {
+		for _, field := range fields {
+			if c.Field == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ImmutableChanges returns the Changes that can't be applied in place
+func (d Diff) ImmutableChanges() []Change {
+	var out []Change
+	for _, c := range d.Changes {
+		if c.Immutable {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Action sums up the Diff
+func (d Diff) Action() DiffAction {
+	switch {
+	case d.Create:
+		return CreateDiffAction
+	case len(d.ImmutableChanges()) != 0:
+		return ReplaceDiffAction
+	case len(d.Changes) != 0:
+		return UpdateDiffAction
+	default:
+		return NoopDiffAction
+	}
+}
+
+// String prints the Diff in a human readable form, one change per line
+func (d Diff) String() string {
+	var b strings.Builder
+	action := d.Action()
+	fmt.Fprintf(&b, "%s %s (%s)", action.symbol(), d.ID.String(), action.String())
+	for _, c := range d.Changes {
+		fmt.Fprintf(&b, "\n    %s", c.String())
+	}
+	return b.String()
+}
+
+// ImmutableChangesError returns a SpecInvalidError listing the immutable changes in the Diff, or nil if there are
+// none
+func (d Diff) ImmutableChangesError() error {
+	immutable := d.ImmutableChanges()
+	if len(immutable) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(immutable))
+	for _, c := range immutable {
+		fields = append(fields, c.Field)
+	}
+	return SpecInvalidError{ErrorInfo: ErrorInfo{
+		Message: fmt.Sprintf("modifying %s of '%s' is not possible", strings.Join(fields, ", "), d.ID.String()),
+		ID:      d.ID,
+		Op:      "update",
+	}}
+}
+
+func formatDiffValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,39 @@
+package cloudobject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	diff := Diff{ID: ReferenceObjectID}
+	diff.Compare("Size", 3, 3)
+	diff.Compare("Tags", []string{"a"}, []string{"a"})
+	assert.False(t, diff.HasChanges())
+	assert.Equal(t, NoopDiffAction, diff.Action())
+	assert.NoError(t, diff.ImmutableChangesError())
+
+	diff.Compare("Size", 3, 4)
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, UpdateDiffAction, diff.Action())
+	assert.NoError(t, diff.ImmutableChangesError())
+	assert.True(t, diff.Changed("Tags", "Size"))
+	assert.False(t, diff.Changed("Tags"))
+
+	diff.CompareImmutable("Location", "eu-west-1", "us-east-1")
+	assert.Equal(t, ReplaceDiffAction, diff.Action())
+	assert.Len(t, diff.ImmutableChanges(), 1)
+	assert.True(t, IsCloudSpecInvalidError(diff.ImmutableChangesError()))
+
+	assert.Equal(t, "-/+ clobjx-test-thisismyobject (replace)\n"+
+		"    Size: 3 => 4\n"+
+		"    Location: \"eu-west-1\" => \"us-east-1\" (forces replacement)", diff.String())
+}
+
+func TestNewCreateDiff(t *testing.T) {
+	diff := NewCreateDiff(ReferenceObjectID)
+	assert.True(t, diff.HasChanges())
+	assert.Equal(t, CreateDiffAction, diff.Action())
+	assert.Equal(t, "+ clobjx-test-thisismyobject (create)", diff.String())
+}
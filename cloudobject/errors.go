@@ -1,17 +1,77 @@
 package cloudobject
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinels for matching the error types below with errors.Is, no matter what they carry, e.g.
+//
+//	if errors.Is(err, cloudobject.ErrNotExists) { ... }
+//
+// Use errors.As with the error types to get at the object ID, the operation and the underlying error.
+var (
+	ErrNotExists           = errors.New("cloud object does not exist")
+	ErrNotReady            = errors.New("cloud object is not ready")
+	ErrAmbiguousIdentifier = errors.New("cloud object identifier is ambiguous")
+	ErrAlreadyExists       = errors.New("cloud object already exists")
+	ErrSpecInvalid         = errors.New("cloud object spec is invalid")
+	ErrOptsInvalid         = errors.New("options are invalid")
+	ErrIdCollision         = errors.New("cloud object id collides with an existing object")
+	ErrWaitTimeout         = errors.New("timed out waiting for cloud object")
+	ErrDependencyCycle     = errors.New("cloud objects depend on each other in a cycle")
+)
+
+// ErrorInfo is what all error types carry: a message, the object and operation it occurred with, and the error it
+// was caused by (e.g. the awserr.Error AWS returned). Only the message is mandatory. The ID and Op are set if known,
+// e.g. "read" for errors of CloudObject.Read.
+type ErrorInfo struct {
+	Message string
+	ID      ID
+	Op      string
+	Err     error
+}
+
+func (e ErrorInfo) Unwrap() error {
+	return e.Err
+}
+
+// message is the Error() of all error types. Messages are returned as they are, so they read the same as before
+// errors carried more than a message. Without one, it is made up from the rest.
+func (e ErrorInfo) message(sentinel error) string {
+	if e.Message != "" {
+		return e.Message
+	}
+	msg := sentinel.Error()
+	if e.ID != "" {
+		msg = fmt.Sprintf("%s: %s", e.ID.String(), msg)
+	}
+	if e.Op != "" {
+		msg = fmt.Sprintf("%s %s", e.Op, msg)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err.Error())
+	}
+	return msg
+}
+
 // NotExistsError is returned when a Cloud Object does not exist
 type NotExistsError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e NotExistsError) Error() string {
-	return e.Message
+	return e.message(ErrNotExists)
+}
+
+// Is makes errors.Is match ErrNotExists
+func (e NotExistsError) Is(target error) bool {
+	return target == ErrNotExists
 }
 
 func IsNotExistsError(err error) bool {
-	_, ok := err.(NotExistsError)
-	return ok
+	return errors.Is(err, ErrNotExists)
 }
 
 func IgnoreNotExistsError(err error) error {
@@ -21,18 +81,22 @@ func IgnoreNotExistsError(err error) error {
 	return err
 }
 
-// NotReadyError is returned when a Cloud Object does not exist
+// NotReadyError is returned when a Cloud Object is not ready for the operation
 type NotReadyError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e NotReadyError) Error() string {
-	return e.Message
+	return e.message(ErrNotReady)
+}
+
+// Is makes errors.Is match ErrNotReady
+func (e NotReadyError) Is(target error) bool {
+	return target == ErrNotReady
 }
 
 func IsNotReadyError(err error) bool {
-	_, ok := err.(NotReadyError)
-	return ok
+	return errors.Is(err, ErrNotReady)
 }
 
 func IgnoreNotReadyError(err error) error {
@@ -42,18 +106,22 @@ func IgnoreNotReadyError(err error) error {
 	return err
 }
 
-// NotExistsError is returned when a Cloud Object does not exist
+// AmbiguousIdentifierError is returned when an identifier matches more than one Cloud Object
 type AmbiguousIdentifierError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e AmbiguousIdentifierError) Error() string {
-	return e.Message
+	return e.message(ErrAmbiguousIdentifier)
+}
+
+// Is makes errors.Is match ErrAmbiguousIdentifier
+func (e AmbiguousIdentifierError) Is(target error) bool {
+	return target == ErrAmbiguousIdentifier
 }
 
 func IsAmbiguousIdentifierError(err error) bool {
-	_, ok := err.(AmbiguousIdentifierError)
-	return ok
+	return errors.Is(err, ErrAmbiguousIdentifier)
 }
 
 func IgnoreAmbiguousIdentifierError(err error) error {
@@ -65,16 +133,20 @@ func IgnoreAmbiguousIdentifierError(err error) error {
 
 // AlreadyExistsError is returned when a Cloud Object already exists
 type AlreadyExistsError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e AlreadyExistsError) Error() string {
-	return e.Message
+	return e.message(ErrAlreadyExists)
+}
+
+// Is makes errors.Is match ErrAlreadyExists
+func (e AlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists
 }
 
 func IsAlreadyExistsError(err error) bool {
-	_, ok := err.(AlreadyExistsError)
-	return ok
+	return errors.Is(err, ErrAlreadyExists)
 }
 
 func IgnoreAlreadyExistsError(err error) error {
@@ -86,16 +158,20 @@ func IgnoreAlreadyExistsError(err error) error {
 
 // SpecInvalidError is returned when a CloudObjectSpec is invalid for the current action
 type SpecInvalidError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e SpecInvalidError) Error() string {
-	return e.Message
+	return e.message(ErrSpecInvalid)
+}
+
+// Is makes errors.Is match ErrSpecInvalid
+func (e SpecInvalidError) Is(target error) bool {
+	return target == ErrSpecInvalid
 }
 
 func IsCloudSpecInvalidError(err error) bool {
-	_, ok := err.(SpecInvalidError)
-	return ok
+	return errors.Is(err, ErrSpecInvalid)
 }
 
 func IgnoreCloudSpecInvalidError(err error) error {
@@ -107,16 +183,20 @@ func IgnoreCloudSpecInvalidError(err error) error {
 
 // OptsInvalidError is returned when a an options object (e.g. DeleteOpts) is invalid for the current action
 type OptsInvalidError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e OptsInvalidError) Error() string {
-	return e.Message
+	return e.message(ErrOptsInvalid)
+}
+
+// Is makes errors.Is match ErrOptsInvalid
+func (e OptsInvalidError) Is(target error) bool {
+	return target == ErrOptsInvalid
 }
 
 func IsOptsInvalidError(err error) bool {
-	_, ok := err.(OptsInvalidError)
-	return ok
+	return errors.Is(err, ErrOptsInvalid)
 }
 
 func IgnoreOptsInvalidError(err error) error {
@@ -128,16 +208,20 @@ func IgnoreOptsInvalidError(err error) error {
 
 // IdCollisionError is returned when a CloudObject cannot be created due to collisions with existing objects
 type IdCollisionError struct {
-	Message string
+	ErrorInfo
 }
 
 func (e IdCollisionError) Error() string {
-	return e.Message
+	return e.message(ErrIdCollision)
+}
+
+// Is makes errors.Is match ErrIdCollision
+func (e IdCollisionError) Is(target error) bool {
+	return target == ErrIdCollision
 }
 
 func IsIdCollisionError(err error) bool {
-	_, ok := err.(IdCollisionError)
-	return ok
+	return errors.Is(err, ErrIdCollision)
 }
 
 func IgnoreIdCollisionError(err error) error {
@@ -146,3 +230,91 @@ func IgnoreIdCollisionError(err error) error {
 	}
 	return err
 }
+
+// WaitTimeoutError is returned when a CloudObject did not reach the desired state in time
+type WaitTimeoutError struct {
+	ErrorInfo
+}
+
+func (e WaitTimeoutError) Error() string {
+	return e.message(ErrWaitTimeout)
+}
+
+// Is makes errors.Is match ErrWaitTimeout
+func (e WaitTimeoutError) Is(target error) bool {
+	return target == ErrWaitTimeout
+}
+
+func IsWaitTimeoutError(err error) bool {
+	return errors.Is(err, ErrWaitTimeout)
+}
+
+func IgnoreWaitTimeoutError(err error) error {
+	if IsWaitTimeoutError(err) {
+		return nil
+	}
+	return err
+}
+
+// DependencyCycleError is returned when CloudObjects depend on each other in a cycle
+type DependencyCycleError struct {
+	ErrorInfo
+}
+
+func (e DependencyCycleError) Error() string {
+	return e.message(ErrDependencyCycle)
+}
+
+// Is makes errors.Is match ErrDependencyCycle
+func (e DependencyCycleError) Is(target error) bool {
+	return target == ErrDependencyCycle
+}
+
+func IsDependencyCycleError(err error) bool {
+	return errors.Is(err, ErrDependencyCycle)
+}
+
+func IgnoreDependencyCycleError(err error) error {
+	if IsDependencyCycleError(err) {
+		return nil
+	}
+	return err
+}
+
+// MultiError is returned when more than one CloudObject failed, e.g. on Graph.Walk. errors.Is and errors.As look at
+// all of its errors, so it matches the sentinels of every error it holds.
+type MultiError struct {
+	Errs []error
+}
+
+func (e MultiError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d objects failed: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+func (e MultiError) Unwrap() []error {
+	return e.Errs
+}
+
+// Is makes errors.Is match the errors of the MultiError, also before Go 1.20 knew about Unwrap() []error
+func (e MultiError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As makes errors.As match the errors of the MultiError, the first one that matches winning
+func (e MultiError) As(target interface{}) bool {
+	for _, err := range e.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package cloudobject
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors_Wrapping(t *testing.T) {
+	cause := errors.New("NotFoundException: Alias not found")
+	err := fmt.Errorf("reading key: %w", NotExistsError{ErrorInfo: ErrorInfo{
+		Message: "KMS Key with id 'x' not found",
+		ID:      "x",
+		Op:      "read",
+		Err:     cause,
+	}})
+
+	assert.True(t, IsNotExistsError(err))
+	assert.True(t, errors.Is(err, ErrNotExists))
+	assert.False(t, errors.Is(err, ErrNotReady))
+	assert.True(t, errors.Is(err, cause))
+	assert.Nil(t, IgnoreNotExistsError(err))
+	assert.Equal(t, "reading key: KMS Key with id 'x' not found", err.Error())
+
+	var notExists NotExistsError
+	assert.True(t, errors.As(err, &notExists))
+	assert.Equal(t, ID("x"), notExists.ID)
+	assert.Equal(t, "read", notExists.Op)
+	assert.Equal(t, cause, notExists.Err)
+}
+
+func TestErrors_Sentinels(t *testing.T) {
+	tests := []struct {
+		err      error
+		sentinel error
+		is       func(error) bool
+	}{
+		{NotExistsError{}, ErrNotExists, IsNotExistsError},
+		{NotReadyError{}, ErrNotReady, IsNotReadyError},
+		{AmbiguousIdentifierError{}, ErrAmbiguousIdentifier, IsAmbiguousIdentifierError},
+		{AlreadyExistsError{}, ErrAlreadyExists, IsAlreadyExistsError},
+		{SpecInvalidError{}, ErrSpecInvalid, IsCloudSpecInvalidError},
+		{OptsInvalidError{}, ErrOptsInvalid, IsOptsInvalidError},
+		{IdCollisionError{}, ErrIdCollision, IsIdCollisionError},
+		{WaitTimeoutError{}, ErrWaitTimeout, IsWaitTimeoutError},
+		{DependencyCycleError{}, ErrDependencyCycle, IsDependencyCycleError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sentinel.Error(), func(t *testing.T) {
+			wrapped := fmt.Errorf("wrapped: %w", tt.err)
+			assert.True(t, errors.Is(wrapped, tt.sentinel))
+			assert.True(t, tt.is(wrapped))
+			assert.False(t, tt.is(errors.New("other")))
+			assert.False(t, tt.is(nil))
+		})
+	}
+}
+
+func TestErrors_Message(t *testing.T) {
+	assert.Equal(t, "cloud object does not exist", NotExistsError{}.Error())
+	assert.Equal(t, "read x: cloud object does not exist: boom",
+		NotExistsError{ErrorInfo: ErrorInfo{ID: "x", Op: "read", Err: errors.New("boom")}}.Error())
+	assert.Equal(t, "given message", NotReadyError{ErrorInfo: ErrorInfo{
+		Message: "given message",
+		Err:     errors.New("boom"),
+	}}.Error())
+}
@@ -0,0 +1,246 @@
+package cloudobject
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Referencer is implemented by CloudObjects that depend on other CloudObjects, e.g. an RDS Instance on its
+// SubnetGroup. References returns the IDs of these objects, as far as they follow from the object and spec.
+type Referencer interface {
+	References(spec CloudObjectSpec) []ID
+}
+
+// WalkFunc is called by Graph.Walk for every object, once everything it has to wait for is done
+type WalkFunc func(ctx context.Context, id ID, obj CloudObject, spec CloudObjectSpec) error
+
+// Graph is a DAG of CloudObjects and their references. Objects are created in dependency order and deleted in
+// reverse order, with independent branches handled in parallel. References to objects that are not part of the
+// graph are considered to be managed elsewhere and are ignored.
+type Graph struct {
+	ids   []ID
+	nodes map[ID]*graphNode
+}
+
+type graphNode struct {
+	obj  CloudObject
+	spec CloudObjectSpec
+
+	// explicit dependencies, on top of what the object references itself
+	dependsOn []ID
+}
+
+func NewGraph() *Graph {
+	return &Graph{nodes: make(map[ID]*graphNode)}
+}
+
+// Add adds an object with the spec it is applied with to the graph
+func (g *Graph) Add(obj CloudObject, spec CloudObjectSpec) error {
+	id := obj.ID()
+	if _, ok := g.nodes[id]; ok {
+		return IdCollisionError{ErrorInfo: ErrorInfo{
+			Message: fmt.Sprintf("'%s' is already part of the graph", id.String()),
+		}}
+	}
+	g.ids = append(g.ids, id)
+	g.nodes[id] = &graphNode{obj: obj, spec: spec}
+	return nil
+}
+
+// Depend declares that the object with id depends on the object with the id on, on top of its own references. Both
+// objects need to be part of the graph already.
+func (g *Graph) Depend(id, on ID) error {
+	for _, i := range []ID{id, on} {
+		if _, ok := g.nodes[i]; !ok {
+			return NotExistsError{ErrorInfo: ErrorInfo{
+				Message: fmt.Sprintf("'%s' is not part of the graph", i.String()),
+			}}
+		}
+	}
+	g.nodes[id].dependsOn = append(g.nodes[id].dependsOn, on)
+	return nil
+}
+
+// Dependencies returns the IDs of the objects in the graph, the object with the given id depends on
+func (g *Graph) Dependencies(id ID) []ID {
+	n, ok := g.nodes[id]
+	if !ok {
+		return nil
+	}
+	refs := append([]ID{}, n.dependsOn...)
+	if referencer, ok := n.obj.(Referencer); ok {
+		refs = append(refs, referencer.References(n.spec)...)
+	}
+
+	var out []ID
+	seen := make(map[ID]bool)
+	for _, ref := range refs {
+		if _, inGraph := g.nodes[ref]; !inGraph || ref == id || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		out = append(out, ref)
+	}
+	return out
+}
+
+// Order returns all IDs in an order that has every object after its dependencies, or before them if reverse is
+// set. Objects without dependencies between them keep the order they were added in.
+func (g *Graph) Order(reverse bool) ([]ID, error) {
+	var out []ID
+	collect := func(_ context.Context, id ID, _ CloudObject, _ CloudObjectSpec) error {
+		out = append(out, id)
+		return nil
+	}
+	if err := g.Walk(context.Background(), 1, reverse, collect); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Walk calls fn for every object of the graph, each as soon as all its dependencies are done (or all its
+// dependents, if reverse is set). Up to parallelism calls run at the same time. After the first error no more calls
+// are started, but the ones already running are waited for.
+func (g *Graph) Walk(ctx context.Context, parallelism int, reverse bool, fn WalkFunc) error {
+	prereqs, dependents, err := g.edges(reverse)
+	if err != nil {
+		return err
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	type result struct {
+		id  ID
+		err error
+	}
+	results := make(chan result)
+
+	pending := make(map[ID]int, len(g.ids))
+	var ready []ID
+	for _, id := range g.ids {
+		pending[id] = len(prereqs[id])
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	var errs []error
+	var firstErr error
+	running := 0
+	for {
+		for len(ready) > 0 && running < parallelism && firstErr == nil {
+			id := ready[0]
+			ready = ready[1:]
+			running++
+			go func(id ID, n *graphNode) {
+				results <- result{id: id, err: fn(ctx, id, n.obj, n.spec)}
+			}(id, g.nodes[id])
+		}
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		if res.err != nil {
+			err := fmt.Errorf("'%s': %w", res.id.String(), res.err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		for _, d := range dependents[res.id] {
+			pending[d]--
+			if pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	if len(errs) > 1 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return MultiError{Errs: errs}
+	}
+	return firstErr
+}
+
+// edges returns for every ID what has to be done before it, and what is waiting for it. Cycles are an error.
+func (g *Graph) edges(reverse bool) (map[ID][]ID, map[ID][]ID, error) {
+	deps := make(map[ID][]ID, len(g.ids))
+	for _, id := range g.ids {
+		deps[id] = g.Dependencies(id)
+	}
+	if err := findCycle(g.ids, deps); err != nil {
+		return nil, nil, err
+	}
+
+	prereqs := make(map[ID][]ID, len(g.ids))
+	dependents := make(map[ID][]ID, len(g.ids))
+	for _, id := range g.ids {
+		for _, dep := range deps[id] {
+			before, after := dep, id
+			if reverse {
+				before, after = id, dep
+			}
+			prereqs[after] = append(prereqs[after], before)
+			dependents[before] = append(dependents[before], after)
+		}
+	}
+	return prereqs, dependents, nil
+}
+
+// findCycle returns a DependencyCycleError naming the first cycle found, if any
+func findCycle(ids []ID, deps map[ID][]ID) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[ID]int, len(ids))
+	var path []ID
+
+	var visit func(id ID) error
+	visit = func(id ID) error {
+		switch state[id] {
+		case visited:
+			return nil
+		case visiting:
+			// The cycle is the part of the path from the first occurrence of id on
+			var cycle []string
+			for i := len(path) - 1; i >= 0; i-- {
+				if path[i] == id {
+					for _, p := range path[i:] {
+						cycle = append(cycle, p.String())
+					}
+					break
+				}
+			}
+			cycle = append(cycle, id.String())
+			return DependencyCycleError{ErrorInfo: ErrorInfo{
+				Message: fmt.Sprintf("dependency cycle detected: %s", strings.Join(cycle, " -> ")),
+			}}
+		}
+
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range deps[id] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cloudobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphObject references the objects given in refs
+type graphObject struct {
+	testObject
+	id   ID
+	refs []ID
+}
+
+func (o graphObject) ID() ID {
+	return o.id
+}
+
+func (o graphObject) References(_ CloudObjectSpec) []ID {
+	return o.refs
+}
+
+func testGraph(t *testing.T, objs ...graphObject) *Graph {
+	g := NewGraph()
+	for _, obj := range objs {
+		assert.NoError(t, g.Add(obj, &testSpec{}))
+	}
+	return g
+}
+
+func TestGraph_Order(t *testing.T) {
+	// instance -> subnetgroup, instance -> key, bucket -> key, key -> external (not in graph)
+	g := testGraph(t,
+		graphObject{id: "instance", refs: []ID{"subnetgroup", "key"}},
+		graphObject{id: "bucket", refs: []ID{"key", "bucket"}},
+		graphObject{id: "subnetgroup"},
+		graphObject{id: "key", refs: []ID{"external"}},
+	)
+	assert.ElementsMatch(t, []ID{"subnetgroup", "key"}, g.Dependencies("instance"))
+	assert.Equal(t, []ID{"key"}, g.Dependencies("bucket"))
+
+	order, err := g.Order(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ID{"subnetgroup", "key", "instance", "bucket"}, order)
+
+	order, err = g.Order(true)
+	assert.NoError(t, err)
+	assert.Equal(t, []ID{"instance", "bucket", "subnetgroup", "key"}, order)
+
+	// Explicit dependencies come on top
+	assert.NoError(t, g.Depend("subnetgroup", "bucket"))
+	order, err = g.Order(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []ID{"key", "bucket", "subnetgroup", "instance"}, order)
+
+	assert.True(t, IsNotExistsError(g.Depend("subnetgroup", "nope")))
+	assert.True(t, IsIdCollisionError(g.Add(graphObject{id: "key"}, nil)))
+}
+
+func TestGraph_Cycle(t *testing.T) {
+	g := testGraph(t,
+		graphObject{id: "a", refs: []ID{"b"}},
+		graphObject{id: "b", refs: []ID{"c"}},
+		graphObject{id: "c", refs: []ID{"a"}},
+		graphObject{id: "d"},
+	)
+	_, err := g.Order(false)
+	assert.True(t, IsDependencyCycleError(err))
+	assert.EqualError(t, err, "dependency cycle detected: a -> b -> c -> a")
+
+	called := false
+	err = g.Walk(context.Background(), 2, false, func(_ context.Context, _ ID, _ CloudObject, _ CloudObjectSpec) error {
+		called = true
+		return nil
+	})
+	assert.True(t, IsDependencyCycleError(err))
+	assert.False(t, called, "nothing may be touched if the graph has a cycle")
+}
+
+func TestGraph_WalkParallel(t *testing.T) {
+	g := testGraph(t,
+		graphObject{id: "a"},
+		graphObject{id: "b"},
+		graphObject{id: "c"},
+		graphObject{id: "d", refs: []ID{"a", "b", "c"}},
+	)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	var done []ID
+	err := g.Walk(context.Background(), 2, false, func(_ context.Context, id ID, _ CloudObject, _ CloudObjectSpec) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		done = append(done, id)
+		mu.Unlock()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, maxRunning)
+	assert.Len(t, done, 4)
+	assert.Equal(t, ID("d"), done[3])
+}
+
+func TestGraph_WalkError(t *testing.T) {
+	g := testGraph(t,
+		graphObject{id: "a"},
+		graphObject{id: "b", refs: []ID{"a"}},
+		graphObject{id: "c"},
+	)
+
+	var mu sync.Mutex
+	var called []ID
+	err := g.Walk(context.Background(), 1, false, func(_ context.Context, id ID, _ CloudObject, _ CloudObjectSpec) error {
+		mu.Lock()
+		called = append(called, id)
+		mu.Unlock()
+		if id == "a" {
+			return NotReadyError{ErrorInfo: ErrorInfo{Message: "not ready"}}
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "'a': not ready")
+	// Nothing is started after the first error
+	assert.Equal(t, []ID{"a"}, called)
+
+	err = g.Walk(context.Background(), 3, false, func(_ context.Context, id ID, _ CloudObject, _ CloudObjectSpec) error {
+		return fmt.Errorf("%s failed", id.String())
+	})
+	assert.EqualError(t, err, "2 objects failed: 'a': a failed; 'c': c failed")
+
+	// All errors stay matchable, so they can still be told apart by their type
+	err = g.Walk(context.Background(), 3, false, func(_ context.Context, id ID, _ CloudObject, _ CloudObjectSpec) error {
+		if id == "a" {
+			return NotExistsError{ErrorInfo: ErrorInfo{Message: "a not found", ID: id}}
+		}
+		return NotReadyError{ErrorInfo: ErrorInfo{Message: "c not ready", ID: id}}
+	})
+	assert.IsType(t, MultiError{}, err)
+	assert.True(t, IsNotExistsError(err))
+	assert.True(t, IsNotReadyError(err))
+	assert.False(t, IsWaitTimeoutError(err))
+	var notReady NotReadyError
+	require.True(t, errors.As(err, &notReady))
+	assert.Equal(t, ID("c"), notReady.ID)
+}
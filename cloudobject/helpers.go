@@ -1,7 +1,13 @@
 package cloudobject
 
+import "context"
+
 func Exists(obj CloudObject) (bool, error) {
-	if err := obj.Read(); err != nil {
+	return ExistsWithContext(context.Background(), obj)
+}
+
+func ExistsWithContext(ctx context.Context, obj CloudObject) (bool, error) {
+	if err := obj.ReadWithContext(ctx); err != nil {
 		if IsNotExistsError(err) {
 			return false, nil
 		}
@@ -1,25 +1,41 @@
 package cloudobject
 
-import awssdk "github.com/aws/aws-sdk-go/aws"
+import (
+	"context"
+
+	awssdk "github.com/aws/aws-sdk-go/aws"
+)
 
 const (
 	AWSProvider = "aws"
 )
 
-// CloudObject is the interface for interacting with any cloud provider resource abstracted in this repository
+// CloudObject is the interface for interacting with any cloud provider resource abstracted in this repository.
+// The *WithContext variants abort the underlying provider calls as soon as the given context is done.
 type CloudObject interface {
 	Create(CloudObjectSpec) (Secrets, error)
+	CreateWithContext(context.Context, CloudObjectSpec) (Secrets, error)
 	Read() error
+	ReadWithContext(context.Context) error
 	Update(CloudObjectSpec) (Secrets, error)
+	UpdateWithContext(context.Context, CloudObjectSpec) (Secrets, error)
 	Delete(bool) error
+	DeleteWithContext(context.Context, bool) error
 	Status() Status
 	ID() ID
 	Exists() (bool, error)
+	ExistsWithContext(context.Context) (bool, error)
+	// Plan compares a spec against the live object and returns what applying it would change, without changing
+	// anything
+	Plan(CloudObjectSpec) (Diff, error)
+	PlanWithContext(context.Context, CloudObjectSpec) (Diff, error)
 }
 
 type Status interface {
 	String() string
 	ProviderID() ProviderID
+	// State is the short provider-side lifecycle state (e.g. "available", "Enabled")
+	State() string
 }
 
 // CloudObjectSpec should be an interface that Object Specs should implement
@@ -51,11 +67,34 @@ func (id ID) StringPtr() *string {
 	return awssdk.String(string(id))
 }
 
+// Kind names the type of a CloudObject (e.g. "bucket", "instance")
+type Kind string
+
+func (kind Kind) String() string {
+	return string(kind)
+}
+
+// Store keeps track of the CloudObjects we have applied, so they don't need to be rediscovered from the provider
 type Store interface {
-	Persist(object *CloudObject) error
-	Retrieve(id ID) *CloudObject
+	Persist(record Record) error
+	Retrieve(id ID) (Record, error)
+	Remove(id ID) error
+	List() ([]Record, error)
 }
 
 type Secrets interface {
 	Map() map[string]string
 }
+
+// SecretsReader is implemented by CloudObjects whose secrets can be derived from what was last read, e.g. connection
+// details that are only known once the object is ready
+type SecretsReader interface {
+	Secrets() Secrets
+}
+
+// SecretSink is where the secrets of CloudObjects are kept, as many of them (e.g. generated credentials) are only
+// handed out once
+type SecretSink interface {
+	// WriteSecrets writes the secrets of the CloudObject with the given ID, replacing what was written for it before
+	WriteSecrets(ctx context.Context, id ID, secrets Secrets) error
+}
@@ -0,0 +1,54 @@
+package cloudobject
+
+import "context"
+
+// Logger is what CloudObjects log to. Its methods take a message and alternating keys and values, so a
+// *slog.Logger can be passed as is, and most other structured loggers with a thin adapter.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+// Loggable is implemented by CloudObjects that log what they do, to a Logger set with SetLogger. Without one, they
+// don't log at all.
+type Loggable interface {
+	SetLogger(logger Logger)
+}
+
+// Operation describes what a CloudObject is doing, for everything that logs on its behalf (e.g. the AWS calls it
+// makes)
+type Operation struct {
+	// ID is the object doing it
+	ID ID
+	// Name is what it does, e.g. "create"
+	Name string
+	// Logger is where to log to. If nil, nothing is logged.
+	Logger Logger
+}
+
+type operationKey struct{}
+
+// WithOperation returns a context carrying the given Operation. Operations of an object nest in the first one, so
+// the reads an object does while creating itself are logged as part of "create". The logger is inherited from
+// the outer operation if not given, so objects handled by others log to the same logger.
+func WithOperation(ctx context.Context, logger Logger, id ID, name string) context.Context {
+	outer, ok := OperationFromContext(ctx)
+	if ok && outer.ID == id {
+		return ctx
+	}
+	if logger == nil && ok {
+		logger = outer.Logger
+	}
+	return context.WithValue(ctx, operationKey{}, Operation{ID: id, Name: name, Logger: logger})
+}
+
+// OperationFromContext returns the Operation the context carries, if any
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	if ctx == nil {
+		return Operation{}, false
+	}
+	op, ok := ctx.Value(operationKey{}).(Operation)
+	return op, ok
+}
@@ -0,0 +1,8 @@
+//go:build go1.21
+
+package cloudobject
+
+import "log/slog"
+
+// *slog.Logger is a Logger as is
+var _ Logger = slog.Default()
@@ -0,0 +1,37 @@
+package cloudobject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) DebugContext(context.Context, string, ...interface{}) {}
+func (nopLogger) InfoContext(context.Context, string, ...interface{})  {}
+func (nopLogger) WarnContext(context.Context, string, ...interface{})  {}
+func (nopLogger) ErrorContext(context.Context, string, ...interface{}) {}
+
+func TestWithOperation(t *testing.T) {
+	_, ok := OperationFromContext(context.Background())
+	assert.False(t, ok)
+
+	logger := &nopLogger{}
+	ctx := WithOperation(context.Background(), logger, "mybucket", "create")
+	op, ok := OperationFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, Operation{ID: "mybucket", Name: "create", Logger: logger}, op)
+
+	// Operations of the same object nest in the first one
+	op, _ = OperationFromContext(WithOperation(ctx, logger, "mybucket", "read"))
+	assert.Equal(t, "create", op.Name)
+
+	// Other objects get their own, with the outer logger unless they have one of their own
+	op, _ = OperationFromContext(WithOperation(ctx, nil, "alias/mykey", "read"))
+	assert.Equal(t, Operation{ID: "alias/mykey", Name: "read", Logger: logger}, op)
+	other := nopLogger{}
+	op, _ = OperationFromContext(WithOperation(ctx, other, "alias/mykey", "read"))
+	assert.Equal(t, other, op.Logger)
+}
@@ -0,0 +1,174 @@
+package cloudobject
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	JSONSecretsFormat       SecretsFormat = "json"
+	DotenvSecretsFormat     SecretsFormat = "dotenv"
+	KubernetesSecretsFormat SecretsFormat = "k8s"
+)
+
+// SecretsFormat is how secrets are written to files and streams
+type SecretsFormat string
+
+// Valid tells whether the SecretsFormat is one we know
+func (format SecretsFormat) Valid() bool {
+	switch format {
+	case JSONSecretsFormat, DotenvSecretsFormat, KubernetesSecretsFormat:
+		return true
+	default:
+		return false
+	}
+}
+
+// FormatSecrets writes the secrets of the CloudObject with the given ID to w in the given format:
+//   - json: an object of all secrets
+//   - dotenv: KEY='value' lines, that can be sourced by a shell, with keys like "accessKeyId" turned into
+//     "ACCESS_KEY_ID"
+//   - k8s: a Kubernetes Secret manifest named after the ID (see KubernetesSecretName), ready for kubectl apply
+func FormatSecrets(w io.Writer, id ID, secrets Secrets, format SecretsFormat) error {
+	values := secrets.Map()
+	switch format {
+	case JSONSecretsFormat:
+		b, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case DotenvSecretsFormat:
+		for _, k := range sortedSecretKeys(values) {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", EnvName(k), shellQuote(values[k])); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KubernetesSecretsFormat:
+		b, err := yaml.Marshal(kubernetesSecret{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   kubernetesMetadata{Name: KubernetesSecretName(id)},
+			Type:       "Opaque",
+			StringData: values,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return OptsInvalidError{ErrorInfo: ErrorInfo{Message: fmt.Sprintf("unknown secrets format '%s'", format)}}
+	}
+}
+
+// WriterSink is a SecretSink writing secrets to an io.Writer (e.g. stdout) in the given format
+type WriterSink struct {
+	Writer io.Writer
+	Format SecretsFormat
+}
+
+func (sink WriterSink) WriteSecrets(_ context.Context, id ID, secrets Secrets) error {
+	return FormatSecrets(sink.Writer, id, secrets, sink.Format)
+}
+
+// FileSink is a SecretSink writing secrets to a file in the given format. The file is only readable by the current
+// user, and it is replaced atomically (write & rename), so it never holds half of the secrets.
+type FileSink struct {
+	Path   string
+	Format SecretsFormat
+}
+
+func (sink FileSink) WriteSecrets(_ context.Context, id ID, secrets Secrets) error {
+	if !sink.Format.Valid() {
+		return OptsInvalidError{ErrorInfo: ErrorInfo{Message: fmt.Sprintf("unknown secrets format '%s'", sink.Format)}}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(sink.Path), filepath.Base(sink.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := FormatSecrets(tmp, id, secrets, sink.Format); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), sink.Path)
+}
+
+type kubernetesSecret struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   kubernetesMetadata `yaml:"metadata"`
+	Type       string             `yaml:"type"`
+	StringData map[string]string  `yaml:"stringData"`
+}
+
+type kubernetesMetadata struct {
+	Name string `yaml:"name"`
+}
+
+// KubernetesSecretName turns the ID into a valid name for a Kubernetes Secret: lower case, with anything but
+// letters, digits, "-" and "." replaced by "-", and at most 253 characters long
+func KubernetesSecretName(id ID) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return unicode.ToLower(r)
+		default:
+			return '-'
+		}
+	}, id.String())
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, "-.")
+}
+
+// EnvName turns a secret key like "secretAccessKey" into an environment variable name like "SECRET_ACCESS_KEY"
+func EnvName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s, so generated passwords survive being sourced by a shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sortedSecretKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
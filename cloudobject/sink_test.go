@@ -0,0 +1,66 @@
+package cloudobject
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var referenceSecrets = testSecrets{"accessKeyId": "AKIAEXAMPLE", "secretAccessKey": "it's secret"}
+
+func TestFormatSecrets(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, FormatSecrets(&b, ReferenceObjectID, referenceSecrets, JSONSecretsFormat))
+	assert.JSONEq(t, `{"accessKeyId": "AKIAEXAMPLE", "secretAccessKey": "it's secret"}`, b.String())
+
+	b.Reset()
+	require.NoError(t, FormatSecrets(&b, ReferenceObjectID, referenceSecrets, DotenvSecretsFormat))
+	assert.Equal(t, "ACCESS_KEY_ID='AKIAEXAMPLE'\nSECRET_ACCESS_KEY='it'\\''s secret'\n", b.String())
+
+	b.Reset()
+	require.NoError(t, FormatSecrets(&b, ReferenceObjectID, referenceSecrets, KubernetesSecretsFormat))
+	assert.YAMLEq(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: clobjx-test-thisismyobject
+type: Opaque
+stringData:
+  accessKeyId: AKIAEXAMPLE
+  secretAccessKey: it's secret
+`, b.String())
+
+	assert.IsType(t, OptsInvalidError{}, FormatSecrets(&b, ReferenceObjectID, referenceSecrets, "xml"))
+}
+
+func TestKubernetesSecretName(t *testing.T) {
+	assert.Equal(t, "clobjx-usr-ci", KubernetesSecretName("clobjx-usr-ci"))
+	assert.Equal(t, "clobjx-db-my-db", KubernetesSecretName("clobjx-db-My_DB"))
+	assert.Equal(t, "arn-aws-iam--123456789012-user-ci", KubernetesSecretName("arn:aws:iam::123456789012:user/ci"))
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.env")
+	require.NoError(t, os.WriteFile(path, []byte("OLD=1\n"), 0644))
+
+	sink := FileSink{Path: path, Format: DotenvSecretsFormat}
+	require.NoError(t, sink.WriteSecrets(context.Background(), ReferenceObjectID, referenceSecrets))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "OLD")
+	assert.Contains(t, string(b), "ACCESS_KEY_ID='AKIAEXAMPLE'")
+
+	// Nothing is left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,315 @@
+package cloudobject
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultStoreDir  = ".cloud-objects"
+	DefaultStoreFile = "state.json"
+
+	storeFormatVersion = 1
+)
+
+// Record is the persisted state of a CloudObject after it has been applied
+type Record struct {
+	ID         ID                `json:"id"`
+	Kind       Kind              `json:"kind"`
+	Spec       json.RawMessage   `json:"spec,omitempty"`
+	ProviderID ProviderID        `json:"providerId"`
+	Secrets    map[string]string `json:"secrets,omitempty"`
+	Updated    time.Time         `json:"updated"`
+}
+
+// NewRecord compiles a Record from an applied CloudObject, the spec it was applied with and the returned secrets
+func NewRecord(kind Kind, obj CloudObject, spec CloudObjectSpec, secrets Secrets) (Record, error) {
+	rec := Record{
+		ID:      obj.ID(),
+		Kind:    kind,
+		Updated: time.Now().UTC(),
+	}
+
+	if spec != nil {
+		raw, err := json.Marshal(spec)
+		if err != nil {
+			return Record{}, err
+		}
+		rec.Spec = raw
+	}
+
+	if status := obj.Status(); status != nil {
+		rec.ProviderID = status.ProviderID()
+	}
+
+	if secrets != nil {
+		rec.Secrets = secrets.Map()
+	}
+
+	return rec, nil
+}
+
+// DecodeSpec unmarshals the last-applied spec of the Record into the given spec
+func (r Record) DecodeSpec(spec CloudObjectSpec) error {
+	if len(r.Spec) == 0 {
+		return NotExistsError{ErrorInfo: ErrorInfo{Message: fmt.Sprintf("no spec recorded for '%s'", r.ID.String())}}
+	}
+	return json.Unmarshal(r.Spec, spec)
+}
+
+func (r Record) copy() Record {
+	out := r
+	if r.Spec != nil {
+		out.Spec = append(json.RawMessage{}, r.Spec...)
+	}
+	if r.Secrets != nil {
+		out.Secrets = make(map[string]string, len(r.Secrets))
+		for k, v := range r.Secrets {
+			out.Secrets[k] = v
+		}
+	}
+	return out
+}
+
+/////////////////
+/// IN-MEMORY ///
+/////////////////
+
+// MemoryStore is a Store that only lives as long as the process. Useful for tests.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[ID]Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[ID]Record)}
+}
+
+func (s *MemoryStore) Persist(record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.ID] = record.copy()
+	return nil
+}
+
+func (s *MemoryStore) Retrieve(id ID) (Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.records[id]
+	if !ok {
+		return Record{}, NotExistsError{ErrorInfo: ErrorInfo{
+			Message: fmt.Sprintf("no record for '%s' in store", id.String()),
+		}}
+	}
+	return rec.copy(), nil
+}
+
+func (s *MemoryStore) Remove(id ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sortedRecords(s.records), nil
+}
+
+//////////////////
+/// FILE-BASED ///
+//////////////////
+
+// FileStore is a Store that keeps all records in a single JSON file. Writes are atomic (write & rename), and
+// the file is only readable by the current user, as records may contain secrets. Every access holds an advisory lock
+// on a ".lock" file next to the store, so concurrent processes (e.g. two CLI runs) don't lose each other's records.
+// Where advisory locks aren't supported, the store must not be shared between concurrent processes.
+type FileStore struct {
+	mu   *sync.Mutex
+	path string
+}
+
+// fileStoreLocks holds one lock per store file, so FileStores on the same file can be used concurrently within the
+// process. Advisory file locks don't exclude each other within a process on all platforms.
+var fileStoreLocks sync.Map
+
+type storeFile struct {
+	Version int           `json:"version"`
+	Objects map[ID]Record `json:"objects"`
+}
+
+// NewFileStore returns a FileStore persisting to the given file. The parent directory is created if necessary.
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("given store path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	mu, _ := fileStoreLocks.LoadOrStore(abs, &sync.Mutex{})
+	return &FileStore{mu: mu.(*sync.Mutex), path: path}, nil
+}
+
+// DefaultFileStore returns a FileStore at ~/.cloud-objects/state.json
+func DefaultFileStore() (*FileStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewFileStore(filepath.Join(home, DefaultStoreDir, DefaultStoreFile))
+}
+
+func (s *FileStore) Path() string {
+	return s.path
+}
+
+func (s *FileStore) Persist(record Record) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	f.Objects[record.ID] = record
+	return s.save(f)
+}
+
+func (s *FileStore) Retrieve(id ID) (Record, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return Record{}, err
+	}
+	defer unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return Record{}, err
+	}
+	rec, ok := f.Objects[id]
+	if !ok {
+		return Record{}, NotExistsError{ErrorInfo: ErrorInfo{
+			Message: fmt.Sprintf("no record for '%s' in store '%s'", id.String(), s.path),
+		}}
+	}
+	return rec, nil
+}
+
+func (s *FileStore) Remove(id ID) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Objects[id]; !ok {
+		return nil
+	}
+	delete(f.Objects, id)
+	return s.save(f)
+}
+
+func (s *FileStore) List() ([]Record, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedRecords(f.Objects), nil
+}
+
+// lock locks the store against other goroutines and processes, returning the func to unlock it again
+func (s *FileStore) lock() (func(), error) {
+	s.mu.Lock()
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("locking store '%s' failed: %w", s.path, err)
+	}
+	return func() {
+		unlock()
+		s.mu.Unlock()
+	}, nil
+}
+
+func (s *FileStore) load() (*storeFile, error) {
+	f := &storeFile{Version: storeFormatVersion, Objects: make(map[ID]Record)}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		// No file yet is just an empty store
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("store '%s' is corrupt: %w", s.path, err)
+	}
+	if f.Version != storeFormatVersion {
+		return nil, fmt.Errorf("store '%s' has unsupported version '%d'", s.path, f.Version)
+	}
+	if f.Objects == nil {
+		f.Objects = make(map[ID]Record)
+	}
+	return f, nil
+}
+
+func (s *FileStore) save(f *storeFile) error {
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file next to the store and rename it, so readers never see a half-written store
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func sortedRecords(records map[ID]Record) []Record {
+	out := make([]Record, 0, len(records))
+	for _, rec := range records {
+		out = append(out, rec.copy())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,36 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package cloudobject
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_ProcessLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultStoreFile)
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	// A lock on the lock file held elsewhere, as by another process, keeps the store from being written
+	unlock, err := lockFile(path + ".lock")
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		done <- store.Persist(getReferenceRecord(t))
+	}()
+	select {
+	case <-done:
+		t.Fatal("store was written while locked by another process")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	assert.NoError(t, <-done)
+	_, err = store.Retrieve(ReferenceObjectID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,162 @@
+package cloudobject
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ReferenceObjectID   ID     = "clobjx-test-thisismyobject"
+	ReferenceProviderID string = "arn:aws:s3:::clobjx-test-thisismyobject"
+)
+
+type testSpec struct {
+	Size int
+}
+
+func (s *testSpec) Valid() (bool, error) {
+	return true, nil
+}
+
+type testStatus struct{}
+
+func (s testStatus) String() string {
+	return "teststatus"
+}
+
+func (s testStatus) State() string {
+	return "available"
+}
+
+func (s testStatus) ProviderID() ProviderID {
+	return ProviderID{Type: AWSProvider, Value: ReferenceProviderID}
+}
+
+type testSecrets map[string]string
+
+func (s testSecrets) Map() map[string]string {
+	return s
+}
+
+type testObject struct {
+	CloudObject
+}
+
+func (o testObject) ID() ID {
+	return ReferenceObjectID
+}
+
+func (o testObject) Status() Status {
+	return testStatus{}
+}
+
+func getReferenceRecord(t *testing.T) Record {
+	rec, err := NewRecord("test", testObject{}, &testSpec{Size: 3}, testSecrets{"password": "secret"})
+	assert.NoError(t, err)
+	return rec
+}
+
+func TestNewRecord(t *testing.T) {
+	rec := getReferenceRecord(t)
+	assert.Equal(t, ReferenceObjectID, rec.ID)
+	assert.Equal(t, Kind("test"), rec.Kind)
+	assert.Equal(t, ReferenceProviderID, rec.ProviderID.Value)
+	assert.Equal(t, map[string]string{"password": "secret"}, rec.Secrets)
+
+	var spec testSpec
+	assert.NoError(t, rec.DecodeSpec(&spec))
+	assert.Equal(t, 3, spec.Size)
+
+	assert.True(t, IsNotExistsError(Record{}.DecodeSpec(&spec)))
+}
+
+func testStore(t *testing.T, store Store) {
+	_, err := store.Retrieve(ReferenceObjectID)
+	assert.True(t, IsNotExistsError(err))
+
+	rec := getReferenceRecord(t)
+	assert.NoError(t, store.Persist(rec))
+
+	got, err := store.Retrieve(ReferenceObjectID)
+	assert.NoError(t, err)
+	assert.Equal(t, rec.ProviderID, got.ProviderID)
+	assert.Equal(t, rec.Secrets, got.Secrets)
+	assert.JSONEq(t, string(rec.Spec), string(got.Spec))
+
+	list, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	assert.NoError(t, store.Remove(ReferenceObjectID))
+	assert.NoError(t, store.Remove(ReferenceObjectID))
+	_, err = store.Retrieve(ReferenceObjectID)
+	assert.True(t, IsNotExistsError(err))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", DefaultStoreFile)
+	store, err := NewFileStore(path)
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	// The store file may contain secrets, so it must only be readable by us
+	assert.NoError(t, store.Persist(getReferenceRecord(t)))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A second store on the same file sees the same records
+	other, err := NewFileStore(path)
+	assert.NoError(t, err)
+	_, err = other.Retrieve(ReferenceObjectID)
+	assert.NoError(t, err)
+
+	_, err = NewFileStore("")
+	assert.Error(t, err)
+}
+
+func TestFileStore_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultStoreFile)
+	store, err := NewFileStore(path)
+	assert.NoError(t, err)
+	// Separate stores on the same file must not lose each other's writes
+	other, err := NewFileStore(path)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := getReferenceRecord(t)
+			rec.ID = ID(string(ReferenceObjectID) + string(rune('a'+i)))
+			if i%2 == 0 {
+				assert.NoError(t, store.Persist(rec))
+			} else {
+				assert.NoError(t, other.Persist(rec))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	list, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, list, 20)
+}
+
+func TestFileStore_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultStoreFile)
+	assert.NoError(t, os.WriteFile(path, []byte("{not json"), 0600))
+	store, err := NewFileStore(path)
+	assert.NoError(t, err)
+	_, err = store.List()
+	assert.Error(t, err)
+}
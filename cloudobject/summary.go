@@ -0,0 +1,35 @@
+package cloudobject
+
+// Summary is a stable description of a CloudObject, for tools to print or consume. Unlike Status, which mirrors
+// whatever the provider returns, its schema only ever gets new fields.
+type Summary struct {
+	Kind       Kind   `json:"kind" yaml:"kind"`
+	ID         ID     `json:"id" yaml:"id"`
+	ProviderID string `json:"providerID" yaml:"providerID"`
+	State      string `json:"state" yaml:"state"`
+
+	// Details holds the key facts of the object, in a schema specific to its kind. See the Details types of the
+	// provider packages (e.g. rds.InstanceDetails).
+	Details interface{} `json:"details,omitempty" yaml:"details,omitempty"`
+}
+
+// Summarizer is implemented by Statuses that have key facts to add to their Summary
+type Summarizer interface {
+	// Details returns the key facts of the object as a struct with json and yaml tags, or nil if there are none
+	Details() interface{}
+}
+
+// Summarize returns the Summary of the given object of the given kind, as of its last read Status
+func Summarize(kind Kind, obj CloudObject) Summary {
+	summary := Summary{Kind: kind, ID: obj.ID()}
+	status := obj.Status()
+	if status == nil {
+		return summary
+	}
+	summary.ProviderID = status.ProviderID().Value
+	summary.State = status.State()
+	if s, ok := status.(Summarizer); ok {
+		summary.Details = s.Details()
+	}
+	return summary
+}
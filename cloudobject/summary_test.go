@@ -0,0 +1,45 @@
+package cloudobject
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type summaryTestStatus struct {
+	testStatus
+}
+
+func (s summaryTestStatus) Details() interface{} {
+	return struct {
+		Endpoint string `json:"endpoint"`
+	}{Endpoint: "db.example.com"}
+}
+
+type summaryTestObject struct {
+	testObject
+	status Status
+}
+
+func (o summaryTestObject) Status() Status {
+	return o.status
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize("test", testObject{})
+	assert.Equal(t, Summary{Kind: "test", ID: ReferenceObjectID, ProviderID: ReferenceProviderID, State: "available"},
+		summary)
+
+	// Statuses with details add them
+	summary = Summarize("test", summaryTestObject{status: summaryTestStatus{}})
+	out, err := json.Marshal(summary)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind": "test", "id": "`+ReferenceObjectID.String()+`", "providerID": "`+ReferenceProviderID+
+		`", "state": "available", "details": {"endpoint": "db.example.com"}}`, string(out))
+
+	// Objects that were never read have no status
+	summary = Summarize("test", summaryTestObject{})
+	assert.Equal(t, Summary{Kind: "test", ID: ReferenceObjectID}, summary)
+}
@@ -0,0 +1,147 @@
+package cloudobject
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultWaitInterval    = 5 * time.Second
+	DefaultWaitMaxInterval = time.Minute
+	DefaultWaitMultiplier  = 1.5
+)
+
+// ReadyFunc tells whether a CloudObject reached the desired state, judging by its freshly read Status. An error
+// aborts the wait, e.g. if the object ended up in a state it will never recover from.
+type ReadyFunc func(status Status) (bool, error)
+
+// ProgressFunc is called after every poll of the object with the given id that did not yet reach the desired state.
+// The status is nil if the object does not exist (yet).
+type ProgressFunc func(id ID, attempt int, elapsed time.Duration, status Status)
+
+// Waiter polls a CloudObject with exponential backoff until it reaches a desired state
+type Waiter struct {
+	// Interval is the delay between the first polls. Defaults to DefaultWaitInterval.
+	Interval time.Duration
+
+	// MaxInterval caps the delay between polls. Defaults to DefaultWaitMaxInterval.
+	MaxInterval time.Duration
+
+	// Multiplier is applied to the delay after every poll. Defaults to DefaultWaitMultiplier.
+	Multiplier float64
+
+	// Timeout bounds the whole wait. If 0, only the passed context bounds it.
+	Timeout time.Duration
+
+	// Progress is called after every unsuccessful poll, if set
+	Progress ProgressFunc
+}
+
+// NewWaiter returns a Waiter with default backoff and the given timeout
+func NewWaiter(timeout time.Duration) *Waiter {
+	return &Waiter{
+		Interval:    DefaultWaitInterval,
+		MaxInterval: DefaultWaitMaxInterval,
+		Multiplier:  DefaultWaitMultiplier,
+		Timeout:     timeout,
+	}
+}
+
+// WaitUntilReady polls the object until ready returns true. Objects that do not exist (yet) are not ready.
+func (w *Waiter) WaitUntilReady(ctx context.Context, obj CloudObject, ready ReadyFunc) error {
+	return w.wait(ctx, obj, func() (bool, Status, error) {
+		if err := obj.ReadWithContext(ctx); err != nil {
+			if IsNotExistsError(err) {
+				return false, nil, nil
+			}
+			return false, nil, err
+		}
+		ok, err := ready(obj.Status())
+		return ok, obj.Status(), err
+	})
+}
+
+// WaitUntilGone polls the object until it does not exist anymore
+func (w *Waiter) WaitUntilGone(ctx context.Context, obj CloudObject) error {
+	return w.wait(ctx, obj, func() (bool, Status, error) {
+		exists, err := obj.ExistsWithContext(ctx)
+		if err != nil || !exists {
+			return !exists, nil, err
+		}
+		return false, obj.Status(), nil
+	})
+}
+
+func (w *Waiter) wait(ctx context.Context, obj CloudObject, poll func() (bool, Status, error)) error {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	interval := w.interval()
+	for attempt := 1; ; attempt++ {
+		done, status, err := poll()
+		if err != nil {
+			// A poll that failed due to our own deadline is a timeout, not a provider error
+			if ctx.Err() != nil {
+				return w.timeoutError(ctx, obj, start)
+			}
+			return err
+		}
+		if done {
+			return nil
+		}
+		if w.Progress != nil {
+			w.Progress(obj.ID(), attempt, time.Since(start), status)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return w.timeoutError(ctx, obj, start)
+		case <-timer.C:
+		}
+		interval = w.next(interval)
+	}
+}
+
+func (w *Waiter) timeoutError(ctx context.Context, obj CloudObject, start time.Time) error {
+	if ctx.Err() == context.Canceled {
+		return ctx.Err()
+	}
+	return WaitTimeoutError{ErrorInfo: ErrorInfo{
+		Message: fmt.Sprintf("gave up waiting for '%s' after %s",
+			obj.ID().String(), time.Since(start).Round(time.Second)),
+		ID:  obj.ID(),
+		Op:  "wait",
+		Err: ctx.Err(),
+	}}
+}
+
+func (w *Waiter) interval() time.Duration {
+	if w.Interval <= 0 {
+		return DefaultWaitInterval
+	}
+	return w.Interval
+}
+
+func (w *Waiter) next(interval time.Duration) time.Duration {
+	multiplier := w.Multiplier
+	if multiplier < 1 {
+		multiplier = DefaultWaitMultiplier
+	}
+	maxInterval := w.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultWaitMaxInterval
+	}
+
+	next := time.Duration(float64(interval) * multiplier)
+	if next > maxInterval {
+		return maxInterval
+	}
+	return next
+}
@@ -0,0 +1,93 @@
+package cloudobject
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pollObject exists from the given read on, and is gone again from the given read on (if > 0)
+type pollObject struct {
+	testObject
+	reads    int
+	existsAt int
+	goneAt   int
+}
+
+func (o *pollObject) ReadWithContext(_ context.Context) error {
+	o.reads++
+	if o.reads < o.existsAt || (o.goneAt > 0 && o.reads >= o.goneAt) {
+		return NotExistsError{ErrorInfo: ErrorInfo{Message: "not there"}}
+	}
+	return nil
+}
+
+func (o *pollObject) ExistsWithContext(ctx context.Context) (bool, error) {
+	return ExistsWithContext(ctx, o)
+}
+
+func fastWaiter(timeout time.Duration) *Waiter {
+	w := NewWaiter(timeout)
+	w.Interval = time.Millisecond
+	w.MaxInterval = 2 * time.Millisecond
+	return w
+}
+
+func alwaysReady(_ Status) (bool, error) {
+	return true, nil
+}
+
+func TestWaiter_WaitUntilReady(t *testing.T) {
+	obj := &pollObject{existsAt: 3}
+	var attempts []int
+	w := fastWaiter(time.Second)
+	w.Progress = func(id ID, attempt int, _ time.Duration, status Status) {
+		assert.Equal(t, ReferenceObjectID, id)
+		attempts = append(attempts, attempt)
+		assert.Nil(t, status)
+	}
+	assert.NoError(t, w.WaitUntilReady(context.Background(), obj, alwaysReady))
+	assert.Equal(t, 3, obj.reads)
+	assert.Equal(t, []int{1, 2}, attempts)
+
+	// A failing predicate aborts the wait right away
+	obj = &pollObject{existsAt: 1}
+	err := fastWaiter(time.Second).WaitUntilReady(context.Background(), obj, func(_ Status) (bool, error) {
+		return false, fmt.Errorf("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, 1, obj.reads)
+}
+
+func TestWaiter_WaitUntilGone(t *testing.T) {
+	obj := &pollObject{existsAt: 1, goneAt: 4}
+	assert.NoError(t, fastWaiter(time.Second).WaitUntilGone(context.Background(), obj))
+	assert.Equal(t, 4, obj.reads)
+}
+
+func TestWaiter_Timeout(t *testing.T) {
+	obj := &pollObject{existsAt: 1}
+	err := fastWaiter(20*time.Millisecond).WaitUntilReady(context.Background(), obj, func(_ Status) (bool, error) {
+		return false, nil
+	})
+	assert.True(t, IsWaitTimeoutError(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = fastWaiter(time.Second).WaitUntilGone(ctx, obj)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWaiter_Backoff(t *testing.T) {
+	w := &Waiter{Interval: time.Second, MaxInterval: 3 * time.Second, Multiplier: 2}
+	assert.Equal(t, 2*time.Second, w.next(time.Second))
+	assert.Equal(t, 3*time.Second, w.next(2*time.Second))
+
+	// Zero values fall back to the defaults
+	w = &Waiter{}
+	assert.Equal(t, DefaultWaitInterval, w.interval())
+	assert.Equal(t, DefaultWaitMaxInterval, w.next(DefaultWaitMaxInterval))
+}
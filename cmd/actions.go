@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -13,6 +17,7 @@ const (
 	ReadCloudObjectAction   CloudObjectAction = "read"
 	UpdateCloudObjectAction CloudObjectAction = "update"
 	DeleteCloudObjectAction CloudObjectAction = "delete"
+	PlanCloudObjectAction   CloudObjectAction = "plan"
 )
 
 type CloudObjectAction string
@@ -40,27 +45,258 @@ func IsCloudObjectAction(s string) bool {
 		return true
 	case DeleteCloudObjectAction:
 		return true
+	case PlanCloudObjectAction:
+		return true
 	default:
 		return false
 	}
 }
 
+// checkSpec validates the spec for the actions that apply or plan it. Read and delete don't look at the spec, so
+// they work without one.
+func checkSpec(action CloudObjectAction, spec cloudobject.CloudObjectSpec) error {
+	switch action {
+	case CreateCloudObjectAction, UpdateCloudObjectAction, PlanCloudObjectAction:
+		_, err := spec.Valid()
+		return err
+	default:
+		return nil
+	}
+}
+
 func HandleCloudObject(obj cloudobject.CloudObject, spec cloudobject.CloudObjectSpec,
+	action CloudObjectAction, purge bool) (cloudobject.Secrets, error) {
+	return HandleCloudObjectWithContext(context.Background(), obj, spec, action, purge)
+}
+
+// HandleCloudObjectWithContext is the same as HandleCloudObject with the addition of the ability to pass a context
+func HandleCloudObjectWithContext(ctx context.Context, obj cloudobject.CloudObject, spec cloudobject.CloudObjectSpec,
 	action CloudObjectAction, purge bool) (cloudobject.Secrets, error) {
 	switch action {
 	case CreateCloudObjectAction:
-		return obj.Create(spec)
+		return obj.CreateWithContext(ctx, spec)
 	case ReadCloudObjectAction:
-		return nil, obj.Read()
+		return nil, obj.ReadWithContext(ctx)
 	case UpdateCloudObjectAction:
-		return obj.Update(spec)
+		return obj.UpdateWithContext(ctx, spec)
 	case DeleteCloudObjectAction:
-		return nil, obj.Delete(purge)
+		return nil, obj.DeleteWithContext(ctx, purge)
 	default:
 		return nil, CloudObjectActionUnknown{Message: fmt.Sprintf("action '%s' unknown", string(action))}
 	}
 }
 
+// WaitForCloudObject waits until the object reached the state the action should lead to: ready after create and
+// update, gone after delete.
+func WaitForCloudObject(ctx context.Context, waiter *cloudobject.Waiter, obj cloudobject.CloudObject,
+	action CloudObjectAction, ready cloudobject.ReadyFunc) error {
+	switch action {
+	case CreateCloudObjectAction, UpdateCloudObjectAction:
+		return waiter.WaitUntilReady(ctx, obj, ready)
+	case DeleteCloudObjectAction:
+		return waiter.WaitUntilGone(ctx, obj)
+	default:
+		return nil
+	}
+}
+
+// getPurge returns whether to purge the object on the action, i.e. whether it is a delete and --purge is set.
+// Purging can't be undone, so unless --yes is set too, it has to be confirmed by typing the object's ID.
+func getPurge(cmd *cobra.Command, action CloudObjectAction, id cloudobject.ID) (bool, error) {
+	purge, err := purgeRequested(cmd, action)
+	if err != nil || !purge {
+		return false, err
+	}
+	return true, confirmPurge(cmd, id.String(), []cloudobject.ID{id})
+}
+
+// purgeRequested returns whether the action is a delete and --purge is set
+func purgeRequested(cmd *cobra.Command, action CloudObjectAction) (bool, error) {
+	if action != DeleteCloudObjectAction {
+		return false, nil
+	}
+	return cmd.Flags().GetBool(PurgeFlag)
+}
+
+// confirmPurge lists the objects that are about to be purged on stderr, and asks for the given confirmation to be
+// typed on stdin. With --yes, it doesn't ask.
+func confirmPurge(cmd *cobra.Command, confirmation string, ids []cloudobject.ID) error {
+	yes, err := cmd.Flags().GetBool(YesFlag)
+	if err != nil || yes {
+		return err
+	}
+
+	w := cmd.ErrOrStderr()
+	fmt.Fprintln(w, "Purging can't be undone. These objects will be deleted along with everything that is otherwise "+
+		"kept around (final snapshots, keys, ...):")
+	for _, id := range ids {
+		fmt.Fprintf(w, "  %s\n", id.String())
+	}
+	fmt.Fprintf(w, "Type '%s' to confirm: ", confirmation)
+	line, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if strings.TrimSpace(line) != confirmation {
+		return UsageError{Message: "purge not confirmed (use --yes to skip the confirmation)"}
+	}
+	return nil
+}
+
+// runCloudObject is what all cloud object commands do: handle the action, wait for it to settle if requested,
+// record the outcome in the local store and print the resulting summary in the format given by --output. The plan
+// action only prints the diff.
+func runCloudObject(cmd *cobra.Command, kind cloudobject.Kind, obj cloudobject.CloudObject,
+	spec cloudobject.CloudObjectSpec, action CloudObjectAction, purge bool,
+	ready cloudobject.ReadyFunc) (cloudobject.Secrets, error) {
+	format, err := getOutputFormat(cmd)
+	if err != nil {
+		return nil, err
+	}
+	logger, err := getLogger(cmd)
+	if err != nil {
+		return nil, err
+	}
+	setLogger(obj, logger)
+	ctx, cancel := GetContext(cmd)
+	defer cancel()
+
+	if action == PlanCloudObjectAction {
+		diff, err := obj.PlanWithContext(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		return nil, printDiff(cmd.OutOrStdout(), format, diff)
+	}
+
+	waiter, err := getWaiter(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := settleCloudObject(ctx, cmd, waiter, kind, obj, spec, action, purge, ready)
+	if err != nil {
+		return nil, err
+	}
+	return secrets, printSummary(cmd.OutOrStdout(), format, summarizeCloudObject(ctx, kind, obj, action))
+}
+
+// summarizeCloudObject returns the Summary of the object after the action. Deleted objects are read again, to tell
+// whether they're gone already or still on their way.
+func summarizeCloudObject(ctx context.Context, kind cloudobject.Kind, obj cloudobject.CloudObject,
+	action CloudObjectAction) cloudobject.Summary {
+	if action == DeleteCloudObjectAction {
+		if err := obj.ReadWithContext(ctx); cloudobject.IsNotExistsError(err) {
+			summary := cloudobject.Summarize(kind, obj)
+			summary.State = DeletedState
+			summary.Details = nil
+			return summary
+		}
+	}
+	return cloudobject.Summarize(kind, obj)
+}
+
+// settleCloudObject handles the action, waits for it to settle if we got a waiter, and records the outcome in the
+// local store
+func settleCloudObject(ctx context.Context, cmd *cobra.Command, waiter *cloudobject.Waiter, kind cloudobject.Kind,
+	obj cloudobject.CloudObject, spec cloudobject.CloudObjectSpec, action CloudObjectAction, purge bool,
+	ready cloudobject.ReadyFunc) (cloudobject.Secrets, error) {
+	secrets, err := HandleCloudObjectWithContext(ctx, obj, spec, action, purge)
+	// Objects that are still settling (e.g. an RDS Instance that is being created) can't be deleted yet. If we're
+	// asked to wait, we wait until they're ready and try again.
+	if waiter != nil && action == DeleteCloudObjectAction && cloudobject.IsNotReadyError(err) {
+		if err = waiter.WaitUntilReady(ctx, obj, ready); err != nil {
+			return nil, err
+		}
+		secrets, err = HandleCloudObjectWithContext(ctx, obj, spec, action, purge)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if waiter != nil {
+		if err := WaitForCloudObject(ctx, waiter, obj, action, ready); err != nil {
+			return nil, err
+		}
+		// Waiting read the object again, so its secrets may be complete now
+		if reader, ok := obj.(cloudobject.SecretsReader); ok && action != DeleteCloudObjectAction && secrets != nil {
+			secrets = reader.Secrets()
+		}
+	}
+
+	recordCloudObject(cmd, kind, obj, spec, secrets, action)
+	return secrets, nil
+}
+
+// getWaiter returns a waiter reporting progress to stderr if --wait is set, nil otherwise
+func getWaiter(cmd *cobra.Command) (*cloudobject.Waiter, error) {
+	wait, err := cmd.Flags().GetBool(WaitFlag)
+	if err != nil || !wait {
+		return nil, err
+	}
+	waitTimeout, err := cmd.Flags().GetDuration(WaitTimeoutFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	waiter := cloudobject.NewWaiter(waitTimeout)
+	waiter.Progress = func(id cloudobject.ID, attempt int, elapsed time.Duration, status cloudobject.Status) {
+		state := "not found"
+		if status != nil && status.State() != "" {
+			state = status.State()
+		}
+		cmd.PrintErrln(fmt.Sprintf("waiting for '%s'... (attempt %d, %s elapsed, state: %s)", id.String(),
+			attempt, elapsed.Round(time.Second), state))
+	}
+	return waiter, nil
+}
+
+// RecordCloudObject keeps the store in sync with the outcome of a successful action. Created and updated objects
+// are persisted with the applied spec and returned secrets, deleted ones are removed. Secrets like generated
+// passwords are only handed out once, so recorded secrets are kept unless all of them were returned again.
+func RecordCloudObject(store cloudobject.Store, kind cloudobject.Kind, obj cloudobject.CloudObject,
+	spec cloudobject.CloudObjectSpec, secrets cloudobject.Secrets, action CloudObjectAction) error {
+	switch action {
+	case CreateCloudObjectAction, UpdateCloudObjectAction:
+		rec, err := cloudobject.NewRecord(kind, obj, spec, secrets)
+		if err != nil {
+			return err
+		}
+		old, err := store.Retrieve(obj.ID())
+		if err != nil && !cloudobject.IsNotExistsError(err) {
+			return err
+		}
+		if !coversSecrets(rec.Secrets, old.Secrets) {
+			rec.Secrets = old.Secrets
+		}
+		return store.Persist(rec)
+	case DeleteCloudObjectAction:
+		return store.Remove(obj.ID())
+	default:
+		return nil
+	}
+}
+
+// coversSecrets tells whether the secrets have all keys of the recorded ones
+func coversSecrets(secrets, recorded map[string]string) bool {
+	for k := range recorded {
+		if secrets[k] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// recordCloudObject records the action in the local store. The action already happened, so failing to record it
+// is only worth a warning.
+func recordCloudObject(cmd *cobra.Command, kind cloudobject.Kind, obj cloudobject.CloudObject,
+	spec cloudobject.CloudObjectSpec, secrets cloudobject.Secrets, action CloudObjectAction) {
+	store, err := GetStore()
+	if err == nil {
+		err = RecordCloudObject(store, kind, obj, spec, secrets, action)
+	}
+	if err != nil {
+		cmd.PrintErrln(fmt.Sprintf("warning: could not record '%s' in local state: %s", obj.ID().String(), err.Error()))
+	}
+}
+
 type CloudObjectActionUnknown struct {
 	Message string
 }
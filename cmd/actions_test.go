@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/aws/fake"
+	"github.com/redradrat/cloud-objects/aws/kms"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+type testSecrets map[string]string
+
+func (s testSecrets) Map() map[string]string {
+	return s
+}
+
+func TestRecordCloudObject(t *testing.T) {
+	backend := fake.NewBackend()
+	key, err := kms.NewKey("mydb", backend.Session())
+	require.NoError(t, err)
+	spec := &kms.KeySpec{KeyUsage: kms.EncryptDecryptKeyUsage, KeyType: kms.SymmetricDefaultKeyType}
+	_, err = key.Create(spec)
+	require.NoError(t, err)
+	store := cloudobject.NewMemoryStore()
+
+	created := testSecrets{"username": "master", "password": "generated"}
+	require.NoError(t, RecordCloudObject(store, kms.KeyKind, key, spec, created, CreateCloudObjectAction))
+	rec, err := store.Retrieve(key.ID())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string(created), rec.Secrets)
+
+	// Updates that don't hand out the password again keep the recorded secrets...
+	for _, secrets := range []cloudobject.Secrets{nil, testSecrets{"username": "master"}} {
+		require.NoError(t, RecordCloudObject(store, kms.KeyKind, key, spec, secrets, UpdateCloudObjectAction))
+		rec, err = store.Retrieve(key.ID())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string(created), rec.Secrets)
+	}
+
+	// ...while those that do replace them
+	rotated := testSecrets{"username": "master", "password": "rotated"}
+	require.NoError(t, RecordCloudObject(store, kms.KeyKind, key, spec, rotated, UpdateCloudObjectAction))
+	rec, err = store.Retrieve(key.ID())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string(rotated), rec.Secrets)
+
+	require.NoError(t, RecordCloudObject(store, kms.KeyKind, key, spec, nil, DeleteCloudObjectAction))
+	_, err = store.Retrieve(key.ID())
+	assert.True(t, cloudobject.IsNotExistsError(err))
+}
+
+func TestPasswordSinks(t *testing.T) {
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	sinks := []secretSink{
+		{SecretSink: cloudobject.WriterSink{Writer: &bytes.Buffer{}}, target: "stdout"},
+		{SecretSink: cloudobject.FileSink{Path: path}, target: path},
+	}
+
+	assert.Equal(t, sinks, passwordSinks(cmd, sinks, "clobjx-db-mydb", testSecrets{"password": "secret"}))
+	assert.Empty(t, out.String())
+
+	// Secrets without a password only go to stdout, so they don't replace the password written before
+	assert.Equal(t, sinks[:1], passwordSinks(cmd, sinks, "clobjx-db-mydb", testSecrets{"host": "localhost"}))
+	assert.Contains(t, out.String(), path)
+}
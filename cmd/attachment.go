@@ -0,0 +1,79 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/redradrat/cloud-objects/aws/iam"
+
+	"github.com/spf13/cobra"
+)
+
+var attachmentSpec iam.PolicyAttachmentSpec
+
+// attachmentCmd represents the attachment command
+var attachmentCmd = &cobra.Command{
+	Use:   "attachment",
+	Args:  OnlyCloudObjectAction(),
+	Short: "Interact with the IAM policy attachment cloud object",
+	Long: `Interact with the IAM policy attachment cloud object. Policies and targets are given by name, or by
+ARN for anything managed elsewhere. For example:
+
+	*) cloud-objects aws iam attachment create --policy testpolicy --type role --target testrole
+
+	*) cloud-objects aws iam attachment delete --policy arn:aws:iam::aws:policy/ReadOnlyAccess --type group --target testgroup`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		action := CloudObjectAction(args[0])
+		// An attachment is identified by its spec, so it has to be valid for all actions
+		if _, err := attachmentSpec.Valid(); err != nil {
+			return err
+		}
+
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		att, err := iam.NewPolicyAttachment(attachmentSpec.Policy, attachmentSpec.Type, attachmentSpec.Target,
+			session)
+		if err != nil {
+			return err
+		}
+
+		purge, err := getPurge(cmd, action, att.ID())
+		if err != nil {
+			return err
+		}
+		_, err = runCloudObject(cmd, iam.PolicyAttachmentKind, att, &attachmentSpec, action, purge, iam.Available)
+		return err
+	},
+}
+
+func init() {
+	iamCmd.AddCommand(attachmentCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// attachmentCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	attachmentCmd.Flags().StringVar(&attachmentSpec.Policy, "policy", "", "The name or ARN of the policy to attach")
+	attachmentCmd.Flags().StringVar((*string)(&attachmentSpec.Type), "type", string(iam.RoleAttachmentType),
+		"What to attach the policy to ('role', 'user' or 'group')")
+	attachmentCmd.Flags().StringVar(&attachmentSpec.Target, "target", "",
+		"The name or ARN of the role, user or group to attach the policy to")
+}
@@ -16,12 +16,35 @@ limitations under the License.
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/redradrat/cloud-objects/aws"
 )
 
 const (
-	RegionFlag = "region"
-	PurgeFlag  = "purge"
+	RegionFlag           = "region"
+	AccountFlag          = "account"
+	ProfileFlag          = "profile"
+	AssumeRoleARNFlag    = "assume-role-arn"
+	ExternalIDFlag       = "external-id"
+	MFASerialFlag        = "mfa-serial"
+	RoleSessionNameFlag  = "role-session-name"
+	EndpointURLFlag      = "endpoint-url"
+	EndpointFlag         = "endpoint"
+	S3ForcePathStyleFlag = "s3-force-path-style"
+	DisableSSLFlag       = "disable-ssl"
+	AccessKeyIDFlag      = "access-key-id"
+	SecretAccessKeyFlag  = "secret-access-key"
+	SessionTokenFlag     = "session-token"
+	MaxRetriesFlag       = "max-retries"
+	LogLevelFlag         = "log-level"
+	PurgeFlag            = "purge"
+	YesFlag              = "yes"
+	WaitFlag             = "wait"
+	WaitTimeoutFlag      = "wait-timeout"
 )
 
 // awsCmd represents the aws command
@@ -39,10 +62,42 @@ func init() {
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
-	awsCmd.PersistentFlags().String(RegionFlag, "", "The AWS region to work with")
-	awsCmd.PersistentFlags().Bool(PurgeFlag, false, "Whether to purge on deletion")
+	addAWSFlags(awsCmd.PersistentFlags())
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// awsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// addAWSFlags adds the flags every command working with AWS cloud objects understands
+func addAWSFlags(flags *pflag.FlagSet) {
+	flags.String(RegionFlag, "", "The AWS region to work with")
+	flags.String(AccountFlag, "",
+		"The account to work with, as named in the 'accounts' section of the config file")
+	flags.String(ProfileFlag, "", "The shared config profile to use")
+	flags.String(AssumeRoleARNFlag, "", "The ARN of a role to assume for working with AWS")
+	flags.String(ExternalIDFlag, "", "The external id to pass when assuming --assume-role-arn")
+	flags.String(MFASerialFlag, "",
+		"The MFA device --assume-role-arn requires; its token code is prompted for")
+	flags.String(RoleSessionNameFlag, "", "The session name to use when assuming --assume-role-arn")
+	flags.String(EndpointURLFlag, "",
+		"The endpoint to use for all AWS services instead of theirs, e.g. 'http://localhost:4566' for LocalStack")
+	flags.StringToString(EndpointFlag, nil,
+		"The endpoint to use for a single AWS service, as '<service>=<url>' (e.g. 's3=http://localhost:9000')")
+	flags.Bool(S3ForcePathStyleFlag, false, "Whether to use path-style addressing for S3 buckets")
+	flags.Bool(DisableSSLFlag, false, "Whether to use http for endpoints given without a scheme")
+	flags.String(AccessKeyIDFlag, "", "The AWS access key id to use instead of the credential chain")
+	flags.String(SecretAccessKeyFlag, "", "The AWS secret access key to use with --access-key-id")
+	flags.String(SessionTokenFlag, "", "The AWS session token to use with --access-key-id")
+	flags.Int(MaxRetriesFlag, aws.DefaultRetryMaxRetries,
+		"How often to retry failed AWS requests on throttling, transient errors and eventual consistency")
+	flags.String(LogLevelFlag, WarnLogLevel,
+		"What to log to stderr: 'debug' (every AWS call), 'info', 'warn' (retries) or 'error'")
+	flags.StringP(OutputFlag, "o", TableOutput, "The output format: 'table', 'json', 'yaml' or 'name'")
+	flags.Bool(PurgeFlag, false,
+		"Whether to purge on deletion, i.e. to also delete what is otherwise kept around (final snapshots, keys, ...)")
+	flags.BoolP(YesFlag, "y", false, "Whether to skip the confirmation of --purge")
+	flags.Bool(WaitFlag, false,
+		"Whether to wait until the cloud object is ready after create/update, or gone after delete")
+	flags.Duration(WaitTimeoutFlag, 30*time.Minute, "How long to wait at most with --wait")
+}
@@ -18,8 +18,6 @@ package cmd
 import (
 	"github.com/redradrat/cloud-objects/aws/s3"
 
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -35,25 +33,24 @@ var bucketCmd = &cobra.Command{
 	*) cloud-objects aws s3 bucket create --name testbucket
 
 	*) cloud-objects aws s3 bucket delete --name testbucket`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		session, err := GetSession(cmd)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 		ins, err := s3.NewBucket(bucketName, session)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 		spec := s3.SaneS3Bucket()
 
-		_, err = HandleCloudObject(ins, &spec, CloudObjectAction(args[0]), false)
+		purge, err := getPurge(cmd, CloudObjectAction(args[0]), ins.ID())
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
-		fmt.Println(ins.Status().String())
+		_, err = runCloudObject(cmd, s3.BucketKind, ins, &spec, CloudObjectAction(args[0]), purge,
+			s3.BucketAvailable)
+		return err
 	},
 }
 
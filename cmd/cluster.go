@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/redradrat/cloud-objects/aws/rds"
+
+	"github.com/spf13/cobra"
+)
+
+var clusterName string
+var clusterEngine string
+var clusterReaders int64
+
+// clusterCmd represents the cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Args:  OnlyCloudObjectAction(),
+	Short: "Interact with the RDS Aurora cluster cloud object",
+	Long: `Interact with the RDS Aurora cluster cloud object: a cluster with a writer instance and --readers reader
+instances. For example:
+
+	*) cloud-objects aws rds cluster create --name testcluster --instanceClass db.r5.large --readers 1
+
+	*) cloud-objects aws rds cluster delete --name testcluster
+
+Like instances, clusters are encrypted with a KMS key of their own, leave a final snapshot on delete unless purged,
+and are restored from it on create. Connection details are handed out the same way as for instances, with the
+reader endpoint added as readerHost and readerDsn.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		sinks, err := getSecretSinks(cmd, session)
+		if err != nil {
+			return err
+		}
+		cluster, err := rds.NewCluster(clusterName, session)
+		if err != nil {
+			return err
+		}
+		spec := rds.SaneAuroraPostgres(clusterName, subnetGroupName, instanceClass, username, password,
+			clusterReaders, nil, securityGroupIDs)
+		switch rds.ClusterDBEngine(clusterEngine) {
+		case rds.AuroraPostgreSQLClusterDBEngine:
+		case rds.AuroraMySQLClusterDBEngine:
+			// Let AWS pick the version and port of the engine
+			spec.Engine = rds.AuroraMySQLClusterDBEngine
+			spec.EngineVersion = ""
+			spec.Port = 0
+		default:
+			return UsageError{Message: fmt.Sprintf("unknown engine '%s' (use '%s' or '%s')", clusterEngine,
+				rds.AuroraPostgreSQLClusterDBEngine, rds.AuroraMySQLClusterDBEngine)}
+		}
+		action := CloudObjectAction(args[0])
+		if err := checkSpec(action, &spec); err != nil {
+			return err
+		}
+
+		purge, err := getPurge(cmd, action, cluster.ID())
+		if err != nil {
+			return err
+		}
+		secrets, err := runCloudObject(cmd, rds.ClusterKind, cluster, &spec, action, purge, rds.ClusterAvailable)
+		if err != nil {
+			return err
+		}
+		return writeCredentials(cmd, passwordSinks(cmd, sinks, cluster.ID(), secrets), cluster.ID(), secrets)
+	},
+}
+
+func init() {
+	rdsCmd.AddCommand(clusterCmd)
+
+	clusterCmd.Flags().StringVarP(&clusterName, "name", "n", "", "The name of the cluster")
+	clusterCmd.Flags().StringVar(&clusterEngine, "engine", rds.AuroraPostgreSQLClusterDBEngine.String(),
+		"The Aurora engine to use ('aurora-postgresql' or 'aurora-mysql')")
+	clusterCmd.Flags().StringVar(&subnetGroupName, "subnetGroup", "", "The subnetGroup to use")
+	clusterCmd.Flags().StringVar(&instanceClass, "instanceClass", "", "The instance class of all instances")
+	clusterCmd.Flags().Int64Var(&clusterReaders, "readers", 0, "The number of reader instances next to the writer")
+	clusterCmd.Flags().StringVar(&username, "username", "", "The master user name")
+	clusterCmd.Flags().StringVar(&password, "password", "",
+		"The master user password; one is generated on create if not given")
+	clusterCmd.Flags().StringSliceVar(&securityGroupIDs, "securityGroups", []string{},
+		"The securityGroupIDs to attach to")
+	addCredentialsFlags(clusterCmd.Flags())
+}
@@ -1,26 +1,183 @@
 package cmd
 
 import (
-	awssdk "github.com/aws/aws-sdk-go/aws"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
 	"github.com/aws/aws-sdk-go/aws/client"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/cloudobject"
 )
 
+// GetSession returns the AWS session configured by the command's flags, for the account given by --account
 func GetSession(cmd *cobra.Command) (client.ConfigProvider, error) {
-	reg, err := cmd.Flags().GetString(RegionFlag)
+	account, err := cmd.Flags().GetString(AccountFlag)
 	if err != nil {
 		return nil, err
 	}
-	conf := &awssdk.Config{}
-	if reg != "" {
-		conf.Region = awssdk.String(reg)
+	return GetAccountSession(cmd, account)
+}
+
+// GetAccountSession returns the AWS session for the given account. Every setting is taken from the command's flags
+// if given, from the account's entry in the "accounts" section of the config file otherwise, and from the "aws"
+// section as a last resort. An empty account only uses flags and the "aws" section.
+func GetAccountSession(cmd *cobra.Command, account string) (client.ConfigProvider, error) {
+	if account != "" && !viper.IsSet(accountKey(account, "")) {
+		return nil, fmt.Errorf("account '%s' is not configured in the 'accounts' section of the config file",
+			account)
 	}
 
-	cp, err := session.NewSession(conf)
+	logger, err := getLogger(cmd)
 	if err != nil {
 		return nil, err
 	}
+	s := settings{flags: cmd.Flags(), account: account}
+	opts := aws.SessionOptions{
+		Region:           s.string(RegionFlag, "region"),
+		Endpoint:         s.string(EndpointURLFlag, "endpointURL"),
+		Endpoints:        s.stringMap(EndpointFlag, "endpoints"),
+		S3ForcePathStyle: s.bool(S3ForcePathStyleFlag, "s3ForcePathStyle"),
+		DisableSSL:       s.bool(DisableSSLFlag, "disableSSL"),
+		Profile:          s.string(ProfileFlag, "profile"),
+		AccessKeyID:      s.string(AccessKeyIDFlag, "accessKeyID"),
+		SecretAccessKey:  s.string(SecretAccessKeyFlag, "secretAccessKey"),
+		SessionToken:     s.string(SessionTokenFlag, "sessionToken"),
+		AssumeRoleARN:    s.string(AssumeRoleARNFlag, "assumeRoleARN"),
+		ExternalID:       s.string(ExternalIDFlag, "externalID"),
+		MFASerial:        s.string(MFASerialFlag, "mfaSerial"),
+		RoleSessionName:  s.string(RoleSessionNameFlag, "roleSessionName"),
+		TokenProvider:    promptMFAToken,
+		Retryer:          newRetryer(logger, s.int(MaxRetriesFlag, "maxRetries")),
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
 
-	return cp, nil
+	return aws.NewSession(opts)
+}
+
+// newRetryer returns the default Retryer with the given number of retries for all operations, which logs the
+// retries of requests not made on behalf of an object to the given logger
+func newRetryer(logger cloudobject.Logger, maxRetries int) *aws.Retryer {
+	retryer := aws.NewRetryer()
+	retryer.Default.MaxRetries = maxRetries
+	for op, policy := range retryer.Operations {
+		policy.MaxRetries = maxRetries
+		retryer.Operations[op] = policy
+	}
+	retryer.Logger = logger
+	return retryer
+}
+
+// settings looks up session settings in flags and config. The first error is kept, so a series of lookups can be
+// checked once.
+type settings struct {
+	flags   *pflag.FlagSet
+	account string
+	err     error
+}
+
+// key returns the config key holding the given setting: the account's, if it has it, and the "aws" section's
+// otherwise
+func (s *settings) key(key string) string {
+	if s.account != "" && viper.IsSet(accountKey(s.account, key)) {
+		return accountKey(s.account, key)
+	}
+	return "aws." + key
+}
+
+func (s *settings) string(flag, key string) string {
+	if !s.flags.Changed(flag) {
+		return viper.GetString(s.key(key))
+	}
+	val, err := s.flags.GetString(flag)
+	if s.err == nil {
+		s.err = err
+	}
+	return val
+}
+
+func (s *settings) bool(flag, key string) bool {
+	if !s.flags.Changed(flag) {
+		return viper.GetBool(s.key(key))
+	}
+	val, err := s.flags.GetBool(flag)
+	if s.err == nil {
+		s.err = err
+	}
+	return val
+}
+
+func (s *settings) int(flag, key string) int {
+	// Unlike the others, ints default to their flag's default rather than to zero
+	if !s.flags.Changed(flag) && viper.IsSet(s.key(key)) {
+		return viper.GetInt(s.key(key))
+	}
+	val, err := s.flags.GetInt(flag)
+	if s.err == nil {
+		s.err = err
+	}
+	return val
+}
+
+func (s *settings) stringMap(flag, key string) map[string]string {
+	if !s.flags.Changed(flag) {
+		return viper.GetStringMapString(s.key(key))
+	}
+	val, err := s.flags.GetStringToString(flag)
+	if s.err == nil {
+		s.err = err
+	}
+	return val
+}
+
+// accountKey returns the config key of a setting of the given account, or of the account itself for an empty key
+func accountKey(account, key string) string {
+	if key == "" {
+		return "accounts." + account
+	}
+	return "accounts." + account + "." + key
+}
+
+// mfaPromptMu makes sure sessions of different accounts don't prompt for their MFA token codes at the same time
+var mfaPromptMu sync.Mutex
+
+// promptMFAToken asks for an MFA token code on stdin. The prompt goes to stderr, to not mix with command output.
+func promptMFAToken() (string, error) {
+	mfaPromptMu.Lock()
+	defer mfaPromptMu.Unlock()
+
+	fmt.Fprint(os.Stderr, "MFA token code: ")
+	var code string
+	if _, err := fmt.Scanln(&code); err != nil {
+		return "", fmt.Errorf("reading MFA token code: %w", err)
+	}
+	return code, nil
+}
+
+// GetStore returns the local store, where we keep track of the cloud objects we applied
+func GetStore() (cloudobject.Store, error) {
+	if stateFile != "" {
+		return cloudobject.NewFileStore(stateFile)
+	}
+	return cloudobject.DefaultFileStore()
+}
+
+// GetContext returns the context for a command run, bound by the --timeout flag if set. The returned cancel func
+// must always be called.
+func GetContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
 }
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/redradrat/cloud-objects/aws/sink"
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const (
+	CredentialsFileFlag   = "credentialsFile"
+	CredentialsFormatFlag = "credentialsFormat"
+	SecretsToFlag         = "secrets-to"
+
+	JSONCredentialsFormat CredentialsFormat = "json"
+	EnvCredentialsFormat  CredentialsFormat = "env"
+
+	FileSecretSink           = "file"
+	DotenvSecretSink         = "dotenv"
+	KubernetesSecretSink     = "k8s"
+	SecretsManagerSecretSink = "secretsmanager"
+	SSMSecretSink            = "ssm"
+)
+
+var secretSinkKinds = []string{FileSecretSink, DotenvSecretSink, KubernetesSecretSink, SecretsManagerSecretSink,
+	SSMSecretSink}
+
+// CredentialsFormat is how generated credentials are written out
+type CredentialsFormat string
+
+// Valid tells whether the CredentialsFormat is one we know
+func (format CredentialsFormat) Valid() bool {
+	return format == JSONCredentialsFormat || format == EnvCredentialsFormat
+}
+
+// SecretsFormat returns the cloudobject.SecretsFormat the CredentialsFormat stands for
+func (format CredentialsFormat) SecretsFormat() cloudobject.SecretsFormat {
+	if format == EnvCredentialsFormat {
+		return cloudobject.DotenvSecretsFormat
+	}
+	return cloudobject.JSONSecretsFormat
+}
+
+// addCredentialsFlags adds the flags of commands that hand out generated credentials
+func addCredentialsFlags(flags *pflag.FlagSet) {
+	flags.String(CredentialsFileFlag, "",
+		"Write generated credentials to this file (created with 0600 permissions) instead of stdout")
+	flags.String(CredentialsFormatFlag, string(JSONCredentialsFormat),
+		"The format to write generated credentials in ('json', or 'env' for a file that can be sourced by a shell)")
+	flags.StringSlice(SecretsToFlag, []string{}, "Where to write generated credentials to, as <kind>:<target> "+
+		"(repeatable): 'file:<path>' (JSON), 'dotenv:<path>' or 'k8s:<path>' (a Kubernetes Secret manifest) for "+
+		"files created with 0600 permissions ('-' for stdout), 'secretsmanager:[<name>]' for a Secrets Manager "+
+		"secret, or 'ssm:[<prefix>]' for SSM parameters; names default to the object's ID")
+}
+
+// secretSink is a sink given on the command line, along with how it was given, for error messages
+type secretSink struct {
+	cloudobject.SecretSink
+	target string
+}
+
+// getSecretSinks returns the sinks the credentials flags ask for: those of --secrets-to, or else the file or stdout
+// of --credentialsFile in the format of --credentialsFormat. It fails early on bad flags, as we can't ask AWS for
+// the credentials again once they were generated.
+func getSecretSinks(cmd *cobra.Command, session client.ConfigProvider) ([]secretSink, error) {
+	targets, err := cmd.Flags().GetStringSlice(SecretsToFlag)
+	if err != nil {
+		return nil, err
+	}
+	path, err := cmd.Flags().GetString(CredentialsFileFlag)
+	if err != nil {
+		return nil, err
+	}
+	format, err := cmd.Flags().GetString(CredentialsFormatFlag)
+	if err != nil {
+		return nil, err
+	}
+	if !CredentialsFormat(format).Valid() {
+		return nil, UsageError{Message: fmt.Sprintf("unknown credentials format '%s' (use 'json' or 'env')", format)}
+	}
+
+	if len(targets) == 0 {
+		if path == "" {
+			return []secretSink{{
+				SecretSink: cloudobject.WriterSink{
+					Writer: cmd.OutOrStdout(),
+					Format: CredentialsFormat(format).SecretsFormat(),
+				},
+				target: "stdout",
+			}}, nil
+		}
+		return []secretSink{{
+			SecretSink: cloudobject.FileSink{Path: path, Format: CredentialsFormat(format).SecretsFormat()},
+			target:     path,
+		}}, nil
+	}
+	if path != "" || cmd.Flags().Changed(CredentialsFormatFlag) {
+		return nil, UsageError{Message: fmt.Sprintf("--%s can't be combined with --%s or --%s", SecretsToFlag,
+			CredentialsFileFlag, CredentialsFormatFlag)}
+	}
+
+	logger, err := getLogger(cmd)
+	if err != nil {
+		return nil, err
+	}
+	sinks := make([]secretSink, 0, len(targets))
+	for _, target := range targets {
+		s, err := newSecretSink(cmd, session, target)
+		if err != nil {
+			return nil, err
+		}
+		setLogger(s, logger)
+		sinks = append(sinks, secretSink{SecretSink: s, target: target})
+	}
+	return sinks, nil
+}
+
+// newSecretSink returns the sink given as <kind>:<target>
+func newSecretSink(cmd *cobra.Command, session client.ConfigProvider, target string) (cloudobject.SecretSink,
+	error) {
+	kind, dest := target, ""
+	if i := strings.Index(target, ":"); i >= 0 {
+		kind, dest = target[:i], target[i+1:]
+	}
+
+	var format cloudobject.SecretsFormat
+	switch kind {
+	case FileSecretSink:
+		format = cloudobject.JSONSecretsFormat
+	case DotenvSecretSink:
+		format = cloudobject.DotenvSecretsFormat
+	case KubernetesSecretSink:
+		format = cloudobject.KubernetesSecretsFormat
+	case SecretsManagerSecretSink:
+		return sink.NewSecretsManager(dest, session)
+	case SSMSecretSink:
+		s, err := sink.NewParameterStore(dest, session)
+		if err != nil {
+			return nil, UsageError{Message: fmt.Sprintf("invalid --%s '%s': %s", SecretsToFlag, target, err)}
+		}
+		return s, nil
+	default:
+		return nil, UsageError{Message: fmt.Sprintf("unknown --%s '%s' (known kinds: %s)", SecretsToFlag, target,
+			strings.Join(secretSinkKinds, ", "))}
+	}
+
+	switch dest {
+	case "":
+		return nil, UsageError{Message: fmt.Sprintf("--%s '%s' lacks a path ('-' for stdout)", SecretsToFlag,
+			target)}
+	case "-":
+		return cloudobject.WriterSink{Writer: cmd.OutOrStdout(), Format: format}, nil
+	default:
+		return cloudobject.FileSink{Path: dest, Format: format}, nil
+	}
+}
+
+// writeCredentials writes the secrets of the object to all sinks. AWS hands out generated credentials only once, so
+// a failing sink doesn't keep the others from getting them.
+func writeCredentials(cmd *cobra.Command, sinks []secretSink, id cloudobject.ID, secrets cloudobject.Secrets) error {
+	if secrets == nil {
+		return nil
+	}
+	ctx, cancel := GetContext(cmd)
+	defer cancel()
+
+	var failed []string
+	for _, s := range sinks {
+		if err := s.WriteSecrets(ctx, id, secrets); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", s.target, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("writing secrets of '%s' failed: %s", id, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// passwordSinks returns the sinks the secrets of a database can be written to. Secrets without a password (e.g. of
+// an update that didn't set one) would replace the password written to files and secret stores before, which can't
+// be asked for again, so they only go to stdout.
+func passwordSinks(cmd *cobra.Command, sinks []secretSink, id cloudobject.ID,
+	secrets cloudobject.Secrets) []secretSink {
+	if secrets == nil || secrets.Map()["password"] != "" {
+		return sinks
+	}
+	var kept []secretSink
+	for _, s := range sinks {
+		if _, ok := s.SecretSink.(cloudobject.WriterSink); ok {
+			kept = append(kept, s)
+			continue
+		}
+		cmd.PrintErrln(fmt.Sprintf("not writing secrets of '%s' to %s, as they lack the password", id, s.target))
+	}
+	return kept
+}
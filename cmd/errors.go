@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+// ErrorClass is the kind of failure a command ended with. Every class has its own exit code.
+type ErrorClass string
+
+const (
+	GenericErrorClass       ErrorClass = "error"
+	UsageErrorClass         ErrorClass = "usage"
+	NotFoundErrorClass      ErrorClass = "not-found"
+	NotReadyErrorClass      ErrorClass = "not-ready"
+	SpecInvalidErrorClass   ErrorClass = "spec-invalid"
+	AlreadyExistsErrorClass ErrorClass = "already-exists"
+	ThrottledErrorClass     ErrorClass = "throttled"
+	AuthErrorClass          ErrorClass = "auth"
+	TimeoutErrorClass       ErrorClass = "timeout"
+)
+
+// exitCodes maps the error classes to the exit codes of the CLI. Successful commands exit with 0.
+var exitCodes = map[ErrorClass]int{
+	GenericErrorClass:       1,
+	UsageErrorClass:         2,
+	NotFoundErrorClass:      3,
+	NotReadyErrorClass:      4,
+	SpecInvalidErrorClass:   5,
+	AlreadyExistsErrorClass: 6,
+	ThrottledErrorClass:     7,
+	AuthErrorClass:          8,
+	TimeoutErrorClass:       9,
+}
+
+func (class ErrorClass) ExitCode() int {
+	if code, ok := exitCodes[class]; ok {
+		return code
+	}
+	return exitCodes[GenericErrorClass]
+}
+
+// AWS error codes that map onto our error classes, on top of the cloudobject error types. Throttling is detected by
+// the SDK itself.
+var (
+	notFoundAWSCodes = codeSet("NoSuchEntity", "NoSuchBucket", "NotFoundException", "DBInstanceNotFound",
+		"DBSnapshotNotFound", "DBSubnetGroupNotFoundFault", "DBClusterNotFoundFault")
+	notReadyAWSCodes = codeSet("InvalidDBInstanceState", "InvalidDBSnapshotState", "InvalidDBSubnetGroupStateFault",
+		"KMSInvalidStateException", "InvalidBucketState", "DeleteConflict", "InvalidDBClusterStateFault")
+	specInvalidAWSCodes = codeSet("ValidationError", "InvalidParameterValue", "InvalidParameterCombination",
+		"MalformedPolicyDocument", "InvalidAliasName", "InvalidBucketName", "IllegalLocationConstraintException")
+	alreadyExistsAWSCodes = codeSet("EntityAlreadyExists", "AlreadyExistsException", "BucketAlreadyExists",
+		"BucketAlreadyOwnedByYou", "DBInstanceAlreadyExists", "DBSnapshotAlreadyExists", "DBSubnetGroupAlreadyExists",
+		"DBClusterAlreadyExistsFault")
+	authAWSCodes = codeSet("AccessDenied", "AccessDeniedException", "UnauthorizedOperation", "AuthFailure",
+		"InvalidClientTokenId", "UnrecognizedClientException", "SignatureDoesNotMatch", "InvalidAccessKeyId",
+		"ExpiredToken", "ExpiredTokenException", "NoCredentialProviders")
+)
+
+func codeSet(codes ...string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[code] = true
+	}
+	return set
+}
+
+// ClassifyError returns the class of the given error. Errors are looked at through any wrapping, as the
+// cloudobject error types or the AWS errors they stem from.
+func ClassifyError(err error) ErrorClass {
+	var (
+		usage         UsageError
+		unknownAction CloudObjectActionUnknown
+		aerr          awserr.Error
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &usage), errors.As(err, &unknownAction):
+		return UsageErrorClass
+	case errors.Is(err, cloudobject.ErrNotExists):
+		return NotFoundErrorClass
+	case errors.Is(err, cloudobject.ErrNotReady):
+		return NotReadyErrorClass
+	case errors.Is(err, cloudobject.ErrSpecInvalid), errors.Is(err, cloudobject.ErrOptsInvalid),
+		errors.Is(err, cloudobject.ErrAmbiguousIdentifier), errors.Is(err, cloudobject.ErrDependencyCycle):
+		return SpecInvalidErrorClass
+	case errors.Is(err, cloudobject.ErrAlreadyExists), errors.Is(err, cloudobject.ErrIdCollision):
+		return AlreadyExistsErrorClass
+	case errors.Is(err, cloudobject.ErrWaitTimeout), errors.Is(err, context.DeadlineExceeded):
+		return TimeoutErrorClass
+	case errors.As(err, &aerr):
+		return classifyAWSError(aerr)
+	default:
+		return GenericErrorClass
+	}
+}
+
+func classifyAWSError(aerr awserr.Error) ErrorClass {
+	code := aerr.Code()
+	switch {
+	case request.IsErrorThrottle(aerr):
+		return ThrottledErrorClass
+	case authAWSCodes[code]:
+		return AuthErrorClass
+	case notFoundAWSCodes[code]:
+		return NotFoundErrorClass
+	case notReadyAWSCodes[code]:
+		return NotReadyErrorClass
+	case specInvalidAWSCodes[code]:
+		return SpecInvalidErrorClass
+	case alreadyExistsAWSCodes[code]:
+		return AlreadyExistsErrorClass
+	}
+	// Requests that never made it to AWS (e.g. canceled ones) wrap what stopped them
+	if orig := aerr.OrigErr(); orig != nil && code == request.CanceledErrorCode {
+		if errors.Is(orig, context.DeadlineExceeded) {
+			return TimeoutErrorClass
+		}
+	}
+	return GenericErrorClass
+}
+
+// ErrorEnvelope is what commands print instead of the plain error message, when JSON output was asked for
+type ErrorEnvelope struct {
+	Error ErrorDetails `json:"error"`
+}
+
+// ErrorDetails describe the error a command failed with
+type ErrorDetails struct {
+	Class    ErrorClass `json:"class"`
+	ExitCode int        `json:"exitCode"`
+	Message  string     `json:"message"`
+
+	// AWSCode, RequestID and StatusCode are set for errors returned by AWS
+	AWSCode    string `json:"awsCode,omitempty"`
+	RequestID  string `json:"requestID,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+}
+
+// NewErrorEnvelope classifies the given error and wraps it in an ErrorEnvelope
+func NewErrorEnvelope(err error) ErrorEnvelope {
+	class := ClassifyError(err)
+	details := ErrorDetails{Class: class, ExitCode: class.ExitCode(), Message: err.Error()}
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		details.AWSCode = aerr.Code()
+	}
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		details.RequestID = reqErr.RequestID()
+		details.StatusCode = reqErr.StatusCode()
+	}
+	return ErrorEnvelope{Error: details}
+}
+
+// printError prints the error a command failed with: as an ErrorEnvelope for JSON and YAML output, as plain message
+// otherwise. Usage errors come with a hint on where to find help.
+func printError(w io.Writer, format string, commandPath string, err error) {
+	switch format {
+	case JSONOutput, YAMLOutput:
+		if printStructured(w, format, NewErrorEnvelope(err)) == nil {
+			return
+		}
+	}
+	fmt.Fprintln(w, "Error:", err.Error())
+	if ClassifyError(err) == UsageErrorClass {
+		fmt.Fprintf(w, "Run '%s --help' for usage.\n", commandPath)
+	}
+}
+
+// UsageError is returned when a command was called the wrong way, e.g. with conflicting flags
+type UsageError struct {
+	Message string
+}
+
+func (e UsageError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		class    ErrorClass
+		exitCode int
+	}{
+		{"generic", errors.New("boom"), GenericErrorClass, 1},
+		{"usage", UsageError{Message: "conflicting flags"}, UsageErrorClass, 2},
+		{"unknown action", CloudObjectActionUnknown{Message: "unknown action"}, UsageErrorClass, 2},
+		{"not exists", cloudobject.NotExistsError{}, NotFoundErrorClass, 3},
+		{"wrapped not exists", fmt.Errorf("reading: %w", cloudobject.NotExistsError{}), NotFoundErrorClass, 3},
+		{"not ready", cloudobject.NotReadyError{}, NotReadyErrorClass, 4},
+		{"spec invalid", cloudobject.SpecInvalidError{}, SpecInvalidErrorClass, 5},
+		{"opts invalid", cloudobject.OptsInvalidError{}, SpecInvalidErrorClass, 5},
+		{"ambiguous identifier", cloudobject.AmbiguousIdentifierError{}, SpecInvalidErrorClass, 5},
+		{"dependency cycle", cloudobject.DependencyCycleError{}, SpecInvalidErrorClass, 5},
+		{"already exists", cloudobject.AlreadyExistsError{}, AlreadyExistsErrorClass, 6},
+		{"id collision", cloudobject.IdCollisionError{}, AlreadyExistsErrorClass, 6},
+		{"wait timeout", cloudobject.WaitTimeoutError{}, TimeoutErrorClass, 9},
+		{"deadline", context.DeadlineExceeded, TimeoutErrorClass, 9},
+		{"aws throttled", awserr.New("Throttling", "rate exceeded", nil), ThrottledErrorClass, 7},
+		{"aws auth", awserr.New("AccessDenied", "denied", nil), AuthErrorClass, 8},
+		{"aws not found", awserr.New("DBInstanceNotFound", "not found", nil), NotFoundErrorClass, 3},
+		{"aws not ready", awserr.New("InvalidDBInstanceState", "modifying", nil), NotReadyErrorClass, 4},
+		{"aws spec invalid", awserr.New("InvalidParameterValue", "invalid", nil), SpecInvalidErrorClass, 5},
+		{"aws already exists", awserr.New("BucketAlreadyExists", "taken", nil), AlreadyExistsErrorClass, 6},
+		{"aws cluster not ready", awserr.New("InvalidDBClusterStateFault", "deleting", nil), NotReadyErrorClass, 4},
+		{"aws cluster already exists", awserr.New("DBClusterAlreadyExistsFault", "taken", nil),
+			AlreadyExistsErrorClass, 6},
+		{"aws unknown", awserr.New("SomethingElse", "boom", nil), GenericErrorClass, 1},
+		{"aws canceled by deadline", awserr.New(request.CanceledErrorCode, "canceled", context.DeadlineExceeded),
+			TimeoutErrorClass, 9},
+		{"multiple", cloudobject.MultiError{Errs: []error{
+			fmt.Errorf("'a': %w", cloudobject.NotExistsError{}), fmt.Errorf("'b': %w", cloudobject.NotExistsError{}),
+		}}, NotFoundErrorClass, 3},
+		{"wrapped aws", fmt.Errorf("creating: %w", awserr.New("AccessDenied", "denied", nil)), AuthErrorClass, 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			class := ClassifyError(test.err)
+			assert.Equal(t, test.class, class)
+			assert.Equal(t, test.exitCode, class.ExitCode())
+		})
+	}
+
+	assert.Equal(t, ErrorClass(""), ClassifyError(nil))
+	assert.Equal(t, 1, ErrorClass("unknown").ExitCode())
+}
+
+func TestNewErrorEnvelope(t *testing.T) {
+	err := fmt.Errorf("creating: %w", awserr.NewRequestFailure(
+		awserr.New("AccessDenied", "denied", nil), 403, "req-1"))
+	assert.Equal(t, ErrorEnvelope{Error: ErrorDetails{Class: AuthErrorClass, ExitCode: 8, Message: err.Error(),
+		AWSCode: "AccessDenied", RequestID: "req-1", StatusCode: 403}}, NewErrorEnvelope(err))
+
+	// Errors that don't stem from AWS leave out the AWS fields
+	out, jsonErr := json.Marshal(NewErrorEnvelope(cloudobject.NotReadyError{ErrorInfo: cloudobject.ErrorInfo{
+		Message: "still modifying",
+	}}))
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"error": {"class": "not-ready", "exitCode": 4, "message": "still modifying"}}`, string(out))
+}
+
+func TestPrintError(t *testing.T) {
+	var buf bytes.Buffer
+	printError(&buf, JSONOutput, "cloud-objects aws", UsageError{Message: "conflicting flags"})
+	var envelope ErrorEnvelope
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &envelope))
+	assert.Equal(t, ErrorDetails{Class: UsageErrorClass, ExitCode: 2, Message: "conflicting flags"}, envelope.Error)
+
+	buf.Reset()
+	printError(&buf, TableOutput, "cloud-objects aws", UsageError{Message: "conflicting flags"})
+	assert.Equal(t, "Error: conflicting flags\nRun 'cloud-objects aws --help' for usage.\n", buf.String())
+
+	buf.Reset()
+	printError(&buf, TableOutput, "cloud-objects aws", errors.New("boom"))
+	assert.Equal(t, "Error: boom\n", buf.String())
+}
+
+func TestStartCommand_RequiredFlags(t *testing.T) {
+	defer func() { startedCmd = nil }()
+	root := &cobra.Command{Use: "root", PersistentPreRunE: startCommand, SilenceErrors: true, SilenceUsage: true}
+	apply := &cobra.Command{Use: "apply", RunE: func(*cobra.Command, []string) error { return nil }}
+	apply.Flags().StringP(FilenameFlag, "f", "", "")
+	require.NoError(t, apply.MarkFlagRequired(FilenameFlag))
+	root.AddCommand(apply)
+
+	// A missing required flag is a usage error, not a failed run
+	root.SetArgs([]string{"apply"})
+	err := root.Execute()
+	assert.Equal(t, UsageErrorClass, ClassifyError(err))
+	assert.EqualError(t, err, `required flag(s) "filename" not set`)
+	assert.Nil(t, startedCmd)
+
+	root.SetArgs([]string{"apply", "-f", "objects.yaml"})
+	require.NoError(t, root.Execute())
+	assert.Equal(t, apply, startedCmd)
+}
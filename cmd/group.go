@@ -0,0 +1,68 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/redradrat/cloud-objects/aws/iam"
+
+	"github.com/spf13/cobra"
+)
+
+var groupName string
+
+// groupCmd represents the group command
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Args:  OnlyCloudObjectAction(),
+	Short: "Interact with the IAM group cloud object",
+	Long: `Interact with the IAM group cloud object. Members are managed with the user command. For example:
+
+	*) cloud-objects aws iam group create --name testgroup
+
+	*) cloud-objects aws iam group delete --name testgroup`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		group, err := iam.NewGroup(groupName, session)
+		if err != nil {
+			return err
+		}
+
+		purge, err := getPurge(cmd, CloudObjectAction(args[0]), group.ID())
+		if err != nil {
+			return err
+		}
+		_, err = runCloudObject(cmd, iam.GroupKind, group, &iam.GroupSpec{}, CloudObjectAction(args[0]), purge,
+			iam.Available)
+		return err
+	},
+}
+
+func init() {
+	iamCmd.AddCommand(groupCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// groupCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	groupCmd.Flags().StringVarP(&groupName, "name", "n", "", "The name of the group")
+}
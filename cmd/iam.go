@@ -16,6 +16,11 @@ limitations under the License.
 package cmd
 
 import (
+	"io"
+	"os"
+
+	"github.com/redradrat/cloud-objects/aws/iam"
+
 	"github.com/spf13/cobra"
 )
 
@@ -43,3 +48,18 @@ func init() {
 	// is called directly, e.g.:
 	// iamCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+// readPolicyDocument reads a JSON policy document from the given file, or from stdin if the path is "-"
+func readPolicyDocument(cmd *cobra.Command, path string) (iam.PolicyDocument, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return iam.PolicyDocument{}, err
+	}
+	return iam.ParsePolicyDocument(data)
+}
@@ -18,8 +18,6 @@ package cmd
 import (
 	"github.com/redradrat/cloud-objects/aws/rds"
 
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +26,7 @@ var instanceClass string
 var username string
 var password string
 var securityGroupIDs []string
+var rotatePassword bool
 
 // instanceCmd represents the instance command
 var instanceCmd = &cobra.Command{
@@ -38,29 +37,81 @@ var instanceCmd = &cobra.Command{
 
 	*) cloud-objects aws rds instance create --name testinstance
 
-	*) cloud-objects aws rds instance delete --name testinstance`,
-	Run: func(cmd *cobra.Command, args []string) {
+	*) cloud-objects aws rds instance delete --name testinstance
+
+On create and update, the connection details of the instance (host, port, user, password and a ready-to-use DSN)
+are printed as JSON to stdout unless --credentialsFile or --secrets-to is given. Host, port and DSN are only known
+once the instance is up, so use --wait to get them on create.
+
+Without --password, a master password is generated on create. It is only handed out then, but it can be replaced
+with a new one on update with --rotatePassword. As only available instances can be rotated, that needs --wait
+if the update changes the instance as well.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		session, err := GetSession(cmd)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
+		}
+		sinks, err := getSecretSinks(cmd, session)
+		if err != nil {
+			return err
 		}
 		ins, err := rds.NewInstance(instanceName, session)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 		spec := rds.SanePostgres(instanceName, subnetGroupName, instanceClass, username, password, nil, securityGroupIDs)
+		action := CloudObjectAction(args[0])
+		if err := checkSpec(action, &spec); err != nil {
+			return err
+		}
+		if rotatePassword && action != UpdateCloudObjectAction {
+			return UsageError{Message: "--rotatePassword only works with the update action"}
+		}
+		if rotatePassword {
+			if err := checkRotatable(cmd, ins, &spec); err != nil {
+				return err
+			}
+		}
 
-		_, err = HandleCloudObject(ins, &spec, CloudObjectAction(args[0]), false)
+		purge, err := getPurge(cmd, action, ins.ID())
+		if err != nil {
+			return err
+		}
+		secrets, err := runCloudObject(cmd, rds.InstanceKind, ins, &spec, action, purge, rds.InstanceAvailable)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
-		fmt.Println(ins.Status())
+		if rotatePassword {
+			ctx, cancel := GetContext(cmd)
+			defer cancel()
+			if secrets, err = ins.RotatePasswordWithContext(ctx); err != nil {
+				return err
+			}
+			recordCloudObject(cmd, rds.InstanceKind, ins, &spec, secrets, action)
+		}
+		return writeCredentials(cmd, passwordSinks(cmd, sinks, ins.ID(), secrets), ins.ID(), secrets)
 	},
 }
 
+// checkRotatable makes sure the password can be rotated after the update. An update that changes the instance leaves
+// it modifying for a while, so rotating right away would fail unless we wait for it.
+func checkRotatable(cmd *cobra.Command, ins *rds.Instance, spec *rds.InstanceSpec) error {
+	waiter, err := getWaiter(cmd)
+	if err != nil || waiter != nil {
+		return err
+	}
+	ctx, cancel := GetContext(cmd)
+	defer cancel()
+	diff, err := ins.PlanWithContext(ctx, spec)
+	if err != nil {
+		return err
+	}
+	if diff.HasChanges() || spec.MasterUserPassword != "" {
+		return UsageError{Message: "--rotatePassword needs --wait when the update changes the instance"}
+	}
+	return nil
+}
+
 func init() {
 	rdsCmd.AddCommand(instanceCmd)
 
@@ -76,8 +127,12 @@ func init() {
 	instanceCmd.Flags().StringVar(&subnetGroupName, "subnetGroup", "", "The subnetGroup to use")
 	instanceCmd.Flags().StringVar(&instanceClass, "instanceClass", "", "The instance class to use")
 	instanceCmd.Flags().StringVar(&username, "username", "", "The master user name")
-	instanceCmd.Flags().StringVar(&password, "password", "", "The master user password")
+	instanceCmd.Flags().StringVar(&password, "password", "",
+		"The master user password; one is generated on create if not given")
+	instanceCmd.Flags().BoolVar(&rotatePassword, "rotatePassword", false,
+		"Replace the master user password with a generated one on update")
 	instanceCmd.Flags().StringSliceVar(&securityGroupIDs, "securityGroups", []string{},
 		"The securityGroupIDs to attach to")
+	addCredentialsFlags(instanceCmd.Flags())
 
 }
@@ -18,8 +18,6 @@ package cmd
 import (
 	"github.com/redradrat/cloud-objects/aws/kms"
 
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -37,16 +35,14 @@ var keyCmd = &cobra.Command{
 	*) cloud-objects aws kms key create --name testkey
 
 	*) cloud-objects aws kms key delete --name testkey`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		session, err := GetSession(cmd)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 		key, err := kms.NewKey(keyName, session)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 
 		spec := kms.KeySpec{
@@ -54,17 +50,13 @@ var keyCmd = &cobra.Command{
 			KeyType:  keyType,
 		}
 
-		prg, err := cmd.Flags().GetBool(PurgeFlag)
-		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
-		}
-		_, err = HandleCloudObject(key, &spec, CloudObjectAction(args[0]), prg)
+		purge, err := getPurge(cmd, CloudObjectAction(args[0]), key.ID())
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
-		fmt.Println(key.Status())
+		_, err = runCloudObject(cmd, kms.KeyKind, key, &spec, CloudObjectAction(args[0]), purge,
+			kms.KeyEnabled)
+		return err
 	},
 }
 
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const (
+	DebugLogLevel = "debug"
+	InfoLogLevel  = "info"
+	WarnLogLevel  = "warn"
+	ErrorLogLevel = "error"
+)
+
+var logLevels = []string{DebugLogLevel, InfoLogLevel, WarnLogLevel, ErrorLogLevel}
+
+// getLogger returns the logger of the command, logging to stderr from the level given by --log-level on
+func getLogger(cmd *cobra.Command) (cloudobject.Logger, error) {
+	level, err := cmd.Flags().GetString(LogLevelFlag)
+	if err != nil {
+		return nil, err
+	}
+	for i, known := range logLevels {
+		if level == known {
+			return &textLogger{w: cmd.ErrOrStderr(), level: i}, nil
+		}
+	}
+	return nil, UsageError{Message: fmt.Sprintf("unknown log level '%s' (known levels: %s)", level,
+		strings.Join(logLevels, ", "))}
+}
+
+// setLogger makes the object (e.g. a CloudObject or a SecretSink) log to the given logger, if it logs at all
+func setLogger(obj interface{}, logger cloudobject.Logger) {
+	if loggable, ok := obj.(cloudobject.Loggable); ok {
+		loggable.SetLogger(logger)
+	}
+}
+
+// textLogger writes "LEVEL message key=value ..." lines for everything logged at its level or above
+type textLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level int
+}
+
+func (l *textLogger) DebugContext(_ context.Context, msg string, args ...interface{}) {
+	l.log(0, msg, args)
+}
+
+func (l *textLogger) InfoContext(_ context.Context, msg string, args ...interface{}) {
+	l.log(1, msg, args)
+}
+
+func (l *textLogger) WarnContext(_ context.Context, msg string, args ...interface{}) {
+	l.log(2, msg, args)
+}
+
+func (l *textLogger) ErrorContext(_ context.Context, msg string, args ...interface{}) {
+	l.log(3, msg, args)
+}
+
+func (l *textLogger) log(level int, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(logLevels[level]))
+	b.WriteString(" ")
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		val := fmt.Sprint(args[i+1])
+		if strings.ContainsAny(val, " \t\n\"=") {
+			val = fmt.Sprintf("%q", val)
+		}
+		fmt.Fprintf(&b, " %v=%s", args[i], val)
+	}
+	b.WriteString("\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, b.String())
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/spf13/cobra"
+
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/aws/iam"
+	"github.com/redradrat/cloud-objects/aws/kms"
+	"github.com/redradrat/cloud-objects/aws/rds"
+	"github.com/redradrat/cloud-objects/aws/s3"
+	"github.com/redradrat/cloud-objects/cloudobject"
+	"github.com/redradrat/cloud-objects/manifest"
+)
+
+const (
+	FilenameFlag    = "filename"
+	ParallelismFlag = "parallelism"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Args:  cobra.NoArgs,
+	Short: "Create or update all cloud objects of a manifest",
+	Long: `Create or update all cloud objects of a YAML or JSON manifest, in dependency order. Objects
+that don't exist yet are created, existing ones are updated. Independent objects are handled in
+parallel. For example:
+
+	*) cloud-objects apply -f stack.yaml
+
+A manifest lists typed objects with their spec:
+
+	objects:
+	  - kind: subnetgroup
+	    name: mydb
+	    spec:
+	      description: My DB subnets
+	      subnetIDs: [subnet-1234, subnet-5678]
+	  - kind: instance
+	    name: mydb
+	    spec:
+	      dbSubnetGroupName: clobjx-sg-mydb
+	      ...
+	  - kind: bucket
+	    name: assets
+	    dependsOn: [instance/mydb]
+	    spec:
+	      versioning: false`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := loadManifest(cmd)
+		if err != nil {
+			return err
+		}
+		return walkManifest(cmd, entries, false, applyManifestEntry)
+	},
+}
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Args:  cobra.NoArgs,
+	Short: "Delete all cloud objects of a manifest",
+	Long: `Delete all cloud objects of a YAML or JSON manifest, in reverse dependency order. For example:
+
+	*) cloud-objects delete -f stack.yaml --purge
+
+Purging has to be confirmed by typing the manifest's file name, unless --yes is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := loadManifest(cmd)
+		if err != nil {
+			return err
+		}
+		purge, err := getManifestPurge(cmd, entries)
+		if err != nil {
+			return err
+		}
+		return walkManifest(cmd, entries, true, deleteManifestEntry(purge))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(applyCmd)
+	RootCmd.AddCommand(deleteCmd)
+
+	for _, c := range []*cobra.Command{applyCmd, deleteCmd} {
+		addAWSFlags(c.Flags())
+		c.Flags().StringP(FilenameFlag, "f", "", "The manifest to use, '-' reads from stdin")
+		_ = c.MarkFlagRequired(FilenameFlag)
+		c.Flags().Int(ParallelismFlag, 4, "How many independent cloud objects to handle at the same time")
+	}
+}
+
+// manifestEntryFunc handles a single manifest entry and returns the action it took
+type manifestEntryFunc func(ctx context.Context, cmd *cobra.Command, waiter *cloudobject.Waiter,
+	entry manifest.Entry) (CloudObjectAction, error)
+
+// walkManifest calls fn for all entries of the manifest, in dependency order, or reverse dependency order if
+// reverse is set. Independent objects are handled in parallel. Progress goes to stderr, the summaries of all
+// handled objects to stdout, in the format given by --output and in manifest order.
+func walkManifest(cmd *cobra.Command, entries []manifest.Entry, reverse bool, fn manifestEntryFunc) error {
+	format, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	logger, err := getLogger(cmd)
+	if err != nil {
+		return err
+	}
+	graph, err := manifest.Graph(entries)
+	if err != nil {
+		return err
+	}
+	byID := make(map[cloudobject.ID]manifest.Entry, len(entries))
+	for _, entry := range entries {
+		setLogger(entry.CloudObject, logger)
+		byID[entry.CloudObject.ID()] = entry
+	}
+
+	parallelism, err := cmd.Flags().GetInt(ParallelismFlag)
+	if err != nil {
+		return err
+	}
+	waiter, err := getWaiter(cmd)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := GetContext(cmd)
+	defer cancel()
+
+	var mu sync.Mutex
+	summaries := make(map[cloudobject.ID]cloudobject.Summary, len(entries))
+	walkErr := graph.Walk(ctx, parallelism, reverse, func(ctx context.Context, id cloudobject.ID,
+		_ cloudobject.CloudObject, _ cloudobject.CloudObjectSpec) error {
+		entry := byID[id]
+		action, err := fn(ctx, cmd, waiter, entry)
+		if err != nil {
+			return err
+		}
+		cmd.PrintErrln(fmt.Sprintf("%s %sd", entry.String(), action))
+		summary := summarizeCloudObject(ctx, entry.Kind, entry.CloudObject, action)
+		mu.Lock()
+		summaries[id] = summary
+		mu.Unlock()
+		return nil
+	})
+
+	// Whatever got done is reported, even if not everything did
+	handled := make([]cloudobject.Summary, 0, len(summaries))
+	for _, entry := range entries {
+		if summary, ok := summaries[entry.CloudObject.ID()]; ok {
+			handled = append(handled, summary)
+		}
+	}
+	if err := printSummaries(cmd.OutOrStdout(), format, handled); err != nil {
+		return err
+	}
+	return walkErr
+}
+
+// applyManifestEntry creates the object of the entry, or updates it if it exists already
+func applyManifestEntry(ctx context.Context, cmd *cobra.Command, waiter *cloudobject.Waiter,
+	entry manifest.Entry) (CloudObjectAction, error) {
+	exists, err := entry.CloudObject.ExistsWithContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	action := CreateCloudObjectAction
+	if exists {
+		action = UpdateCloudObjectAction
+	}
+	_, err = settleCloudObject(ctx, cmd, waiter, entry.Kind, entry.CloudObject, entry.CloudSpec, action, false,
+		entry.Ready)
+	return action, err
+}
+
+// deleteManifestEntry returns a manifestEntryFunc deleting the object of the entry, purging it if purge is set
+func deleteManifestEntry(purge bool) manifestEntryFunc {
+	return func(ctx context.Context, cmd *cobra.Command, waiter *cloudobject.Waiter,
+		entry manifest.Entry) (CloudObjectAction, error) {
+		_, err := settleCloudObject(ctx, cmd, waiter, entry.Kind, entry.CloudObject, entry.CloudSpec,
+			DeleteCloudObjectAction, purge, entry.Ready)
+		return DeleteCloudObjectAction, err
+	}
+}
+
+// getManifestPurge returns whether --purge is set for deleting the manifest. Unless --yes is set too, the purge
+// has to be confirmed by typing the manifest's file name. Manifests read from stdin leave no way to confirm, so
+// they need --yes.
+func getManifestPurge(cmd *cobra.Command, entries []manifest.Entry) (bool, error) {
+	purge, err := purgeRequested(cmd, DeleteCloudObjectAction)
+	if err != nil || !purge {
+		return false, err
+	}
+	filename, err := cmd.Flags().GetString(FilenameFlag)
+	if err != nil {
+		return false, err
+	}
+	ids := make([]cloudobject.ID, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.CloudObject.ID())
+	}
+	if filename == "-" {
+		if yes, err := cmd.Flags().GetBool(YesFlag); err != nil || !yes {
+			return false, UsageError{Message: "purging a manifest read from stdin needs --yes"}
+		}
+	}
+	return true, confirmPurge(cmd, filepath.Base(filename), ids)
+}
+
+// loadManifest parses the manifest given by --filename and resolves all its objects
+func loadManifest(cmd *cobra.Command) ([]manifest.Entry, error) {
+	filename, err := cmd.Flags().GetString(FilenameFlag)
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.ParseFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defaultAccount, err := cmd.Flags().GetString(AccountFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects without an account of their own go to the one given by --account
+	registries := make(map[string]*manifest.Registry)
+	return manifest.BuildAccounts(m, func(account string) (*manifest.Registry, error) {
+		if account == "" {
+			account = defaultAccount
+		}
+		if r, ok := registries[account]; ok {
+			return r, nil
+		}
+		session, err := GetAccountSession(cmd, account)
+		if err != nil {
+			return nil, err
+		}
+		registries[account] = AWSRegistry(session)
+		return registries[account], nil
+	})
+}
+
+// AWSRegistry returns a manifest registry knowing all AWS cloud object kinds. Buckets and keys start out with the
+// same defaults the single object commands use; the manifest spec only needs to override what differs. All objects
+// share the same service clients, but read replicas of instances in other regions.
+func AWSRegistry(session client.ConfigProvider) *manifest.Registry {
+	clients := aws.NewClients(session)
+	r := manifest.NewRegistry()
+	r.Register(s3.BucketKind, func() cloudobject.CloudObjectSpec {
+		spec := s3.SaneS3Bucket()
+		return &spec
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return s3.NewBucketWithClients(name, clients.S3, clients.KMS)
+	}, s3.BucketAvailable)
+	r.Register(rds.InstanceKind, func() cloudobject.CloudObjectSpec {
+		return &rds.InstanceSpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return rds.NewInstanceWithClients(name, clients.RDS, clients.KMS)
+	}, rds.InstanceAvailable)
+	r.Register(rds.ClusterKind, func() cloudobject.CloudObjectSpec {
+		return &rds.ClusterSpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return rds.NewClusterWithClients(name, clients.RDS, clients.KMS)
+	}, rds.ClusterAvailable)
+	r.Register(rds.ReadReplicaKind, func() cloudobject.CloudObjectSpec {
+		return &rds.ReadReplicaSpec{}
+	}, func(name string, spec cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		// Replicas of instances in other regions need a client of their own for the source
+		if replicaSpec, ok := spec.(*rds.ReadReplicaSpec); ok && replicaSpec.SourceRegion != "" {
+			return rds.NewReadReplica(name, session)
+		}
+		return rds.NewReadReplicaWithClients(name, clients.RDS, clients.RDS, clients.KMS)
+	}, rds.InstanceAvailable)
+	r.Register(rds.SubnetGroupKind, func() cloudobject.CloudObjectSpec {
+		return &rds.SubnetGroupSpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return rds.NewSubnetGroupWithClient(name, clients.RDS)
+	}, rds.SubnetGroupComplete)
+	r.Register(kms.KeyKind, func() cloudobject.CloudObjectSpec {
+		spec := kms.EncryptSymmetric()
+		return &spec
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return kms.NewKeyWithClient(name, clients.KMS)
+	}, kms.KeyEnabled)
+	r.Register(iam.UserKind, func() cloudobject.CloudObjectSpec {
+		return &iam.UserSpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return iam.NewUser(name, session)
+	}, iam.Available)
+	r.Register(iam.RoleKind, func() cloudobject.CloudObjectSpec {
+		return &iam.RoleSpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return iam.NewRole(name, session)
+	}, iam.Available)
+	r.Register(iam.GroupKind, func() cloudobject.CloudObjectSpec {
+		return &iam.GroupSpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return iam.NewGroup(name, session)
+	}, iam.Available)
+	r.Register(iam.PolicyKind, func() cloudobject.CloudObjectSpec {
+		return &iam.PolicySpec{}
+	}, func(name string, _ cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return iam.NewPolicy(name, session)
+	}, iam.Available)
+	// Policy attachments have no name of their own at AWS; the manifest name only serves dependsOn
+	r.Register(iam.PolicyAttachmentKind, func() cloudobject.CloudObjectSpec {
+		return &iam.PolicyAttachmentSpec{}
+	}, func(_ string, spec cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		attSpec := spec.(*iam.PolicyAttachmentSpec)
+		return iam.NewPolicyAttachment(attSpec.Policy, attSpec.Type, attSpec.Target, session)
+	}, iam.Available)
+	return r
+}
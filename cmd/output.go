@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const (
+	OutputFlag = "output"
+
+	// TableOutput prints a table of kind, ID, state and provider ID, one object per row
+	TableOutput = "table"
+	// JSONOutput prints the cloudobject.Summary of the object, or a list of them for manifests
+	JSONOutput = "json"
+	// YAMLOutput is the same as JSONOutput, in YAML
+	YAMLOutput = "yaml"
+	// NameOutput prints the ID of every object on a line of its own
+	NameOutput = "name"
+
+	// DeletedState is the state we report for objects that are gone after deleting them
+	DeletedState = "deleted"
+)
+
+var outputFormats = []string{TableOutput, JSONOutput, YAMLOutput, NameOutput}
+
+// getOutputFormat returns the output format given by --output
+func getOutputFormat(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Flags().GetString(OutputFlag)
+	if err != nil {
+		return "", err
+	}
+	for _, known := range outputFormats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", UsageError{Message: fmt.Sprintf("unknown output format '%s' (known formats: %s)", format,
+		strings.Join(outputFormats, ", "))}
+}
+
+// printSummary prints the summary of a single object in the given format
+func printSummary(w io.Writer, format string, summary cloudobject.Summary) error {
+	switch format {
+	case JSONOutput, YAMLOutput:
+		return printStructured(w, format, summary)
+	default:
+		return printSummaries(w, format, []cloudobject.Summary{summary})
+	}
+}
+
+// printSummaries prints the summaries of several objects in the given format
+func printSummaries(w io.Writer, format string, summaries []cloudobject.Summary) error {
+	switch format {
+	case JSONOutput, YAMLOutput:
+		return printStructured(w, format, summaries)
+	case NameOutput:
+		for _, summary := range summaries {
+			if _, err := fmt.Fprintln(w, summary.ID.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tID\tSTATE\tPROVIDER ID")
+		for _, s := range summaries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Kind.String(), s.ID.String(), orDash(s.State), orDash(s.ProviderID))
+		}
+		return tw.Flush()
+	}
+}
+
+// printDiff prints the result of a plan in the given format
+func printDiff(w io.Writer, format string, diff cloudobject.Diff) error {
+	switch format {
+	case JSONOutput, YAMLOutput:
+		return printStructured(w, format, diff)
+	case NameOutput:
+		_, err := fmt.Fprintln(w, diff.ID.String())
+		return err
+	default:
+		_, err := fmt.Fprintln(w, diff.String())
+		return err
+	}
+}
+
+// printStructured prints the given value as JSON or YAML
+func printStructured(w io.Writer, format string, v interface{}) error {
+	if format == YAMLOutput {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
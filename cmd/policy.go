@@ -0,0 +1,90 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/redradrat/cloud-objects/aws/iam"
+
+	"github.com/spf13/cobra"
+)
+
+var policyName string
+var policySpec iam.PolicySpec
+var policyDocumentFile string
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Args:  OnlyCloudObjectAction(),
+	Short: "Interact with the IAM policy cloud object",
+	Long: `Interact with the IAM policy cloud object. For example:
+
+	*) cloud-objects aws iam policy create --name testpolicy --document policy.json
+
+	*) cat policy.json | cloud-objects aws iam policy update --name testpolicy --document -
+
+	*) cloud-objects aws iam policy delete --name testpolicy
+
+Updating the policy document adds a new default version to the policy.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		action := CloudObjectAction(args[0])
+		spec := policySpec
+		if policyDocumentFile != "" {
+			pd, err := readPolicyDocument(cmd, policyDocumentFile)
+			if err != nil {
+				return err
+			}
+			spec.PolicyDocument = pd
+		}
+		if err := checkSpec(action, &spec); err != nil {
+			return err
+		}
+
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		policy, err := iam.NewPolicy(policyName, session)
+		if err != nil {
+			return err
+		}
+
+		purge, err := getPurge(cmd, action, policy.ID())
+		if err != nil {
+			return err
+		}
+		_, err = runCloudObject(cmd, iam.PolicyKind, policy, &spec, action, purge, iam.Available)
+		return err
+	},
+}
+
+func init() {
+	iamCmd.AddCommand(policyCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// policyCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	policyCmd.Flags().StringVarP(&policyName, "name", "n", "", "The name of the policy")
+	policyCmd.Flags().StringVar(&policySpec.Description, "description", "",
+		"The description of the policy (can't be changed later on)")
+	policyCmd.Flags().StringVar(&policyDocumentFile, "document", "",
+		"The JSON file holding the policy document, or '-' to read it from stdin")
+}
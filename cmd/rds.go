@@ -27,7 +27,11 @@ var rdsCmd = &cobra.Command{
 
 	*) cloud-objects aws rds instance create --name testinstance
 
-	*) cloud-objects aws rds instance delete --name testinstance`,
+	*) cloud-objects aws rds instance delete --name testinstance
+
+	*) cloud-objects aws rds cluster create --name testcluster --instanceClass db.r5.large
+
+	*) cloud-objects aws rds replica create --name testreplica --source testinstance`,
 }
 
 func init() {
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"github.com/redradrat/cloud-objects/aws"
+	"github.com/redradrat/cloud-objects/aws/rds"
+
+	"github.com/spf13/cobra"
+)
+
+var replicaName string
+var replicaSource string
+var replicaSourceRegion string
+var replicaAvailabilityZone string
+
+// replicaCmd represents the replica command
+var replicaCmd = &cobra.Command{
+	Use:   "replica",
+	Args:  OnlyCloudObjectAction(),
+	Short: "Interact with the RDS read replica cloud object",
+	Long: `Interact with the RDS read replica cloud object: a read-only copy of an RDS instance. For example:
+
+	*) cloud-objects aws rds replica create --name testreplica --source testinstance
+
+	*) cloud-objects aws rds replica create --name testreplica --source testinstance --sourceRegion eu-west-1 \
+		--subnetGroup testgroup --region eu-central-1
+
+	*) cloud-objects aws rds replica delete --name testreplica
+
+The source instance has to have automated backups enabled. Replicas of encrypted instances are encrypted with a KMS
+key of their own. Unlike instances, replicas leave no final snapshot on delete.
+
+On create and update, the connection details of the replica are handed out like those of instances, without the
+password, which is the one of the source instance.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		sinks, err := getSecretSinks(cmd, session)
+		if err != nil {
+			return err
+		}
+		replica, err := rds.NewReadReplica(replicaName, session)
+		if err != nil {
+			return err
+		}
+		spec := rds.ReadReplicaSpec{
+			SourceInstance:          replicaSource,
+			SourceRegion:            replicaSourceRegion,
+			AvailabilityZone:        replicaAvailabilityZone,
+			AutoMinorVersionUpgrade: true,
+			DBInstanceClass:         instanceClass,
+			VpcSecurityGroupIds:     securityGroupIDs,
+		}
+		if subnetGroupName != "" {
+			spec.DBSubnetGroupName = aws.CloudObjectResource(rds.DBSubnetGroupTopic, subnetGroupName)
+		}
+		action := CloudObjectAction(args[0])
+		if err := checkSpec(action, &spec); err != nil {
+			return err
+		}
+
+		purge, err := getPurge(cmd, action, replica.ID())
+		if err != nil {
+			return err
+		}
+		secrets, err := runCloudObject(cmd, rds.ReadReplicaKind, replica, &spec, action, purge, rds.InstanceAvailable)
+		if err != nil {
+			return err
+		}
+		return writeCredentials(cmd, sinks, replica.ID(), secrets)
+	},
+}
+
+func init() {
+	rdsCmd.AddCommand(replicaCmd)
+
+	replicaCmd.Flags().StringVarP(&replicaName, "name", "n", "", "The name of the replica")
+	replicaCmd.Flags().StringVar(&replicaSource, "source", "", "The name of the instance to replicate")
+	replicaCmd.Flags().StringVar(&replicaSourceRegion, "sourceRegion", "",
+		"The region of the source instance, if it's not the one of the replica")
+	replicaCmd.Flags().StringVar(&replicaAvailabilityZone, "availabilityZone", "",
+		"The availability zone to create the replica in")
+	replicaCmd.Flags().StringVar(&subnetGroupName, "subnetGroup", "",
+		"The subnetGroup to use, for replicas of instances in other regions")
+	replicaCmd.Flags().StringVar(&instanceClass, "instanceClass", "",
+		"The instance class to use; defaults to the one of the source instance")
+	replicaCmd.Flags().StringSliceVar(&securityGroupIDs, "securityGroups", []string{},
+		"The securityGroupIDs to attach to; default to the ones of the source instance")
+	addCredentialsFlags(replicaCmd.Flags())
+}
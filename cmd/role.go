@@ -0,0 +1,107 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/redradrat/cloud-objects/aws/iam"
+
+	"github.com/spf13/cobra"
+)
+
+var roleName string
+var roleSpec iam.RoleSpec
+var roleTrustPolicyFile string
+var roleTrustPreset string
+
+// roleCmd represents the role command
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Args:  OnlyCloudObjectAction(),
+	Short: "Interact with the IAM role cloud object",
+	Long: fmt.Sprintf(`Interact with the IAM role cloud object. For example:
+
+	*) cloud-objects aws iam role create --name testrole --trust lambda
+
+	*) cloud-objects aws iam role update --name testrole --trustPolicy trust.json
+
+	*) cloud-objects aws iam role delete --name testrole
+
+The trust policy is either read from a JSON file (or stdin with '-'), or one of the presets: %s, or
+account:<id> to trust another AWS account.`, strings.Join(iam.TrustPolicyPresets(), ", ")),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		action := CloudObjectAction(args[0])
+		spec := roleSpec
+		switch {
+		case roleTrustPolicyFile != "" && roleTrustPreset != "":
+			return UsageError{Message: "only one of --trustPolicy and --trust can be given"}
+		case roleTrustPolicyFile != "":
+			pd, err := readPolicyDocument(cmd, roleTrustPolicyFile)
+			if err != nil {
+				return err
+			}
+			spec.PolicyDocument = pd
+		case roleTrustPreset != "":
+			pd, err := iam.TrustPolicyPreset(roleTrustPreset)
+			if err != nil {
+				return err
+			}
+			spec.PolicyDocument = pd
+		}
+		if err := checkSpec(action, &spec); err != nil {
+			return err
+		}
+
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		role, err := iam.NewRole(roleName, session)
+		if err != nil {
+			return err
+		}
+
+		purge, err := getPurge(cmd, action, role.ID())
+		if err != nil {
+			return err
+		}
+		_, err = runCloudObject(cmd, iam.RoleKind, role, &spec, action, purge, iam.Available)
+		return err
+	},
+}
+
+func init() {
+	iamCmd.AddCommand(roleCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// roleCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	roleCmd.Flags().StringVarP(&roleName, "name", "n", "", "The name of the role")
+	roleCmd.Flags().StringVar(&roleSpec.Description, "description", "", "The description of the role")
+	roleCmd.Flags().Int64Var(&roleSpec.MaxSessionDuration, "maxSessionDuration", iam.DefaultMaxSessionDuration,
+		"How long sessions of the role last at most, in seconds (3600 to 43200)")
+	roleCmd.Flags().StringVar(&roleTrustPolicyFile, "trustPolicy", "",
+		"The JSON file holding the trust policy, or '-' to read it from stdin")
+	roleCmd.Flags().StringVar(&roleTrustPreset, "trust", "",
+		"A trust policy preset (e.g. 'ec2', 'lambda', 'account:123456789012')")
+}
@@ -16,15 +16,26 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"github.com/spf13/cobra"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var stateFile string
+var timeout time.Duration
+
+// startedCmd is the command cobra runs, once it is done parsing flags and args. Errors before that are usage errors.
+var startedCmd *cobra.Command
 
 // rootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
@@ -39,15 +50,55 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRunE: startCommand,
+	// Errors are printed by Execute, according to the output format
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Failed commands exit with the code of their ErrorClass.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// Interrupting the CLI cancels all in-flight provider calls
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := RootCmd.ExecuteContext(ctx)
+	stop()
+	if err == nil {
+		return
+	}
+
+	cmd, format := RootCmd, TableOutput
+	if startedCmd == nil {
+		err = UsageError{Message: err.Error()}
+		if found, _, ferr := RootCmd.Find(os.Args[1:]); ferr == nil {
+			cmd = found
+		}
+	} else {
+		cmd = startedCmd
+		if f, ferr := cmd.Flags().GetString(OutputFlag); ferr == nil {
+			format = f
+		}
+	}
+	printError(os.Stderr, format, cmd.CommandPath(), err)
+	os.Exit(ClassifyError(err).ExitCode())
+}
+
+// startCommand marks the command as started, once its flags are complete. Cobra only checks for required flags right
+// before running the command, so missing ones would be taken for a failed run otherwise.
+func startCommand(cmd *cobra.Command, args []string) error {
+	var missing []string
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if required := flag.Annotations[cobra.BashCompOneRequiredFlag]; len(required) > 0 && required[0] == "true" &&
+			!flag.Changed {
+			missing = append(missing, flag.Name)
+		}
+	})
+	if len(missing) > 0 {
+		return UsageError{Message: fmt.Sprintf(`required flag(s) "%s" not set`, strings.Join(missing, `", "`))}
 	}
+	startedCmd = cmd
+	return nil
 }
 
 func init() {
@@ -58,6 +109,10 @@ func init() {
 	// will be global for your application.
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.cloud-objects.yaml)")
+	RootCmd.PersistentFlags().StringVar(&stateFile, "state-file", "",
+		"file to record applied cloud objects in (default is $HOME/.cloud-objects/state.json)")
+	RootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0,
+		"abort the command if it takes longer than this (e.g. '30s', '5m'); no timeout by default")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -73,8 +128,8 @@ func initConfig() {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(GenericErrorClass.ExitCode())
 		}
 
 		// Search config in home directory with name ".cloud-objects" (without extension).
@@ -86,6 +141,6 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
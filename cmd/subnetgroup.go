@@ -16,8 +16,6 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 
 	"github.com/redradrat/cloud-objects/aws/rds"
@@ -38,16 +36,14 @@ var subnetgroupCmd = &cobra.Command{
 	*) cloud-objects aws rds subnetgroup create --name testsubnetgroup
 
 	*) cloud-objects aws rds subnetgroup delete --name testsubnetgroup`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		session, err := GetSession(cmd)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 		sg, err := rds.NewSubnetGroup(subnetGroupName, session)
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
 		spec := rds.SubnetGroupSpec{
 			Description: "A test RDS DB SubnetGroup",
@@ -57,13 +53,13 @@ var subnetgroupCmd = &cobra.Command{
 			},
 		}
 
-		_, err = HandleCloudObject(sg, &spec, CloudObjectAction(args[0]), false)
+		purge, err := getPurge(cmd, CloudObjectAction(args[0]), sg.ID())
 		if err != nil {
-			cmd.PrintErrln(err.Error())
-			return
+			return err
 		}
-		fmt.Println(sg.Status())
-
+		_, err = runCloudObject(cmd, rds.SubnetGroupKind, sg, &spec, CloudObjectAction(args[0]), purge,
+			rds.SubnetGroupComplete)
+		return err
 	},
 }
 
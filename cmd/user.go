@@ -16,26 +16,92 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/redradrat/cloud-objects/aws/iam"
 
 	"github.com/spf13/cobra"
 )
 
-// iamCmd represents the iam command
+var userName string
+var userSpec iam.UserSpec
+var userPolicies []string
+
+// userCmd represents the user command
 var userCmd = &cobra.Command{
 	Use:   "user",
 	Args:  OnlyCloudObjectAction(),
 	Short: "Interact with the IAM user cloud object",
 	Long: `Interact with the IAM user cloud object. For example:
 
-	*) cloud-objects aws iam user create --name testinstance
+	*) cloud-objects aws iam user create --name testuser --programmaticAccess --credentialsFile creds.json
+
+	*) cloud-objects aws iam user update --name testuser --groups developers --policies arn:aws:iam::aws:policy/ReadOnlyAccess
+
+	*) cloud-objects aws iam user delete --name testuser
 
-	*) cloud-objects aws iam user delete --name testinstance`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("user called")
+Generated credentials are only handed out once, on create or update. They are printed as JSON to stdout unless
+--credentialsFile or --secrets-to is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkSpec(CloudObjectAction(args[0]), &userSpec); err != nil {
+			return err
+		}
+		session, err := GetSession(cmd)
+		if err != nil {
+			return err
+		}
+		sinks, err := getSecretSinks(cmd, session)
+		if err != nil {
+			return err
+		}
+		usr, err := iam.NewUser(userName, session)
+		if err != nil {
+			return err
+		}
+		action := CloudObjectAction(args[0])
+		// Confirming the purge of the user covers its attachments
+		purge, err := getPurge(cmd, action, usr.ID())
+		if err != nil {
+			return err
+		}
+		// Attachments refer to the user, so they go first on delete and last otherwise
+		if action == DeleteCloudObjectAction {
+			if err := runUserAttachments(cmd, session, action, purge); err != nil {
+				return err
+			}
+		}
+		secrets, err := runCloudObject(cmd, iam.UserKind, usr, &userSpec, action, purge, iam.Available)
+		if err != nil {
+			return err
+		}
+		if err := writeCredentials(cmd, sinks, usr.ID(), secrets); err != nil {
+			return err
+		}
+		if action != DeleteCloudObjectAction {
+			if err := runUserAttachments(cmd, session, action, purge); err != nil {
+				return err
+			}
+		}
+		return nil
 	},
 }
 
+// runUserAttachments handles the action for the attachments of the --policies to the user
+func runUserAttachments(cmd *cobra.Command, session client.ConfigProvider, action CloudObjectAction,
+	purge bool) error {
+	for _, policy := range userPolicies {
+		spec := iam.PolicyAttachmentSpec{Policy: policy, Type: iam.UserAttachmentType, Target: userName}
+		att, err := iam.NewPolicyAttachment(spec.Policy, spec.Type, spec.Target, session)
+		if err != nil {
+			return err
+		}
+		if _, err := runCloudObject(cmd, iam.PolicyAttachmentKind, att, &spec, action, purge,
+			iam.Available); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	iamCmd.AddCommand(userCmd)
 
@@ -43,9 +109,18 @@ func init() {
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
-	// iamCmd.PersistentFlags().String("foo", "", "A help for foo")
+	// userCmd.PersistentFlags().String("foo", "", "A help for foo")
 
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
-	// iamCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	userCmd.Flags().StringVarP(&userName, "name", "n", "", "The name of the user")
+	userCmd.Flags().BoolVar(&userSpec.LoginProfile, "loginProfile", false,
+		"Whether the user gets a generated password for the AWS console")
+	userCmd.Flags().BoolVar(&userSpec.ProgrammaticAccess, "programmaticAccess", false,
+		"Whether the user gets an access key for the AWS APIs")
+	userCmd.Flags().StringSliceVar(&userSpec.Groups, "groups", []string{},
+		"The groups the user is a member of, by name or ARN; the user is removed from all others")
+	userCmd.Flags().StringSliceVar(&userPolicies, "policies", []string{},
+		"The policies to attach to the user, by name or ARN")
+	addCredentialsFlags(userCmd.Flags())
 }
@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+// Object is a single typed cloud object as declared in a manifest
+type Object struct {
+	Kind cloudobject.Kind       `yaml:"kind" json:"kind"`
+	Name string                 `yaml:"name" json:"name"`
+	Spec map[string]interface{} `yaml:"spec" json:"spec"`
+
+	// Account names the account the object lives in, for tools working across several. Empty means the default.
+	Account string `yaml:"account" json:"account"`
+
+	// DependsOn lists other objects of the manifest as "kind/name", that need to be applied before this one. Most
+	// dependencies follow from the spec already (e.g. an instance's subnet group), this is for everything else.
+	DependsOn []string `yaml:"dependsOn" json:"dependsOn"`
+}
+
+func (o Object) String() string {
+	return fmt.Sprintf("%s/%s", o.Kind.String(), o.Name)
+}
+
+// Manifest is a list of cloud objects, that are applied or deleted together. It can be written in YAML or JSON,
+// either as a list of objects, or as a mapping with the list under "objects".
+type Manifest struct {
+	Objects []Object `yaml:"objects" json:"objects"`
+}
+
+// Parse reads a YAML or JSON manifest
+func Parse(data []byte) (*Manifest, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("manifest is neither valid YAML nor JSON: %w", err)
+	}
+
+	m := &Manifest{}
+	// An empty document is an empty manifest
+	if len(root.Content) == 0 {
+		return m, nil
+	}
+
+	var err error
+	switch root.Content[0].Kind {
+	case yaml.SequenceNode:
+		err = root.Content[0].Decode(&m.Objects)
+	case yaml.MappingNode:
+		err = root.Content[0].Decode(m)
+	default:
+		err = fmt.Errorf("expected a list of objects or a mapping with 'objects'")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest is malformed: %w", err)
+	}
+
+	return m, nil
+}
+
+// ParseFile reads a YAML or JSON manifest from the given path. "-" reads from stdin.
+func ParseFile(path string) (*Manifest, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// DecodeSpec decodes the untyped spec of an object into the given typed spec. Field names are matched
+// case-insensitively, unknown fields are an error.
+func (o Object) DecodeSpec(spec cloudobject.CloudObjectSpec) error {
+	if len(o.Spec) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(o.Spec)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(spec); err != nil {
+		return cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("spec of '%s' is invalid: %s", o.String(), err.Error()),
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package manifest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+const testKind cloudobject.Kind = "test"
+
+type testSpec struct {
+	Size   int
+	Labels []string
+	Nested testNestedSpec
+}
+
+type testNestedSpec struct {
+	Enabled bool
+}
+
+func (s *testSpec) Valid() (bool, error) {
+	if s.Size < 0 {
+		return false, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: "Size must not be negative",
+		}}
+	}
+	return true, nil
+}
+
+type testObject struct {
+	cloudobject.CloudObject
+	name string
+	size int
+}
+
+func (o testObject) ID() cloudobject.ID {
+	return cloudobject.ID(o.name)
+}
+
+func testRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(testKind, func() cloudobject.CloudObjectSpec {
+		return &testSpec{Size: 1}
+	}, func(name string, spec cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error) {
+		return testObject{name: name, size: spec.(*testSpec).Size}, nil
+	}, nil)
+	return r
+}
+
+func TestParse(t *testing.T) {
+	yamlManifest := `
+objects:
+  - kind: test
+    name: first
+    spec:
+      size: 3
+      labels: [a, b]
+      nested:
+        enabled: true
+  - kind: test
+    name: second
+`
+	jsonManifest := `[{"kind": "test", "name": "first", "spec": {"Size": 3, "Labels": ["a", "b"],
+		"Nested": {"Enabled": true}}}, {"kind": "test", "name": "second"}]`
+
+	for _, data := range []string{yamlManifest, jsonManifest} {
+		m, err := Parse([]byte(data))
+		assert.NoError(t, err)
+		assert.Len(t, m.Objects, 2)
+
+		entries, err := testRegistry().Build(m)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "test/first", entries[0].String())
+		assert.Equal(t, &testSpec{Size: 3, Labels: []string{"a", "b"}, Nested: testNestedSpec{Enabled: true}},
+			entries[0].CloudSpec)
+		assert.Equal(t, "first", entries[0].CloudObject.(testObject).name)
+		// The object is built from the decoded spec
+		assert.Equal(t, 3, entries[0].CloudObject.(testObject).size)
+		// Without a spec, the factory defaults stay
+		assert.Equal(t, &testSpec{Size: 1}, entries[1].CloudSpec)
+	}
+
+	m, err := Parse([]byte(""))
+	assert.NoError(t, err)
+	assert.Empty(t, m.Objects)
+
+	_, err = Parse([]byte("just a string"))
+	assert.Error(t, err)
+	_, err = Parse([]byte("objects: {"))
+	assert.Error(t, err)
+}
+
+func TestRegistry_Build(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{"unknown kind", `[{kind: nope, name: a}]`, "objects[0]: unknown kind 'nope' (known kinds: [test])"},
+		{"no name", `[{kind: test}]`, "objects[0]: object of kind 'test' has no name"},
+		{"duplicate", `[{kind: test, name: a}, {kind: test, name: a}]`,
+			"objects[1]: 'test/a' is declared more than once"},
+		{"unknown field", `[{kind: test, name: a, spec: {sice: 3}}]`, "objects[0]: spec of 'test/a' is invalid"},
+		{"wrong type", `[{kind: test, name: a, spec: {size: many}}]`, "objects[0]: spec of 'test/a' is invalid"},
+		{"invalid", `[{kind: test, name: a, spec: {size: -1}}]`,
+			"objects[0]: 'test/a': Size must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(tt.manifest))
+			assert.NoError(t, err)
+			_, err = testRegistry().Build(m)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestBuildAccounts(t *testing.T) {
+	m, err := Parse([]byte(`[{kind: test, name: a}, {kind: test, name: b, account: prod}]`))
+	assert.NoError(t, err)
+
+	var accounts []string
+	entries, err := BuildAccounts(m, func(account string) (*Registry, error) {
+		accounts = append(accounts, account)
+		return testRegistry(), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "prod"}, accounts)
+	assert.Equal(t, "prod", entries[1].Account)
+
+	_, err = BuildAccounts(m, func(account string) (*Registry, error) {
+		if account == "prod" {
+			return nil, fmt.Errorf("unknown account '%s'", account)
+		}
+		return testRegistry(), nil
+	})
+	assert.EqualError(t, err, "objects[1]: unknown account 'prod'")
+}
+
+func TestGraph(t *testing.T) {
+	m, err := Parse([]byte(`[{kind: test, name: a, dependsOn: [test/b]}, {kind: test, name: b}]`))
+	assert.NoError(t, err)
+	entries, err := testRegistry().Build(m)
+	assert.NoError(t, err)
+	g, err := Graph(entries)
+	assert.NoError(t, err)
+	order, err := g.Order(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudobject.ID{"b", "a"}, order)
+
+	m, err = Parse([]byte(`[{kind: test, name: a, dependsOn: [test/c]}]`))
+	assert.NoError(t, err)
+	entries, err = testRegistry().Build(m)
+	assert.NoError(t, err)
+	_, err = Graph(entries)
+	assert.EqualError(t, err, "'test/a' depends on 'test/c', which is not part of the manifest")
+}
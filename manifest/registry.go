@@ -0,0 +1,148 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/redradrat/cloud-objects/cloudobject"
+)
+
+// SpecFactory returns a new spec of the right type for a kind, with the defaults the manifest spec is decoded onto
+type SpecFactory func() cloudobject.CloudObjectSpec
+
+// ObjectFactory returns a new CloudObject of a kind with the given name. It gets the decoded spec, for kinds whose
+// identity follows from it (e.g. policy attachments).
+type ObjectFactory func(name string, spec cloudobject.CloudObjectSpec) (cloudobject.CloudObject, error)
+
+type kindEntry struct {
+	newSpec   SpecFactory
+	newObject ObjectFactory
+	ready     cloudobject.ReadyFunc
+}
+
+// Registry knows how to turn the objects of a manifest into real CloudObjects and Specs
+type Registry struct {
+	kinds map[cloudobject.Kind]kindEntry
+}
+
+func NewRegistry() *Registry {
+	return &Registry{kinds: make(map[cloudobject.Kind]kindEntry)}
+}
+
+// Register makes a kind known to the registry. Ready tells when objects of this kind are ready to be used.
+func (r *Registry) Register(kind cloudobject.Kind, newSpec SpecFactory, newObject ObjectFactory,
+	ready cloudobject.ReadyFunc) {
+	r.kinds[kind] = kindEntry{newSpec: newSpec, newObject: newObject, ready: ready}
+}
+
+// Kinds returns all registered kinds, sorted
+func (r *Registry) Kinds() []cloudobject.Kind {
+	out := make([]cloudobject.Kind, 0, len(r.kinds))
+	for kind := range r.kinds {
+		out = append(out, kind)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// Entry is a manifest object, resolved to its CloudObject and decoded, validated Spec
+type Entry struct {
+	Object
+
+	CloudObject cloudobject.CloudObject
+	CloudSpec   cloudobject.CloudObjectSpec
+	Ready       cloudobject.ReadyFunc
+}
+
+// Build resolves all objects of the manifest, in manifest order. Unknown kinds, missing or duplicate names and
+// invalid specs are errors; nothing is resolved in that case.
+func (r *Registry) Build(m *Manifest) ([]Entry, error) {
+	return BuildAccounts(m, func(string) (*Registry, error) {
+		return r, nil
+	})
+}
+
+// BuildAccounts resolves all objects of the manifest like Registry.Build, but each one with the Registry that
+// registries returns for the object's Account. This way objects of different accounts get different clients.
+func BuildAccounts(m *Manifest, registries func(account string) (*Registry, error)) ([]Entry, error) {
+	seen := make(map[string]bool)
+	entries := make([]Entry, 0, len(m.Objects))
+	for i, obj := range m.Objects {
+		r, err := registries(obj.Account)
+		if err != nil {
+			return nil, fmt.Errorf("objects[%d]: %w", i, err)
+		}
+		entry, err := r.build(obj)
+		if err != nil {
+			return nil, fmt.Errorf("objects[%d]: %w", i, err)
+		}
+		if seen[obj.String()] {
+			return nil, fmt.Errorf("objects[%d]: %w", i, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+				Message: fmt.Sprintf("'%s' is declared more than once", obj.String()),
+			}})
+		}
+		seen[obj.String()] = true
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (r *Registry) build(obj Object) (Entry, error) {
+	if obj.Name == "" {
+		return Entry{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("object of kind '%s' has no name", obj.Kind.String()),
+		}}
+	}
+	kind, ok := r.kinds[obj.Kind]
+	if !ok {
+		return Entry{}, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+			Message: fmt.Sprintf("unknown kind '%s' (known kinds: %v)", obj.Kind.String(), r.Kinds()),
+		}}
+	}
+
+	spec := kind.newSpec()
+	if err := obj.DecodeSpec(spec); err != nil {
+		return Entry{}, err
+	}
+	if ok, err := spec.Valid(); !ok || err != nil {
+		if err == nil {
+			err = cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{Message: "spec is invalid"}}
+		}
+		return Entry{}, fmt.Errorf("'%s': %w", obj.String(), err)
+	}
+	cloudObj, err := kind.newObject(obj.Name, spec)
+	if err != nil {
+		return Entry{}, fmt.Errorf("'%s': %w", obj.String(), err)
+	}
+
+	return Entry{Object: obj, CloudObject: cloudObj, CloudSpec: spec, Ready: kind.ready}, nil
+}
+
+// Graph returns the dependency graph of the resolved objects, with the references they declare themselves and the
+// ones given by DependsOn
+func Graph(entries []Entry) (*cloudobject.Graph, error) {
+	g := cloudobject.NewGraph()
+	ids := make(map[string]cloudobject.ID, len(entries))
+	for _, entry := range entries {
+		if err := g.Add(entry.CloudObject, entry.CloudSpec); err != nil {
+			return nil, fmt.Errorf("'%s': %w", entry.String(), err)
+		}
+		ids[entry.String()] = entry.CloudObject.ID()
+	}
+
+	for _, entry := range entries {
+		for _, dep := range entry.DependsOn {
+			id, ok := ids[dep]
+			if !ok {
+				return nil, cloudobject.SpecInvalidError{ErrorInfo: cloudobject.ErrorInfo{
+					Message: fmt.Sprintf("'%s' depends on '%s', which is not part of the manifest",
+						entry.String(), dep),
+				}}
+			}
+			if err := g.Depend(entry.CloudObject.ID(), id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g, nil
+}